@@ -0,0 +1,48 @@
+package connectlog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/mycujoo/go-stdlib/pkg/ctxslog"
+)
+
+func (i *Interceptor) unaryClient(ctx context.Context, request connect.AnyRequest, next connect.UnaryFunc) (connect.AnyResponse, error) {
+	if i.o.skip(request.Spec().Procedure) {
+		return next(ctx, request)
+	}
+
+	start := time.Now()
+	resp, err := next(ctx, request)
+	i.clientDone(ctx, request.Spec(), callAttrs(time.Since(start), request, resp), err)
+	return resp, err
+}
+
+// clientDone logs the result of an outgoing call.
+func (i *Interceptor) clientDone(ctx context.Context, spec connect.Spec, callAttrs []slog.Attr, err error) {
+	l := i.logger.With(i.o.methodFields(spec)...)
+	attrs := ctxslog.Attrs(ctx)
+	attrs = append(attrs[:len(attrs):len(attrs)], callAttrs...)
+	if i.o.traceIDs {
+		attrs = append(attrs, traceFields(ctx)...)
+	}
+
+	if err != nil {
+		code := connect.CodeOf(err)
+		msg := fmt.Sprintf("client error: %s", err.Error())
+		if connectErr := new(connect.Error); errors.As(err, &connectErr) {
+			msg = fmt.Sprintf("client error: %s", connectErr.Message())
+		}
+		attrs = append(attrs,
+			i.o.codeAttr(code),
+			slog.Any("error", err),
+		)
+		l.LogAttrs(ctx, i.o.errorLevel(spec.Procedure, codeToLevel(code)), msg, attrs...)
+	} else if i.o.logSuccess {
+		l.LogAttrs(ctx, i.o.successLevel(spec.Procedure, slog.LevelInfo), "client ok", attrs...)
+	}
+}
@@ -0,0 +1,40 @@
+package connectlog
+
+import (
+	"log/slog"
+	"time"
+)
+
+// deadlineBudgetWarning is the fraction of the deadline budget of a call below which the call is logged at WARN level.
+const deadlineBudgetWarning = 0.05
+
+// deadlineBudget is the time a handler had until the deadline of the request, and the time left when it returned.
+type deadlineBudget struct {
+	budget    time.Duration
+	remaining time.Duration
+	// Less than deadlineBudgetWarning of the budget was left, or the deadline passed.
+	exhausted bool
+}
+
+func newDeadlineBudget(budget, remaining time.Duration) deadlineBudget {
+	return deadlineBudget{
+		budget:    budget,
+		remaining: remaining,
+		exhausted: float64(remaining) < float64(budget)*deadlineBudgetWarning,
+	}
+}
+
+func (b deadlineBudget) attrs() []slog.Attr {
+	return []slog.Attr{
+		slog.Float64("deadline_budget_ms", float64(b.budget)/float64(time.Millisecond)),
+		slog.Float64("deadline_remaining_ms", float64(b.remaining)/float64(time.Millisecond)),
+	}
+}
+
+// message returns the message logged for successful calls that exhausted their budget.
+func (b deadlineBudget) message() string {
+	if b.remaining < 0 {
+		return "handler ok after deadline"
+	}
+	return "handler ok close to deadline"
+}
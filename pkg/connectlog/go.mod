@@ -5,8 +5,10 @@ go 1.21
 require (
 	connectrpc.com/connect v1.11.1
 	github.com/mycujoo/go-stdlib/pkg/ctxslog v1.0.0
+	go.opentelemetry.io/otel v1.19.0
+	go.opentelemetry.io/otel/metric v1.19.0
+	go.opentelemetry.io/otel/trace v1.19.0
+	google.golang.org/protobuf v1.31.0
 )
 
 replace github.com/mycujoo/go-stdlib/pkg/ctxslog => ../ctxslog
-
-require google.golang.org/protobuf v1.31.0 // indirect
@@ -6,60 +6,150 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"runtime/debug"
 	"strings"
+	"time"
 
 	"connectrpc.com/connect"
 	"github.com/mycujoo/go-stdlib/pkg/ctxslog"
+	"google.golang.org/protobuf/proto"
 )
 
 var errInternal = errors.New("internal error")
 
-// NewLoggingInterceptor returns unary interceptor that logs response errors.
-// It injects context logger with method and trace context.
-func NewLoggingInterceptor(logger *slog.Logger, opts ...Option) connect.UnaryInterceptorFunc {
-	o := options{}
+// Interceptor logs calls of handlers and clients, unary and streaming, with one set of options.
+// It implements connect.Interceptor, so the same interceptor can be passed to connect.WithInterceptors of handlers and
+// clients.
+//
+// Handlers get a context logger with method, client IP, user agent, protocol, retry attempt, idempotency key and trace
+// context, and their errors are logged and masked. Errors of clients are logged with the attributes of the context
+// logger of the call.
+type Interceptor struct {
+	logger *slog.Logger
+	o      options
+}
+
+var _ connect.Interceptor = (*Interceptor)(nil)
+
+// NewLoggingInterceptor returns interceptor that logs response errors of handlers and clients.
+func NewLoggingInterceptor(logger *slog.Logger, opts ...Option) *Interceptor {
+	i := &Interceptor{logger: logger}
 	for _, opt := range opts {
-		opt(&o)
+		opt(&i.o)
+	}
+	return i
+}
+
+// WrapUnary implements connect.Interceptor.
+func (i *Interceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, request connect.AnyRequest) (connect.AnyResponse, error) {
+		if request.Spec().IsClient {
+			return i.unaryClient(ctx, request, next)
+		}
+
+		ctx = i.handlerContext(ctx, request.Spec(), request.Peer(), request.Header())
+		start := time.Now()
+		resp, err := next(ctx, request)
+		attrs := callAttrs(time.Since(start), request, resp)
+		return resp, i.handlerDone(ctx, request.Spec(), start, attrs, err)
 	}
+}
 
-	return func(next connect.UnaryFunc) connect.UnaryFunc {
-		return func(ctx context.Context, request connect.AnyRequest) (connect.AnyResponse, error) {
-			l := logger.With(methodFields(request.Spec())...)
-
-			// Inject logger into context
-			ctx = ctxslog.ToContext(ctx, l)
-
-			resp, err := next(ctx, request)
-
-			// Extract logger from context with all added attributes
-			l = ctxslog.Extract(ctx)
-
-			if err != nil {
-				var level slog.Level
-				var msg string
-				var attrs []slog.Attr
-				originalErr := err
-
-				if connectErr := new(connect.Error); errors.As(err, &connectErr) {
-					level = codeToLevel(connectErr.Code())
-					msg = fmt.Sprintf("handler error: %s", connectErr.Message())
-					attrs = append(attrs, slog.String("code", connectErr.Code().String()))
-				} else {
-					level = slog.LevelError
-					msg = fmt.Sprintf("handler error: %s", err.Error())
-					// Hide the internal error from the client
-					err = connect.NewError(connect.CodeInternal, errInternal)
-				}
-
-				attrs = append(attrs, slog.Any("error", originalErr))
-				l.LogAttrs(ctx, level, msg, attrs...)
-			} else if o.logSuccess {
-				l.Log(ctx, slog.LevelInfo, "handler ok")
-			}
-
-			return resp, err
+// handlerContext injects the logger of a handler call into the context.
+func (i *Interceptor) handlerContext(ctx context.Context, spec connect.Spec, peer connect.Peer, header http.Header) context.Context {
+	fields := append(i.o.methodFields(spec), peerFields(peer, header, i.o.trustedHops)...)
+	fields = append(fields, retryFields(header)...)
+	fields = append(fields, headerFields(header, i.o.loggedHeaders)...)
+	if i.o.traceIDs {
+		for _, a := range traceFields(ctx) {
+			fields = append(fields, a)
 		}
 	}
+	return ctxslog.ToContext(ctx, i.logger.With(fields...))
+}
+
+// handlerDone logs the result of a handler call started at start, and returns the error to return to the client.
+func (i *Interceptor) handlerDone(ctx context.Context, spec connect.Spec, start time.Time, attrs []slog.Attr, err error) error {
+	var budget deadlineBudget
+	if deadline, ok := ctx.Deadline(); ok {
+		budget = newDeadlineBudget(deadline.Sub(start), time.Until(deadline))
+		attrs = append(attrs, budget.attrs()...)
+	}
+
+	// Extract logger from context with all added attributes
+	l := ctxslog.Extract(ctx)
+	// Skipped procedures still get a context logger and masked errors, only their completion isn't logged.
+	skip := i.o.skip(spec.Procedure)
+
+	if err != nil {
+		var level slog.Level
+		var msg string
+		originalErr := err
+		for _, hook := range i.o.errorHooks {
+			hook(ctx, spec, err)
+		}
+
+		// Context errors are returned with their code, so that cancellations by clients aren't logged as internal.
+		err = contextError(err)
+		if connectErr := new(connect.Error); errors.As(err, &connectErr) {
+			level = codeToLevel(connectErr.Code())
+			msg = fmt.Sprintf("handler error: %s", connectErr.Message())
+			attrs = append(attrs, i.o.codeAttr(connectErr.Code()))
+		} else {
+			level = slog.LevelError
+			msg = fmt.Sprintf("handler error: %s", err.Error())
+			attrs = append(attrs, reportContext(err, spec.Procedure))
+			// Hide the internal error from the client
+			var maskAttrs []slog.Attr
+			err, maskAttrs = i.o.maskError(ctx, err)
+			attrs = append(attrs, maskAttrs...)
+		}
+
+		attrs = append(attrs, slog.Any("error", originalErr))
+		if budget.exhausted && level < slog.LevelWarn {
+			level = slog.LevelWarn
+		}
+		if !skip {
+			l.LogAttrs(ctx, i.o.errorLevel(spec.Procedure, level), msg, attrs...)
+		}
+	} else if budget.exhausted && !skip {
+		l.LogAttrs(ctx, i.o.errorLevel(spec.Procedure, slog.LevelWarn), budget.message(), attrs...)
+	} else if i.o.logSuccess && !skip {
+		l.LogAttrs(ctx, i.o.successLevel(spec.Procedure, slog.LevelInfo), "handler ok", attrs...)
+	}
+
+	return err
+}
+
+// contextError returns a connect error with code canceled or deadline exceeded for an unexpected error caused by the
+// cancellation of a context, and the error unchanged otherwise.
+func contextError(err error) error {
+	if connectErr := new(connect.Error); errors.As(err, &connectErr) {
+		return err
+	}
+	switch {
+	case errors.Is(err, context.Canceled):
+		return connect.NewError(connect.CodeCanceled, err)
+	case errors.Is(err, context.DeadlineExceeded):
+		return connect.NewError(connect.CodeDeadlineExceeded, err)
+	default:
+		return err
+	}
+}
+
+// callAttrs returns the duration of a call and the sizes of its messages, if they are protobuf messages.
+func callAttrs(duration time.Duration, request connect.AnyRequest, resp connect.AnyResponse) []slog.Attr {
+	attrs := make([]slog.Attr, 0, 3)
+	attrs = append(attrs, slog.Float64("duration_ms", float64(duration)/float64(time.Millisecond)))
+	if m, ok := request.Any().(proto.Message); ok {
+		attrs = append(attrs, slog.Int("request_bytes", proto.Size(m)))
+	}
+	if resp != nil {
+		if m, ok := resp.Any().(proto.Message); ok {
+			attrs = append(attrs, slog.Int("response_bytes", proto.Size(m)))
+		}
+	}
+	return attrs
 }
 
 func codeToLevel(code connect.Code) slog.Level {
@@ -102,41 +192,47 @@ func codeToLevel(code connect.Code) slog.Level {
 }
 
 func methodFields(spec connect.Spec) []any {
-	name := strings.TrimLeft(spec.Procedure, "/")
-	parts := strings.SplitN(name, "/", 2)
+	svc, method := splitProcedure(spec.Procedure)
 	var fields []any
-	switch len(parts) {
-	case 0:
-		return nil // invalid
-	case 1:
-		// fall back to treating the whole string as the method
-		if method := parts[0]; method != "" {
-			fields = append(fields, slog.String("method", method))
-		}
-	default:
-		// some.package.v1.Service/Method
-		if svc := parts[0]; svc != "" {
-			fields = append(fields, slog.String("service", svc))
-		}
-		if method := parts[1]; method != "" {
-			fields = append(fields, slog.String("method", method))
-		}
+	if svc != "" {
+		fields = append(fields, slog.String("service", svc))
+	}
+	if method != "" {
+		fields = append(fields, slog.String("method", method))
 	}
 	return fields
 }
 
+// splitProcedure returns the service and method of a procedure, e.g. "/some.package.v1.Service/Method".
+func splitProcedure(procedure string) (svc string, method string) {
+	name := strings.TrimLeft(procedure, "/")
+	parts := strings.SplitN(name, "/", 2)
+	if len(parts) == 1 {
+		// fall back to treating the whole string as the method
+		return "", parts[0]
+	}
+	return parts[0], parts[1]
+}
+
 // NewLoggingRecoverHandler returns a recover handler that logs panics.
+// The stack of the panic is logged in the stack_trace field, in the format of Go panics, so that GCP Error Reporting
+// reports the panic and groups it by the panicking location.
 func NewLoggingRecoverHandler(logger *slog.Logger) func(context.Context, connect.Spec, http.Header, any) error {
 	return func(ctx context.Context, spec connect.Spec, header http.Header, val any) error {
-		// remove authorization header from logs
-		header.Del("authorization")
+		// remove sensitive headers from logs
+		header = header.Clone()
+		for name := range sensitiveHeaders {
+			header.Del(name)
+		}
 		attrs := append(methodFields(spec),
 			slog.Any("headers", header),
 			slog.Any("val", val),
+			// The recover handler is called by a deferred function, so the stack includes the panicking frames.
+			slog.String("stack_trace", fmt.Sprintf("panic: %v\n\n%s", val, debug.Stack())),
 		)
 		logger.ErrorContext(ctx,
 			"handler panic",
-			attrs,
+			attrs...,
 		)
 		return connect.NewError(connect.CodeInternal, errInternal)
 	}
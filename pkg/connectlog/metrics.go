@@ -0,0 +1,80 @@
+package connectlog
+
+import (
+	"context"
+	"time"
+
+	"connectrpc.com/connect"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const meterName = "github.com/mycujoo/go-stdlib/pkg/connectlog"
+
+type rpcInstruments struct {
+	duration metric.Float64Histogram
+	requests metric.Int64Counter
+}
+
+func newRPCInstruments(meter metric.Meter, kind string) (rpcInstruments, error) {
+	var ins rpcInstruments
+	var err error
+	ins.duration, err = meter.Float64Histogram("rpc."+kind+".duration",
+		metric.WithUnit("ms"),
+		metric.WithDescription("Duration of "+kind+" calls."),
+	)
+	if err != nil {
+		return ins, err
+	}
+	ins.requests, err = meter.Int64Counter("rpc."+kind+".requests",
+		metric.WithUnit("{request}"),
+		metric.WithDescription("Number of "+kind+" calls."),
+	)
+	return ins, err
+}
+
+// NewMetricsInterceptor returns unary interceptor that records the number and the duration of calls,
+// in the rpc.server.requests and rpc.server.duration metrics for handlers, and in rpc.client.requests and
+// rpc.client.duration for clients. They have the rpc.system, rpc.service and rpc.method attributes,
+// and the rpc.connect_rpc.error_code attribute for failed calls.
+//
+// It records the errors returned by the interceptors after it, so it should be added before the logging
+// interceptor, which hides internal errors, to record their codes as returned to clients.
+func NewMetricsInterceptor(provider metric.MeterProvider) (connect.UnaryInterceptorFunc, error) {
+	meter := provider.Meter(meterName)
+	server, err := newRPCInstruments(meter, "server")
+	if err != nil {
+		return nil, err
+	}
+	client, err := newRPCInstruments(meter, "client")
+	if err != nil {
+		return nil, err
+	}
+
+	return func(next connect.UnaryFunc) connect.UnaryFunc {
+		return func(ctx context.Context, request connect.AnyRequest) (connect.AnyResponse, error) {
+			start := time.Now()
+			resp, err := next(ctx, request)
+			duration := float64(time.Since(start)) / float64(time.Millisecond)
+
+			ins := server
+			if request.Spec().IsClient {
+				ins = client
+			}
+			svc, method := splitProcedure(request.Spec().Procedure)
+			attrs := []attribute.KeyValue{
+				attribute.String("rpc.system", "connect_rpc"),
+				attribute.String("rpc.service", svc),
+				attribute.String("rpc.method", method),
+			}
+			if err != nil {
+				attrs = append(attrs, attribute.String("rpc.connect_rpc.error_code", connect.CodeOf(err).String()))
+			}
+			opt := metric.WithAttributes(attrs...)
+			ins.duration.Record(ctx, duration, opt)
+			ins.requests.Add(ctx, 1, opt)
+
+			return resp, err
+		}
+	}, nil
+}
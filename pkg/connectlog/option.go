@@ -1,9 +1,32 @@
 package connectlog
 
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"connectrpc.com/connect"
+	"github.com/mycujoo/go-stdlib/pkg/ctxslog"
+)
+
 type Option func(o *options)
 
 type options struct {
-	logSuccess bool
+	logSuccess    bool
+	trustedHops   int
+	loggedHeaders []string
+	skipExact     map[string]bool
+	skipPrefixes  []string
+
+	internalMessage string
+	errorID         bool
+	traceIDs        bool
+	rpcSemconv      bool
+	unmasked        func(ctx context.Context, err error) bool
+	errorHooks      []func(ctx context.Context, spec connect.Spec, err error)
+	procedureLevels map[string]slog.Level
 }
 
 func WithSuccess() Option {
@@ -11,3 +34,186 @@ func WithSuccess() Option {
 		o.logSuccess = true
 	}
 }
+
+// WithTrustedProxyHops sets the number of trusted proxies that append to the X-Forwarded-For header after the one
+// that adds the client IP, which is logged as client_ip: the client IP is the entry at len-1-hops of the header.
+// The entries before it are set by clients, who could choose the logged IP otherwise.
+// Defaults to 0, the last entry, e.g. behind a single reverse proxy. Use 1 behind the GCP load balancers, which
+// append the client IP and their own address.
+func WithTrustedProxyHops(hops int) Option {
+	return func(o *options) {
+		o.trustedHops = hops
+	}
+}
+
+// WithLoggedHeaders adds the values of the request headers with the given names to the context logger,
+// in the "headers" group, e.g. WithLoggedHeaders("x-tenant-id", "x-api-version").
+// The authorization, proxy-authorization and cookie headers are never logged.
+func WithLoggedHeaders(names ...string) Option {
+	return func(o *options) {
+		for _, name := range names {
+			name = strings.ToLower(name)
+			if !sensitiveHeaders[name] {
+				o.loggedHeaders = append(o.loggedHeaders, name)
+			}
+		}
+	}
+}
+
+// WithSkipProcedures disables logging of calls of the given procedures, e.g. health checks and reflection calls.
+// Procedures ending with "*" match procedures starting with the rest, e.g. "/grpc.reflection.v1.ServerReflection/*",
+// others match exactly, e.g. "/grpc.health.v1.Health/Check".
+func WithSkipProcedures(procedures ...string) Option {
+	return func(o *options) {
+		for _, p := range procedures {
+			if prefix, ok := strings.CutSuffix(p, "*"); ok {
+				o.skipPrefixes = append(o.skipPrefixes, prefix)
+				continue
+			}
+			if o.skipExact == nil {
+				o.skipExact = make(map[string]bool)
+			}
+			o.skipExact[p] = true
+		}
+	}
+}
+
+func (o *options) skip(procedure string) bool {
+	if o.skipExact[procedure] {
+		return true
+	}
+	for _, prefix := range o.skipPrefixes {
+		if strings.HasPrefix(procedure, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// WithTraceIDs adds the trace and span IDs of the span of the call to the logs, as trace_id and span_id,
+// for handlers that don't add them, unlike gcplog.Handler which adds the trace fields of GCP.
+func WithTraceIDs() Option {
+	return func(o *options) {
+		o.traceIDs = true
+	}
+}
+
+// WithRPCSemanticConventions logs the service, method and code of calls with the keys of the OpenTelemetry semantic
+// conventions, rpc.system, rpc.service, rpc.method and rpc.grpc.status_code, instead of service, method and code,
+// so that they match the attributes of spans.
+func WithRPCSemanticConventions() Option {
+	return func(o *options) {
+		o.rpcSemconv = true
+	}
+}
+
+// methodFields returns the service and method of a call.
+func (o *options) methodFields(spec connect.Spec) []any {
+	if !o.rpcSemconv {
+		return methodFields(spec)
+	}
+	svc, method := splitProcedure(spec.Procedure)
+	return []any{
+		slog.String("rpc.system", "connect_rpc"),
+		slog.String("rpc.service", svc),
+		slog.String("rpc.method", method),
+	}
+}
+
+// codeAttr returns the code of a failed call.
+func (o *options) codeAttr(code connect.Code) slog.Attr {
+	if o.rpcSemconv {
+		// Codes of connect have the values of gRPC status codes.
+		return slog.Int("rpc.grpc.status_code", int(code))
+	}
+	return slog.String("code", code.String())
+}
+
+// WithInternalErrorMessage sets the message of the error returned to clients instead of unexpected errors,
+// which are not connect errors. Defaults to "internal error".
+func WithInternalErrorMessage(msg string) Option {
+	return func(o *options) {
+		o.internalMessage = msg
+	}
+}
+
+// WithErrorID adds an ID to the message of the error returned to clients instead of unexpected errors,
+// e.g. "internal error (id: 8c7dd922...)", and logs it as error_id, so that reports of clients can be correlated with
+// the logs. The ID is the request ID set with ctxslog.SetRequestID, or a new one.
+func WithErrorID() Option {
+	return func(o *options) {
+		o.errorID = true
+	}
+}
+
+// WithUnmaskedErrors returns unexpected errors for which unmasked returns true to clients with their message and
+// code internal, instead of hiding them, e.g. for trusted internal callers.
+func WithUnmaskedErrors(unmasked func(ctx context.Context, err error) bool) Option {
+	return func(o *options) {
+		o.unmasked = unmasked
+	}
+}
+
+// maskError returns the error returned to clients instead of an unexpected error,
+// and the attributes to log with it.
+func (o *options) maskError(ctx context.Context, err error) (*connect.Error, []slog.Attr) {
+	if o.unmasked != nil && o.unmasked(ctx, err) {
+		return connect.NewError(connect.CodeInternal, err), nil
+	}
+	if o.internalMessage == "" && !o.errorID {
+		return connect.NewError(connect.CodeInternal, errInternal), nil
+	}
+	msg := o.internalMessage
+	if msg == "" {
+		msg = errInternal.Error()
+	}
+	var attrs []slog.Attr
+	if o.errorID {
+		id := ctxslog.RequestID(ctx)
+		if id == "" {
+			id = ctxslog.NewRequestID()
+		}
+		msg = fmt.Sprintf("%s (id: %s)", msg, id)
+		attrs = append(attrs, slog.String("error_id", id))
+	}
+	return connect.NewError(connect.CodeInternal, errors.New(msg)), attrs
+}
+
+// WithErrorHook calls hook with every error returned by handlers, before it is masked, e.g. to report errors to an
+// external service or to count them. The hook is also called for procedures skipped with WithSkipProcedures.
+// Several hooks are called in the order they were added.
+func WithErrorHook(hook func(ctx context.Context, spec connect.Spec, err error)) Option {
+	return func(o *options) {
+		o.errorHooks = append(o.errorHooks, hook)
+	}
+}
+
+// WithProcedureLevels sets the level of the log lines of successful calls of the given procedures, e.g. DEBUG for a
+// polling endpoint, instead of INFO. Failed calls are logged at the level of their code, or at the given level if it
+// is higher. Procedures match exactly, e.g. "/some.package.v1.Service/Method".
+func WithProcedureLevels(levels map[string]slog.Level) Option {
+	return func(o *options) {
+		if o.procedureLevels == nil {
+			o.procedureLevels = make(map[string]slog.Level, len(levels))
+		}
+		for procedure, level := range levels {
+			o.procedureLevels[procedure] = level
+		}
+	}
+}
+
+// successLevel returns the level of the log line of a successful call.
+func (o *options) successLevel(procedure string, level slog.Level) slog.Level {
+	if l, ok := o.procedureLevels[procedure]; ok {
+		return l
+	}
+	return level
+}
+
+// errorLevel returns the level of the log line of a failed call, which is at least the level of the procedure.
+func (o *options) errorLevel(procedure string, level slog.Level) slog.Level {
+	if l, ok := o.procedureLevels[procedure]; ok && l > level {
+		return l
+	}
+	return level
+}
@@ -0,0 +1,67 @@
+package connectlog
+
+import (
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+
+	"connectrpc.com/connect"
+)
+
+// peerFields returns the client IP, user agent and protocol of a request handled by a server.
+func peerFields(peer connect.Peer, header http.Header, trustedHops int) []any {
+	var fields []any
+	if ip := clientIP(peer, header, trustedHops); ip != "" {
+		fields = append(fields, slog.String("client_ip", ip))
+	}
+	if ua := header.Get("User-Agent"); ua != "" {
+		fields = append(fields, slog.String("user_agent", ua))
+	}
+	if protocol := peer.Protocol; protocol != "" {
+		fields = append(fields, slog.String("protocol", protocol))
+	}
+	return fields
+}
+
+// clientIP returns the entry of the X-Forwarded-For header added by the outermost trusted proxy, which is at
+// len-1-trustedHops, since proxies append to the header and the entries before them are set by the client.
+// It returns the address of the peer when the header has fewer entries.
+func clientIP(peer connect.Peer, header http.Header, trustedHops int) string {
+	var entries []string
+	// Proxies can add the header again instead of appending to it.
+	for _, value := range header.Values("X-Forwarded-For") {
+		for _, entry := range strings.Split(value, ",") {
+			entries = append(entries, strings.TrimSpace(entry))
+		}
+	}
+	if i := len(entries) - 1 - trustedHops; i >= 0 && i < len(entries) && entries[i] != "" {
+		return entries[i]
+	}
+	addr := peer.Addr
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+// sensitiveHeaders are never logged.
+var sensitiveHeaders = map[string]bool{
+	"authorization":       true,
+	"proxy-authorization": true,
+	"cookie":              true,
+}
+
+// headerFields returns the values of the request headers with the given names, in the "headers" group.
+func headerFields(header http.Header, names []string) []any {
+	var attrs []any
+	for _, name := range names {
+		if values := header.Values(name); len(values) > 0 {
+			attrs = append(attrs, slog.String(name, strings.Join(values, ",")))
+		}
+	}
+	if len(attrs) == 0 {
+		return nil
+	}
+	return []any{slog.Group("headers", attrs...)}
+}
@@ -0,0 +1,57 @@
+package connectlog
+
+import (
+	"errors"
+	"log/slog"
+	"reflect"
+	"runtime"
+	"strconv"
+)
+
+// reportContext returns the context of GCP Error Reporting for an error, like gcplog.NewReportContext,
+// so that the errors are grouped by the location where they were created.
+// The location is the innermost frame of the deepest stack of the error, e.g. created with github.com/pkg/errors,
+// or the procedure if the error has no stack.
+// see: https://cloud.google.com/error-reporting/docs/formatting-error-messages
+func reportContext(err error, procedure string) slog.Attr {
+	location := []any{slog.String("functionName", procedure)}
+	if pc := errorPC(err); pc != 0 {
+		f, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+		location = []any{
+			slog.String("filePath", f.File),
+			slog.String("lineNumber", strconv.Itoa(f.Line)),
+			slog.String("functionName", f.Function),
+		}
+	}
+	return slog.Group("context", slog.Group("reportLocation", location...))
+}
+
+// errorPC returns the first program counter of the deepest stack in the chain of err, or 0.
+func errorPC(err error) uintptr {
+	var pc uintptr
+	for ; err != nil; err = errors.Unwrap(err) {
+		if pcs := stackTracerPCs(err); len(pcs) > 0 {
+			pc = pcs[0]
+		}
+	}
+	return pc
+}
+
+// stackTracerPCs returns the stack of an error with a StackTrace method returning a slice of program counters,
+// e.g. github.com/pkg/errors.StackTrace, without depending on the package.
+func stackTracerPCs(err error) []uintptr {
+	m := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !m.IsValid() {
+		return nil
+	}
+	t := m.Type()
+	if t.NumIn() != 0 || t.NumOut() != 1 || t.Out(0).Kind() != reflect.Slice || t.Out(0).Elem().Kind() != reflect.Uintptr {
+		return nil
+	}
+	st := m.Call(nil)[0]
+	pcs := make([]uintptr, st.Len())
+	for i := range pcs {
+		pcs[i] = uintptr(st.Index(i).Uint())
+	}
+	return pcs
+}
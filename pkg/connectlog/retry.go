@@ -0,0 +1,34 @@
+package connectlog
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+// Headers with the number of previous attempts of a call, of gRPC retries and of clients retrying connect calls.
+var retryAttemptHeaders = []string{"grpc-previous-rpc-attempts", "connect-retry-attempt"}
+
+// Headers with the idempotency key of a call, of the IETF draft and its common predecessor.
+var idempotencyKeyHeaders = []string{"idempotency-key", "x-idempotency-key"}
+
+// retryFields returns the retry attempt and the idempotency key of a request, if it has them,
+// to distinguish first attempts from retries.
+func retryFields(header http.Header) []any {
+	var fields []any
+	for _, name := range retryAttemptHeaders {
+		if v := header.Get(name); v != "" {
+			if attempt, err := strconv.Atoi(v); err == nil {
+				fields = append(fields, slog.Int("retry_attempt", attempt))
+				break
+			}
+		}
+	}
+	for _, name := range idempotencyKeyHeaders {
+		if v := header.Get(name); v != "" {
+			fields = append(fields, slog.String("idempotency_key", v))
+			break
+		}
+	}
+	return fields
+}
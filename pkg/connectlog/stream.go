@@ -0,0 +1,124 @@
+package connectlog
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+
+	"connectrpc.com/connect"
+)
+
+// WrapStreamingHandler implements connect.Interceptor.
+// Streams are logged like unary calls when the handler returns, with the number of messages received and sent.
+func (i *Interceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		ctx = i.handlerContext(ctx, conn.Spec(), conn.Peer(), conn.RequestHeader())
+		counted := &streamingHandlerConn{StreamingHandlerConn: conn}
+		start := time.Now()
+		err := next(ctx, counted)
+		attrs := streamAttrs(time.Since(start), counted.received, counted.sent)
+		return i.handlerDone(ctx, conn.Spec(), start, attrs, err)
+	}
+}
+
+// WrapStreamingClient implements connect.Interceptor.
+// Streams are logged when the response is closed, with the first error returned by Send or Receive.
+func (i *Interceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return func(ctx context.Context, spec connect.Spec) connect.StreamingClientConn {
+		conn := next(ctx, spec)
+		if i.o.skip(spec.Procedure) {
+			return conn
+		}
+		return &streamingClientConn{
+			StreamingClientConn: conn,
+			ctx:                 ctx,
+			interceptor:         i,
+			start:               time.Now(),
+		}
+	}
+}
+
+// streamAttrs returns the duration of a stream and the number of messages received and sent by the handler.
+func streamAttrs(duration time.Duration, received, sent int) []slog.Attr {
+	return []slog.Attr{
+		slog.Float64("duration_ms", float64(duration)/float64(time.Millisecond)),
+		slog.Int("request_messages", received),
+		slog.Int("response_messages", sent),
+	}
+}
+
+type streamingHandlerConn struct {
+	connect.StreamingHandlerConn
+	received int
+	sent     int
+}
+
+func (c *streamingHandlerConn) Receive(msg any) error {
+	err := c.StreamingHandlerConn.Receive(msg)
+	if err == nil {
+		c.received++
+	}
+	return err
+}
+
+func (c *streamingHandlerConn) Send(msg any) error {
+	err := c.StreamingHandlerConn.Send(msg)
+	if err == nil {
+		c.sent++
+	}
+	return err
+}
+
+type streamingClientConn struct {
+	connect.StreamingClientConn
+	ctx         context.Context
+	interceptor *Interceptor
+	start       time.Time
+
+	mu       sync.Mutex
+	sent     int
+	received int
+	err      error
+	logged   bool
+}
+
+func (c *streamingClientConn) Send(msg any) error {
+	err := c.StreamingClientConn.Send(msg)
+	c.mu.Lock()
+	// Send returns io.EOF when the server closed the stream, the error is then returned by Receive.
+	if err == nil {
+		c.sent++
+	} else if !errors.Is(err, io.EOF) && c.err == nil {
+		c.err = err
+	}
+	c.mu.Unlock()
+	return err
+}
+
+func (c *streamingClientConn) Receive(msg any) error {
+	err := c.StreamingClientConn.Receive(msg)
+	c.mu.Lock()
+	if err == nil {
+		c.received++
+	} else if !errors.Is(err, io.EOF) && c.err == nil {
+		c.err = err
+	}
+	c.mu.Unlock()
+	return err
+}
+
+func (c *streamingClientConn) CloseResponse() error {
+	err := c.StreamingClientConn.CloseResponse()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.logged {
+		c.logged = true
+		// The counts are from the side of the handler, which receives what the client sends.
+		attrs := streamAttrs(time.Since(c.start), c.sent, c.received)
+		c.interceptor.clientDone(c.ctx, c.Spec(), attrs, c.err)
+	}
+	return err
+}
@@ -0,0 +1,38 @@
+package ctxslog
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/baggage"
+)
+
+var baggageKeys atomic.Pointer[[]string]
+
+// LogBaggage sets the keys of the OpenTelemetry baggage members to log, e.g. "tenant_id" and "user_id".
+// Members of the baggage in the context with these keys are added as attributes,
+// like the attributes added with AddArgs, by Extract, by the functions of this package and by Handler.
+// It is meant to be called once when the program starts, calling it again replaces the keys.
+func LogBaggage(keys ...string) {
+	keys = append([]string(nil), keys...)
+	baggageKeys.Store(&keys)
+}
+
+// appendBaggage appends the baggage members of ctx with the keys set with LogBaggage to attrs.
+func appendBaggage(ctx context.Context, attrs []slog.Attr) []slog.Attr {
+	keys := baggageKeys.Load()
+	if keys == nil {
+		return attrs
+	}
+	b := baggage.FromContext(ctx)
+	if b.Len() == 0 {
+		return attrs
+	}
+	for _, key := range *keys {
+		if m := b.Member(key); m.Key() != "" {
+			attrs = append(attrs, slog.String(key, m.Value()))
+		}
+	}
+	return attrs
+}
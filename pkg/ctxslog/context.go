@@ -11,7 +11,7 @@ type ctxMarker struct{}
 
 type ctxLogger struct {
 	logger *slog.Logger
-	args   []any
+	attrs  []slog.Attr
 }
 
 var (
@@ -19,6 +19,8 @@ var (
 )
 
 // AddArgs adds attributes to the context logger.
+// The args are converted to attributes like the args of slog.Logger.Log: alternating keys and values, or slog.Attr.
+// Values without a key, and keys without a value, are added with the key "!BADKEY".
 func AddArgs(ctx context.Context, args ...any) {
 	l, ok := ctx.Value(ctxMarkerKey).(*ctxLogger)
 	if !ok || l == nil {
@@ -27,18 +29,100 @@ func AddArgs(ctx context.Context, args ...any) {
 		slog.Default().Warn("trying to add args to a context that doesn't have a logger")
 		return
 	}
-	l.args = append(l.args, args...)
+	l.attrs = appendArgs(l.attrs, args)
+}
+
+const badKey = "!BADKEY"
+
+// appendArgs appends args converted to attributes to attrs.
+func appendArgs(attrs []slog.Attr, args []any) []slog.Attr {
+	for len(args) > 0 {
+		switch x := args[0].(type) {
+		case slog.Attr:
+			attrs = append(attrs, x)
+			args = args[1:]
+		case string:
+			if len(args) == 1 {
+				attrs = append(attrs, slog.String(badKey, x))
+				args = nil
+				continue
+			}
+			attrs = append(attrs, slog.Any(x, args[1]))
+			args = args[2:]
+		default:
+			attrs = append(attrs, slog.Any(badKey, x))
+			args = args[1:]
+		}
+	}
+	return attrs
+}
+
+// Args returns the attributes added to the context logger with AddArgs, as slog.Attr args.
+// It can be used by handlers to add them to records logged without the context logger,
+// e.g. as gcplog.HandlerOptions.ContextArgs.
+func Args(ctx context.Context) []any {
+	attrs := Attrs(ctx)
+	if attrs == nil {
+		return nil
+	}
+	args := make([]any, len(attrs))
+	for i, a := range attrs {
+		args[i] = a
+	}
+	return args
+}
+
+// Attrs returns the attributes added to the context logger with AddArgs.
+func Attrs(ctx context.Context) []slog.Attr {
+	l, ok := ctx.Value(ctxMarkerKey).(*ctxLogger)
+	if !ok || l == nil {
+		return nil
+	}
+	return l.attrs
 }
 
 // Extract returns the context-scoped Logger.
 //
 // It always returns a Logger.
+//
+// Lazy values of the context args are resolved by the handler of the logger when it is extracted,
+// while the functions of this package and Handler only resolve them for records that are logged.
+// When the handler of the logger is a Handler, the returned logger adds the context args of ctx to its records, also
+// when they are logged with another context, e.g. with Info.
 func Extract(ctx context.Context) *slog.Logger {
-	l, ok := ctx.Value(ctxMarkerKey).(*ctxLogger)
-	if !ok || l == nil {
-		return slog.Default()
+	logger, attrs := extractLazy(ctx, true)
+	if len(attrs) == 0 {
+		return logger
+	}
+	return slog.New(logger.Handler().WithAttrs(attrs))
+}
+
+// extractLazy returns the context-scoped Logger without the context args, and the attributes to add to its records.
+// If the handler of the logger is a Handler, it adds the args itself: with bind, the returned logger has a Handler
+// taking them from ctx, for records handled with another context.
+func extractLazy(ctx context.Context, bind bool) (*slog.Logger, []slog.Attr) {
+	logger := slog.Default()
+	if l, ok := ctx.Value(ctxMarkerKey).(*ctxLogger); ok && l != nil {
+		logger = l.logger
+	}
+	var attrs []slog.Attr
+	if h, ok := logger.Handler().(*Handler); !ok {
+		attrs = contextAttrs(ctx)
+	} else if bind {
+		logger = slog.New(h.withContext(ctx))
+	}
+	if level, ok := levelFromContext(ctx); ok {
+		logger = slog.New(&levelHandler{Handler: logger.Handler(), level: level})
 	}
-	return l.logger.With(l.args...)
+	return logger, attrs
+}
+
+// contextAttrs returns the attributes added with AddArgs, the logged baggage members and the attributes returned by
+// the extract hooks of ctx.
+func contextAttrs(ctx context.Context) []slog.Attr {
+	attrs := Attrs(ctx)
+	attrs = appendBaggage(ctx, attrs[:len(attrs):len(attrs)])
+	return appendHooks(ctx, attrs)
 }
 
 // ToContext adds the slog.Logger to the context for extraction later.
@@ -52,52 +136,71 @@ func ToContext(ctx context.Context, logger *slog.Logger) context.Context {
 
 // Debug is equivalent to calling Debug on the logger in the context.
 func Debug(ctx context.Context, msg string, args ...any) {
-	l := Extract(ctx)
-	if !l.Enabled(context.Background(), slog.LevelDebug) {
-		return
-	}
-	var pcs [1]uintptr
-	runtime.Callers(2, pcs[:]) // skip [Callers, Debug]
-	r := slog.NewRecord(time.Now(), slog.LevelDebug, msg, pcs[0])
-	r.Add(args...)
-	_ = l.Handler().Handle(ctx, r)
+	log(ctx, slog.LevelDebug, msg, args)
 }
 
 // Info is equivalent to calling Info on the logger in the context.
 func Info(ctx context.Context, msg string, args ...any) {
-	l := Extract(ctx)
-	if !l.Enabled(context.Background(), slog.LevelInfo) {
-		return
-	}
-	var pcs [1]uintptr
-	runtime.Callers(2, pcs[:]) // skip [Callers, Info]
-	r := slog.NewRecord(time.Now(), slog.LevelInfo, msg, pcs[0])
-	r.Add(args...)
-	_ = l.Handler().Handle(ctx, r)
+	log(ctx, slog.LevelInfo, msg, args)
 }
 
 // Warn is equivalent to calling Warn on the logger in the context.
 func Warn(ctx context.Context, msg string, args ...any) {
-	l := Extract(ctx)
-	if !l.Enabled(context.Background(), slog.LevelWarn) {
+	log(ctx, slog.LevelWarn, msg, args)
+}
+
+// Error is equivalent to calling Error on the logger in the context.
+func Error(ctx context.Context, msg string, args ...any) {
+	log(ctx, slog.LevelError, msg, args)
+}
+
+// DebugAttrs is a more efficient version of Debug that accepts only attributes.
+func DebugAttrs(ctx context.Context, msg string, attrs ...slog.Attr) {
+	logAttrs(ctx, slog.LevelDebug, msg, attrs)
+}
+
+// InfoAttrs is a more efficient version of Info that accepts only attributes.
+func InfoAttrs(ctx context.Context, msg string, attrs ...slog.Attr) {
+	logAttrs(ctx, slog.LevelInfo, msg, attrs)
+}
+
+// WarnAttrs is a more efficient version of Warn that accepts only attributes.
+func WarnAttrs(ctx context.Context, msg string, attrs ...slog.Attr) {
+	logAttrs(ctx, slog.LevelWarn, msg, attrs)
+}
+
+// ErrorAttrs is a more efficient version of Error that accepts only attributes.
+func ErrorAttrs(ctx context.Context, msg string, attrs ...slog.Attr) {
+	logAttrs(ctx, slog.LevelError, msg, attrs)
+}
+
+// log logs a record with the context args added to it only when the level is enabled,
+// so that Lazy values of args that are not logged are not resolved.
+// It must be called directly by the exported functions, as the caller of the exported function is the source.
+func log(ctx context.Context, level slog.Level, msg string, args []any) {
+	logger, ctxAttrs := extractLazy(ctx, false)
+	if !logger.Enabled(context.Background(), level) {
 		return
 	}
 	var pcs [1]uintptr
-	runtime.Callers(2, pcs[:]) // skip [Callers, Warn]
-	r := slog.NewRecord(time.Now(), slog.LevelWarn, msg, pcs[0])
+	runtime.Callers(3, pcs[:]) // skip [Callers, log, Debug/Info/Warn/Error]
+	r := slog.NewRecord(time.Now(), level, msg, pcs[0])
+	r.AddAttrs(ctxAttrs...)
 	r.Add(args...)
-	_ = l.Handler().Handle(ctx, r)
+	_ = logger.Handler().Handle(ctx, r)
 }
 
-// Error is equivalent to calling Error on the logger in the context.
-func Error(ctx context.Context, msg string, args ...any) {
-	l := Extract(ctx)
-	if !l.Enabled(context.Background(), slog.LevelError) {
+// logAttrs is log with attributes.
+// It must be called directly by the exported functions, as the caller of the exported function is the source.
+func logAttrs(ctx context.Context, level slog.Level, msg string, attrs []slog.Attr) {
+	logger, ctxAttrs := extractLazy(ctx, false)
+	if !logger.Enabled(context.Background(), level) {
 		return
 	}
 	var pcs [1]uintptr
-	runtime.Callers(2, pcs[:]) // skip [Callers, Error]
-	r := slog.NewRecord(time.Now(), slog.LevelError, msg, pcs[0])
-	r.Add(args...)
-	_ = l.Handler().Handle(ctx, r)
+	runtime.Callers(3, pcs[:]) // skip [Callers, logAttrs, DebugAttrs/InfoAttrs/WarnAttrs/ErrorAttrs]
+	r := slog.NewRecord(time.Now(), level, msg, pcs[0])
+	r.AddAttrs(ctxAttrs...)
+	r.AddAttrs(attrs...)
+	_ = logger.Handler().Handle(ctx, r)
 }
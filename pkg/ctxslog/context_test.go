@@ -0,0 +1,56 @@
+package ctxslog_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/mycujoo/go-stdlib/pkg/ctxslog"
+	"github.com/mycujoo/go-stdlib/pkg/ctxslog/ctxslogtest"
+)
+
+func TestInfoAttrs(t *testing.T) {
+	ctx, rec := ctxslogtest.NewContext(t)
+	ctxslog.AddArgs(ctx, "request_id", "abc")
+
+	ctxslog.InfoAttrs(ctx, "processed", slog.Int("items", 3))
+	_, file, line, _ := runtime.Caller(0)
+
+	records := rec.Records()
+	if len(records) != 1 || records[0].Level != slog.LevelInfo {
+		t.Fatalf("unexpected records: %v", records)
+	}
+	var attrs []string
+	records[0].Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a.String())
+		return true
+	})
+	if expected := []string{"request_id=abc", "items=3"}; !reflect.DeepEqual(attrs, expected) {
+		t.Errorf("unexpected attrs: %v, expected %v", attrs, expected)
+	}
+	f, _ := runtime.CallersFrames([]uintptr{records[0].PC}).Next()
+	if f.File != file || f.Line != line-1 {
+		t.Errorf("unexpected source: %s:%d", f.File, f.Line)
+	}
+}
+
+func BenchmarkInfo(b *testing.B) {
+	ctx := ctxslog.ToContext(context.Background(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	ctxslog.AddArgs(ctx, "request_id", "abc")
+
+	b.Run("args", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			ctxslog.Info(ctx, "processed", "items", 3, "name", "batch")
+		}
+	})
+	b.Run("attrs", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			ctxslog.InfoAttrs(ctx, "processed", slog.Int("items", 3), slog.String("name", "batch"))
+		}
+	})
+}
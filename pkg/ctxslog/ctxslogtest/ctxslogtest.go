@@ -0,0 +1,114 @@
+// Package ctxslogtest provides a context logger recording the logged records, for assertions in tests of code logging
+// with ctxslog.
+package ctxslogtest
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+
+	"github.com/mycujoo/go-stdlib/pkg/ctxslog"
+)
+
+// NewContext returns a context with a logger recording all records, of all levels, for assertions in tests.
+// The recorded records are written to the test log if the test fails.
+func NewContext(t testing.TB) (context.Context, *Recorder) {
+	rec := &Recorder{}
+	t.Cleanup(func() {
+		if !t.Failed() {
+			return
+		}
+		for _, r := range rec.Records() {
+			t.Logf("%s %s %s", r.Level, r.Message, recordArgs(r))
+		}
+	})
+	return ctxslog.ToContext(context.Background(), slog.New(&recordingHandler{rec: rec})), rec
+}
+
+// Recorder records the records logged with the context returned by NewContext.
+type Recorder struct {
+	mu      sync.Mutex
+	records []slog.Record
+}
+
+// Records returns the recorded records. The attributes added with slog.Logger.With and WithGroup, and the context args,
+// are part of the records.
+func (r *Recorder) Records() []slog.Record {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	records := make([]slog.Record, len(r.records))
+	copy(records, r.records)
+	return records
+}
+
+// Messages returns the messages of the recorded records.
+func (r *Recorder) Messages() []string {
+	records := r.Records()
+	msgs := make([]string, len(records))
+	for i, rec := range records {
+		msgs[i] = rec.Message
+	}
+	return msgs
+}
+
+func (r *Recorder) add(rec slog.Record) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records = append(r.records, rec)
+}
+
+func recordArgs(r slog.Record) []slog.Attr {
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	return attrs
+}
+
+type recordingHandler struct {
+	rec *Recorder
+	ops []handlerOp
+}
+
+// handlerOp is a group or attributes added to a recordingHandler, added to the records when handling them.
+type handlerOp struct {
+	group string
+	attrs []slog.Attr
+}
+
+func (h *recordingHandler) Enabled(context.Context, slog.Level) bool {
+	return true
+}
+
+// Handle records r with the attributes and groups of the handler added to it.
+func (h *recordingHandler) Handle(_ context.Context, r slog.Record) error {
+	attrs := recordArgs(r)
+	for i := len(h.ops) - 1; i >= 0; i-- {
+		op := h.ops[i]
+		if op.group == "" {
+			attrs = append(op.attrs[:len(op.attrs):len(op.attrs)], attrs...)
+		} else if len(attrs) > 0 {
+			attrs = []slog.Attr{{Key: op.group, Value: slog.GroupValue(attrs...)}}
+		}
+	}
+	rec := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	rec.AddAttrs(attrs...)
+	h.rec.add(rec)
+	return nil
+}
+
+func (h *recordingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	return &recordingHandler{rec: h.rec, ops: append(h.ops[:len(h.ops):len(h.ops)], handlerOp{attrs: attrs})}
+}
+
+func (h *recordingHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &recordingHandler{rec: h.rec, ops: append(h.ops[:len(h.ops):len(h.ops)], handlerOp{group: name})}
+}
@@ -0,0 +1,34 @@
+package ctxslogtest_test
+
+import (
+	"log/slog"
+	"reflect"
+	"testing"
+
+	"github.com/mycujoo/go-stdlib/pkg/ctxslog"
+	"github.com/mycujoo/go-stdlib/pkg/ctxslog/ctxslogtest"
+)
+
+func TestNewContext(t *testing.T) {
+	ctx, rec := ctxslogtest.NewContext(t)
+	ctxslog.AddArgs(ctx, "request_id", "abc")
+
+	ctxslog.Debug(ctx, "loading")
+	ctxslog.Extract(ctx).WithGroup("user").With("name", "alice").Info("loaded", "id", 1)
+
+	if msgs := rec.Messages(); !reflect.DeepEqual(msgs, []string{"loading", "loaded"}) {
+		t.Fatalf("unexpected messages: %v", msgs)
+	}
+	records := rec.Records()
+	if records[0].Level != slog.LevelDebug {
+		t.Errorf("unexpected level: %s", records[0].Level)
+	}
+	var attrs []string
+	records[1].Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a.String())
+		return true
+	})
+	if expected := []string{"request_id=abc", "user=[name=alice id=1]"}; !reflect.DeepEqual(attrs, expected) {
+		t.Errorf("unexpected attrs: %v, expected %v", attrs, expected)
+	}
+}
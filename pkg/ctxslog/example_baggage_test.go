@@ -0,0 +1,27 @@
+package ctxslog_test
+
+import (
+	"context"
+	"log/slog"
+	"os"
+
+	"github.com/mycujoo/go-stdlib/pkg/ctxslog"
+	"go.opentelemetry.io/otel/baggage"
+)
+
+func ExampleLogBaggage() {
+	ctxslog.LogBaggage("tenant_id")
+	defer ctxslog.LogBaggage()
+
+	th := slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{ReplaceAttr: RemoveTimeAndBaseSource})
+	ctx := ctxslog.ToContext(context.Background(), slog.New(th))
+
+	tenant, _ := baggage.NewMember("tenant_id", "acme")
+	user, _ := baggage.NewMember("user_id", "alice")
+	b, _ := baggage.New(tenant, user)
+	ctx = baggage.ContextWithBaggage(ctx, b)
+
+	ctxslog.Info(ctx, "order created")
+	// Output:
+	// level=INFO msg="order created" tenant_id=acme
+}
@@ -0,0 +1,32 @@
+package ctxslog_test
+
+import (
+	"context"
+	"log/slog"
+	"os"
+
+	"github.com/mycujoo/go-stdlib/pkg/ctxslog"
+)
+
+type tenantKey struct{}
+
+func ExampleWithExtractHook() {
+	th := slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{ReplaceAttr: RemoveTimeAndBaseSource})
+	ctx := ctxslog.ToContext(context.Background(), slog.New(th))
+
+	ctx = ctxslog.WithExtractHook(ctx, func(ctx context.Context) []slog.Attr {
+		if tenant, ok := ctx.Value(tenantKey{}).(string); ok {
+			return []slog.Attr{slog.String("tenant", tenant)}
+		}
+		return nil
+	})
+	ctxslog.Info(ctx, "job started")
+
+	ctx = context.WithValue(ctx, tenantKey{}, "acme")
+	ctxslog.Info(ctx, "tenant processed")
+	ctxslog.Extract(ctx).Info("extracted")
+	// Output:
+	// level=INFO msg="job started"
+	// level=INFO msg="tenant processed" tenant=acme
+	// level=INFO msg=extracted tenant=acme
+}
@@ -0,0 +1,34 @@
+package ctxslog_test
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/mycujoo/go-stdlib/pkg/ctxslog"
+)
+
+func ExampleEveryN() {
+	th := slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{ReplaceAttr: RemoveTimeAndBaseSource})
+	ctx := ctxslog.ToContext(context.Background(), slog.New(th))
+
+	for i := 0; i < 7; i++ {
+		ctxslog.EveryN(ctx, "example-invalid-item", 3).Warn("invalid item", "id", i)
+	}
+	// Output:
+	// level=WARN msg="invalid item" id=0
+	// level=WARN msg="invalid item" id=3 suppressed=2
+	// level=WARN msg="invalid item" id=6 suppressed=2
+}
+
+func ExampleEvery() {
+	th := slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{ReplaceAttr: RemoveTimeAndBaseSource})
+	ctx := ctxslog.ToContext(context.Background(), slog.New(th))
+
+	for i := 0; i < 3; i++ {
+		ctxslog.Every(ctx, "example-slow-item", time.Hour).Warn("slow item", "id", i)
+	}
+	// Output:
+	// level=WARN msg="slow item" id=0
+}
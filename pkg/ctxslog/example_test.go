@@ -40,6 +40,95 @@ func ExampleToContext() {
 	// level=INFO source=example_test.go:36 msg="this is a log" name=mycujoo group.test=a
 }
 
+func ExampleArgs() {
+	ctx := ctxslog.ToContext(context.Background(), slog.Default())
+	ctxslog.AddArgs(ctx, "request_id", "abc")
+
+	fmt.Println(ctxslog.Args(ctx))
+	fmt.Println(ctxslog.Args(context.Background()))
+	// Output:
+	// [request_id=abc]
+	// []
+}
+
+func ExampleNewHandler() {
+	th := slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{ReplaceAttr: RemoveTimeAndBaseSource})
+	logger := slog.New(ctxslog.NewHandler(th))
+
+	ctx := ctxslog.ToContext(context.Background(), logger)
+	ctxslog.AddArgs(ctx, "request_id", "abc")
+
+	logger.InfoContext(ctx, "logged with slog")
+	logger.WithGroup("group").InfoContext(ctx, "logged in a group", "test", "a")
+	ctxslog.Info(ctx, "logged with ctxslog")
+	// Output:
+	// level=INFO msg="logged with slog" request_id=abc
+	// level=INFO msg="logged in a group" request_id=abc group.test=a
+	// level=INFO msg="logged with ctxslog" request_id=abc
+}
+
+func ExampleWithLevel() {
+	th := slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{ReplaceAttr: RemoveTimeAndBaseSource})
+	logger := slog.New(th)
+
+	ctx := ctxslog.ToContext(context.Background(), logger)
+	ctxslog.Debug(ctx, "not logged")
+
+	debugCtx := ctxslog.WithLevel(ctx, slog.LevelDebug)
+	ctxslog.Debug(debugCtx, "logged for this request")
+	ctxslog.Extract(debugCtx).Debug("logged with the extracted logger")
+
+	hl := slog.New(ctxslog.NewHandler(th))
+	hl.DebugContext(debugCtx, "logged with slog")
+	// Output:
+	// level=DEBUG msg="logged for this request"
+	// level=DEBUG msg="logged with the extracted logger"
+	// level=DEBUG msg="logged with slog"
+}
+
+func ExampleLazy() {
+	th := slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{ReplaceAttr: RemoveTimeAndBaseSource})
+	ctx := ctxslog.ToContext(context.Background(), slog.New(th))
+
+	ctxslog.AddArgs(ctx, ctxslog.Lazy("digest", func() slog.Value {
+		fmt.Println("computing digest")
+		return slog.StringValue("8c7dd922")
+	}))
+
+	// The digest is not computed because the level is not enabled.
+	ctxslog.Debug(ctx, "payload received")
+	ctxslog.Info(ctx, "payload processed")
+	// Output:
+	// computing digest
+	// level=INFO msg="payload processed" digest=8c7dd922
+}
+
+func ExampleSetRequestID() {
+	th := slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{ReplaceAttr: RemoveTimeAndBaseSource})
+	ctx := ctxslog.ToContext(context.Background(), slog.New(th))
+
+	ctx = ctxslog.SetRequestID(ctx, "abc")
+	ctxslog.Info(ctx, "request received")
+	fmt.Println(ctxslog.RequestID(ctx))
+
+	generated := ctxslog.SetRequestID(context.Background(), "")
+	fmt.Println(len(ctxslog.RequestID(generated)))
+	// Output:
+	// level=INFO msg="request received" request_id=abc
+	// abc
+	// 32
+}
+
+func ExampleAddArgs() {
+	th := slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{ReplaceAttr: RemoveTimeAndBaseSource})
+	ctx := ctxslog.ToContext(context.Background(), slog.New(th))
+
+	ctxslog.AddArgs(ctx, "user", "alice", slog.Int("attempt", 2), 42, "dangling")
+	ctxslog.Info(ctx, "logged in")
+	// Output:
+	// level=INFO msg="logged in" user=alice attempt=2 !BADKEY=42 !BADKEY=dangling
+}
+
 // RemoveTimeAndBaseSource removes the top-level time attribute and simplifies the source file path.
 // It is intended to be used as a ReplaceAttr function,
 // to make example output deterministic.
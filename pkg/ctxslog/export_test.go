@@ -0,0 +1,9 @@
+package ctxslog
+
+import "os"
+
+// SetExit replaces os.Exit called by Fatal, and returns a function restoring it.
+func SetExit(f func(int)) func() {
+	exit = f
+	return func() { exit = os.Exit }
+}
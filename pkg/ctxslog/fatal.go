@@ -0,0 +1,39 @@
+package ctxslog
+
+import (
+	"context"
+	"log/slog"
+	"os"
+)
+
+// exit is os.Exit, replaced in tests.
+var exit = os.Exit
+
+// Fatal logs at slog.LevelError with the logger in the context and exits the program with status 1,
+// like log.Fatal. Deferred functions are not run, so the handler of the logger is flushed first if it has a
+// Flush() error method, e.g. a gcplog.Handler writing to a gcplog.AsyncWriter.
+func Fatal(ctx context.Context, msg string, args ...any) {
+	log(ctx, slog.LevelError, msg, args)
+	flush(ctx)
+	exit(1)
+}
+
+// Panic logs at slog.LevelError with the logger in the context and panics with msg, like log.Panic.
+func Panic(ctx context.Context, msg string, args ...any) {
+	log(ctx, slog.LevelError, msg, args)
+	panic(msg)
+}
+
+func flush(ctx context.Context) {
+	logger, _ := extractLazy(ctx, false)
+	h := logger.Handler()
+	if lh, ok := h.(*levelHandler); ok {
+		h = lh.Handler
+	}
+	if ch, ok := h.(*Handler); ok {
+		h = ch.base
+	}
+	if f, ok := h.(interface{ Flush() error }); ok {
+		_ = f.Flush()
+	}
+}
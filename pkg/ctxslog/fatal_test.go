@@ -0,0 +1,70 @@
+package ctxslog_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/mycujoo/go-stdlib/pkg/ctxslog"
+	"github.com/mycujoo/go-stdlib/pkg/ctxslog/ctxslogtest"
+)
+
+type flushHandler struct {
+	slog.Handler
+	flushed bool
+}
+
+func (h *flushHandler) Flush() error {
+	h.flushed = true
+	return nil
+}
+
+func TestFatal(t *testing.T) {
+	ctx, rec := ctxslogtest.NewContext(t)
+	var code int
+	defer ctxslog.SetExit(func(c int) { code = c })()
+
+	ctxslog.Fatal(ctx, "cannot start", "error", "no config")
+	_, _, line, _ := runtime.Caller(0)
+
+	if code != 1 {
+		t.Errorf("unexpected exit code: %d", code)
+	}
+	records := rec.Records()
+	if len(records) != 1 || records[0].Level != slog.LevelError || records[0].Message != "cannot start" {
+		t.Fatalf("unexpected records: %v", records)
+	}
+	f, _ := runtime.CallersFrames([]uintptr{records[0].PC}).Next()
+	if filepath.Base(f.File) != "fatal_test.go" || f.Line != line-1 {
+		t.Errorf("unexpected source: %s:%d", f.File, f.Line)
+	}
+}
+
+func TestFatalFlush(t *testing.T) {
+	h := &flushHandler{Handler: slog.NewTextHandler(io.Discard, nil)}
+	ctx := ctxslog.ToContext(context.Background(), slog.New(h))
+	defer ctxslog.SetExit(func(int) {})()
+
+	ctxslog.Fatal(ctx, "cannot start")
+
+	if !h.flushed {
+		t.Error("handler not flushed")
+	}
+}
+
+func TestPanic(t *testing.T) {
+	ctx, rec := ctxslogtest.NewContext(t)
+	defer func() {
+		if v := recover(); v != "invariant violated" {
+			t.Errorf("unexpected panic: %v", v)
+		}
+		if msgs := rec.Messages(); len(msgs) != 1 || msgs[0] != "invariant violated" {
+			t.Errorf("unexpected messages: %v", msgs)
+		}
+	}()
+
+	ctxslog.Panic(ctx, "invariant violated")
+}
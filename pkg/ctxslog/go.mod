@@ -1,3 +1,12 @@
 module github.com/mycujoo/go-stdlib/pkg/ctxslog
 
 go 1.21
+
+require go.opentelemetry.io/otel v1.19.0
+
+require (
+	github.com/go-logr/logr v1.2.4 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	go.opentelemetry.io/otel/metric v1.19.0 // indirect
+	go.opentelemetry.io/otel/trace v1.19.0 // indirect
+)
@@ -0,0 +1,94 @@
+package ctxslog
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Handler adds the attributes added to the context with AddArgs, the baggage members set with LogBaggage and the
+// attributes returned by extract hooks to every record it handles,
+// so that they are logged also when calling slog.InfoContext and similar directly instead of the functions of this
+// package.
+type Handler struct {
+	// Handler without the groups and attributes added with WithGroup and WithAttrs.
+	base slog.Handler
+	// base with the groups and attributes added.
+	handler slog.Handler
+	ops     []handlerOp
+	// Context the attributes are taken from instead of the context of records, for loggers returned by Extract.
+	ctx context.Context
+}
+
+// handlerOp is a group or attributes added to a Handler, replayed when adding context attributes.
+type handlerOp struct {
+	group string
+	attrs []slog.Attr
+}
+
+// NewHandler returns a handler that adds the context attributes to the records passed to inner.
+// Context attributes are added at the top level, before the attributes added with WithAttrs,
+// like with the logger returned by Extract.
+func NewHandler(inner slog.Handler) *Handler {
+	return &Handler{base: inner, handler: inner}
+}
+
+// Enabled reports whether the level is enabled by the level of the context set with WithLevel, if any,
+// or by the inner handler.
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	if minLevel, ok := levelFromContext(ctx); ok {
+		return level >= minLevel.Level()
+	}
+	return h.handler.Enabled(ctx, level)
+}
+
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	attrsCtx := ctx
+	if h.ctx != nil {
+		attrsCtx = h.ctx
+	}
+	attrs := contextAttrs(attrsCtx)
+	if len(attrs) == 0 {
+		return h.handler.Handle(ctx, r)
+	}
+	handler := h.base.WithAttrs(attrs)
+	for _, op := range h.ops {
+		if op.group != "" {
+			handler = handler.WithGroup(op.group)
+		} else {
+			handler = handler.WithAttrs(op.attrs)
+		}
+	}
+	return handler.Handle(ctx, r)
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	return h.with(handlerOp{attrs: attrs}, h.handler.WithAttrs(attrs))
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return h.with(handlerOp{group: name}, h.handler.WithGroup(name))
+}
+
+func (h *Handler) with(op handlerOp, handler slog.Handler) *Handler {
+	ops := make([]handlerOp, len(h.ops), len(h.ops)+1)
+	copy(ops, h.ops)
+	return &Handler{
+		base:    h.base,
+		handler: handler,
+		ops:     append(ops, op),
+		ctx:     h.ctx,
+	}
+}
+
+// withContext returns a copy of h adding the context attributes of ctx to all records.
+func (h *Handler) withContext(ctx context.Context) *Handler {
+	c := *h
+	c.ctx = ctx
+	return &c
+}
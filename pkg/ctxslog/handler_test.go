@@ -0,0 +1,43 @@
+package ctxslog_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/mycujoo/go-stdlib/pkg/ctxslog"
+)
+
+func TestHandlerExtract(t *testing.T) {
+	var buf bytes.Buffer
+	th := slog.NewTextHandler(&buf, &slog.HandlerOptions{ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+		if a.Key == slog.TimeKey && len(groups) == 0 {
+			return slog.Attr{}
+		}
+		return a
+	}})
+	ctx := ctxslog.ToContext(context.Background(), slog.New(ctxslog.NewHandler(th)))
+	ctxslog.AddArgs(ctx, "user", "u1")
+
+	logger := ctxslog.Extract(ctx)
+	logger.Info("extracted")
+	logger.WithGroup("g").With("a", 1).Info("extracted in a group", "b", 2)
+	ctxslog.Info(ctx, "logged with ctxslog")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	expected := []string{
+		`level=INFO msg=extracted user=u1`,
+		`level=INFO msg="extracted in a group" user=u1 g.a=1 g.b=2`,
+		`level=INFO msg="logged with ctxslog" user=u1`,
+	}
+	if len(lines) != len(expected) {
+		t.Fatalf("unexpected lines: %q", lines)
+	}
+	for i, line := range lines {
+		if line != expected[i] {
+			t.Errorf("unexpected line %d: %s, expected %s", i, line, expected[i])
+		}
+	}
+}
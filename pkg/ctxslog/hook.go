@@ -0,0 +1,52 @@
+package ctxslog
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// ExtractHook returns attributes derived from a context, e.g. the tenant of the authenticated user,
+// added like the attributes added with AddArgs.
+// Hooks are called every time the attributes of a context are added: by Extract,
+// and for every record logged with the functions of this package or with Handler.
+type ExtractHook func(ctx context.Context) []slog.Attr
+
+var (
+	hooksMu sync.RWMutex
+	hooks   []ExtractHook
+)
+
+// OnExtract registers a hook called for all contexts, e.g. to add standard fields in code paths without middleware,
+// like cron jobs and queue consumers. It is meant to be called when the program starts.
+func OnExtract(hook ExtractHook) {
+	hooksMu.Lock()
+	defer hooksMu.Unlock()
+	hooks = append(hooks, hook)
+}
+
+type hooksMarker struct{}
+
+var hooksMarkerKey = &hooksMarker{}
+
+// WithExtractHook returns a context with a hook called for it and the contexts derived from it,
+// after the hooks registered with OnExtract.
+func WithExtractHook(ctx context.Context, hook ExtractHook) context.Context {
+	parent, _ := ctx.Value(hooksMarkerKey).([]ExtractHook)
+	return context.WithValue(ctx, hooksMarkerKey, append(parent[:len(parent):len(parent)], hook))
+}
+
+// appendHooks appends the attributes returned by the hooks for ctx to attrs.
+func appendHooks(ctx context.Context, attrs []slog.Attr) []slog.Attr {
+	hooksMu.RLock()
+	global := hooks
+	hooksMu.RUnlock()
+	for _, hook := range global {
+		attrs = append(attrs, hook(ctx)...)
+	}
+	local, _ := ctx.Value(hooksMarkerKey).([]ExtractHook)
+	for _, hook := range local {
+		attrs = append(attrs, hook(ctx)...)
+	}
+	return attrs
+}
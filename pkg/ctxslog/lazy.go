@@ -0,0 +1,22 @@
+package ctxslog
+
+import "log/slog"
+
+// lazyValue is a slog.LogValuer calling a function to get the value.
+type lazyValue func() slog.Value
+
+func (f lazyValue) LogValue() slog.Value {
+	return f()
+}
+
+// Lazy returns an attribute whose value is computed by f when a record with it is logged,
+// e.g. to add an expensive digest of a payload to the context that is only computed for debug logs that are enabled:
+//
+//	ctxslog.AddArgs(ctx, ctxslog.Lazy("payload_digest", func() slog.Value {
+//		return slog.StringValue(digest(payload))
+//	}))
+//
+// Any slog.LogValuer is resolved the same way.
+func Lazy(key string, f func() slog.Value) slog.Attr {
+	return slog.Any(key, lazyValue(f))
+}
@@ -0,0 +1,42 @@
+package ctxslog
+
+import (
+	"context"
+	"log/slog"
+)
+
+type levelMarker struct{}
+
+var levelMarkerKey = &levelMarker{}
+
+// WithLevel returns a context with a minimal level that overrides the level of the handler,
+// e.g. to log debug messages of a single request.
+// It is honored by Extract, by the functions of this package and by Handler.
+//
+// The handler must not check the level again when handling records, like the handlers of the slog package.
+func WithLevel(ctx context.Context, level slog.Leveler) context.Context {
+	return context.WithValue(ctx, levelMarkerKey, level)
+}
+
+func levelFromContext(ctx context.Context) (slog.Leveler, bool) {
+	level, ok := ctx.Value(levelMarkerKey).(slog.Leveler)
+	return level, ok && level != nil
+}
+
+// levelHandler replaces the level of a handler.
+type levelHandler struct {
+	slog.Handler
+	level slog.Leveler
+}
+
+func (h *levelHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *levelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &levelHandler{Handler: h.Handler.WithAttrs(attrs), level: h.level}
+}
+
+func (h *levelHandler) WithGroup(name string) slog.Handler {
+	return &levelHandler{Handler: h.Handler.WithGroup(name), level: h.level}
+}
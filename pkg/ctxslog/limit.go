@@ -0,0 +1,111 @@
+package ctxslog
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// SuppressedKey is the key of the attribute with the number of messages suppressed since the previous message logged
+// with Every or EveryN.
+const SuppressedKey = "suppressed"
+
+// Limited logs messages with the logger in the context, if allowed by the rate limit it was returned for.
+type Limited struct {
+	ctx        context.Context
+	allowed    bool
+	suppressed int64
+}
+
+type rateLimit struct {
+	mu         sync.Mutex
+	next       time.Time
+	count      int64
+	suppressed int64
+}
+
+var (
+	everyLimits  sync.Map // map[string]*rateLimit
+	everyNLimits sync.Map // map[string]*rateLimit
+)
+
+func loadLimit(limits *sync.Map, key string) *rateLimit {
+	if l, ok := limits.Load(key); ok {
+		return l.(*rateLimit)
+	}
+	l, _ := limits.LoadOrStore(key, &rateLimit{})
+	return l.(*rateLimit)
+}
+
+// Every returns a Limited logging at most one message per key per interval, e.g. for warnings about items in a loop
+// processing thousands of items. Logged messages have the number of suppressed messages under SuppressedKey.
+// Keys are shared by all contexts, and should be constants, as their limits are kept for the lifetime of the program.
+//
+//	ctxslog.Every(ctx, "invalid-item", time.Minute).Warn("invalid item", "id", item.ID)
+func Every(ctx context.Context, key string, interval time.Duration) Limited {
+	l := loadLimit(&everyLimits, key)
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if now.Before(l.next) {
+		l.suppressed++
+		return Limited{}
+	}
+	suppressed := l.suppressed
+	l.suppressed = 0
+	l.next = now.Add(interval)
+	return Limited{ctx: ctx, allowed: true, suppressed: suppressed}
+}
+
+// EveryN returns a Limited logging the first and then every nth message per key, like Every.
+func EveryN(ctx context.Context, key string, n int) Limited {
+	l := loadLimit(&everyNLimits, key)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.count++
+	if n > 1 && (l.count-1)%int64(n) != 0 {
+		l.suppressed++
+		return Limited{}
+	}
+	suppressed := l.suppressed
+	l.suppressed = 0
+	return Limited{ctx: ctx, allowed: true, suppressed: suppressed}
+}
+
+func (l Limited) args(args []any) []any {
+	if l.suppressed == 0 {
+		return args
+	}
+	return append(args[:len(args):len(args)], slog.Int64(SuppressedKey, l.suppressed))
+}
+
+// Debug is equivalent to Debug, if allowed.
+func (l Limited) Debug(msg string, args ...any) {
+	if l.allowed {
+		log(l.ctx, slog.LevelDebug, msg, l.args(args))
+	}
+}
+
+// Info is equivalent to Info, if allowed.
+func (l Limited) Info(msg string, args ...any) {
+	if l.allowed {
+		log(l.ctx, slog.LevelInfo, msg, l.args(args))
+	}
+}
+
+// Warn is equivalent to Warn, if allowed.
+func (l Limited) Warn(msg string, args ...any) {
+	if l.allowed {
+		log(l.ctx, slog.LevelWarn, msg, l.args(args))
+	}
+}
+
+// Error is equivalent to Error, if allowed.
+func (l Limited) Error(msg string, args ...any) {
+	if l.allowed {
+		log(l.ctx, slog.LevelError, msg, l.args(args))
+	}
+}
@@ -0,0 +1,42 @@
+package ctxslog
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+)
+
+// RequestIDKey is the key of the request ID attribute added by SetRequestID.
+const RequestIDKey = "request_id"
+
+type requestIDMarker struct{}
+
+var requestIDMarkerKey = &requestIDMarker{}
+
+// SetRequestID returns a context with the request ID, e.g. from an X-Request-Id header, and adds it to the args of
+// the context logger under RequestIDKey, if the context has a logger.
+// If id is empty, a new ID is generated with NewRequestID.
+func SetRequestID(ctx context.Context, id string) context.Context {
+	if id == "" {
+		id = NewRequestID()
+	}
+	if l, ok := ctx.Value(ctxMarkerKey).(*ctxLogger); ok && l != nil {
+		l.attrs = append(l.attrs, slog.String(RequestIDKey, id))
+	}
+	return context.WithValue(ctx, requestIDMarkerKey, id)
+}
+
+// RequestID returns the request ID set with SetRequestID, or an empty string.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDMarkerKey).(string)
+	return id
+}
+
+// NewRequestID returns a random ID of 32 hex characters.
+func NewRequestID() string {
+	var b [16]byte
+	// Read only fails if the random source of the system is unavailable.
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
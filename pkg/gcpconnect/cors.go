@@ -0,0 +1,103 @@
+package gcpconnect
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	connectcors "connectrpc.com/cors"
+)
+
+// CORSOptions configures the CORS headers of the server, for browsers calling it with connect-web or grpc-web.
+type CORSOptions struct {
+	// Origins allowed to call the server, e.g. "https://app.example.com", or "*" for any origin.
+	AllowedOrigins []string
+	// Request headers allowed in addition to the headers of the Connect, gRPC and gRPC-Web protocols.
+	AllowedHeaders []string
+	// Response headers exposed in addition to the headers of the Connect, gRPC and gRPC-Web protocols.
+	ExposedHeaders []string
+	// How long browsers can cache the result of a preflight request. Defaults to 2 hours.
+	MaxAge time.Duration
+	// Allow requests with credentials, e.g. cookies. Requires AllowedOrigins without "*".
+	AllowCredentials bool
+}
+
+// WithCORS adds CORS headers to the responses of the server for the allowed origins, and answers preflight requests.
+// The headers of the Connect, gRPC and gRPC-Web protocols are allowed and exposed, as listed by connectrpc.com/cors.
+// NewServer fails if credentials are allowed from any origin.
+func WithCORS(opts CORSOptions) ServerOption {
+	return func(o *serverOptions) {
+		o.cors = &opts
+	}
+}
+
+// errCORSCredentialsWithAnyOrigin is returned for CORS options allowing credentials from any origin, which would let
+// any website make calls with the credentials of users.
+var errCORSCredentialsWithAnyOrigin = errors.New("CORS: credentials cannot be allowed from any origin")
+
+func corsHandler(next http.Handler, opts CORSOptions) (http.Handler, error) {
+	allowAll := false
+	origins := make(map[string]bool, len(opts.AllowedOrigins))
+	for _, origin := range opts.AllowedOrigins {
+		if origin == "*" {
+			allowAll = true
+		}
+		origins[strings.ToLower(origin)] = true
+	}
+	if allowAll && opts.AllowCredentials {
+		return nil, errCORSCredentialsWithAnyOrigin
+	}
+	maxAge := opts.MaxAge
+	if maxAge == 0 {
+		maxAge = 2 * time.Hour
+	}
+	allowedMethods := strings.Join(connectcors.AllowedMethods(), ", ")
+	allowedHeaders := strings.Join(append(connectcors.AllowedHeaders(), opts.AllowedHeaders...), ", ")
+	exposedHeaders := strings.Join(append(connectcors.ExposedHeaders(), opts.ExposedHeaders...), ", ")
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		header := w.Header()
+		header.Add("Vary", "Origin")
+		if !allowAll && !origins[strings.ToLower(origin)] {
+			if preflight {
+				// Answer without CORS headers, so that the browser rejects the request.
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if allowAll {
+			// Never reflect the origin when any origin is allowed, credentials are not allowed then.
+			header.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			header.Set("Access-Control-Allow-Origin", origin)
+		}
+		if opts.AllowCredentials {
+			header.Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if preflight {
+			header.Add("Vary", "Access-Control-Request-Method")
+			header.Add("Vary", "Access-Control-Request-Headers")
+			header.Set("Access-Control-Allow-Methods", allowedMethods)
+			header.Set("Access-Control-Allow-Headers", allowedHeaders)
+			header.Set("Access-Control-Max-Age", strconv.Itoa(int(maxAge/time.Second)))
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		header.Set("Access-Control-Expose-Headers", exposedHeaders)
+		next.ServeHTTP(w, r)
+	}), nil
+}
@@ -0,0 +1,125 @@
+package gcpconnect_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mycujoo/go-stdlib/pkg/gcpconnect"
+)
+
+func TestWithCORS(t *testing.T) {
+	newHandler := func(t *testing.T, opts gcpconnect.CORSOptions) http.Handler {
+		t.Helper()
+		srv, err := gcpconnect.NewServer(context.Background(), "127.0.0.1:0", "/test.v1.Service/",
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}),
+			gcpconnect.WithCORS(opts),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return srv.Handler
+	}
+	preflight := func(origin string) *http.Request {
+		r := httptest.NewRequest(http.MethodOptions, "/test.v1.Service/Method", nil)
+		r.Header.Set("Origin", origin)
+		r.Header.Set("Access-Control-Request-Method", http.MethodPost)
+		r.Header.Set("Access-Control-Request-Headers", "content-type,connect-protocol-version")
+		return r
+	}
+	call := func(origin string) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/test.v1.Service/Method", nil)
+		r.Header.Set("Origin", origin)
+		return r
+	}
+
+	t.Run("preflight", func(t *testing.T) {
+		h := newHandler(t, gcpconnect.CORSOptions{
+			AllowedOrigins: []string{"https://app.example.com"},
+			AllowedHeaders: []string{"X-Tenant-Id"},
+		})
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, preflight("https://app.example.com"))
+
+		if w.Code != http.StatusNoContent {
+			t.Errorf("unexpected status: %d", w.Code)
+		}
+		header := w.Header()
+		if v := header.Get("Access-Control-Allow-Origin"); v != "https://app.example.com" {
+			t.Errorf("unexpected allowed origin: %q", v)
+		}
+		if v := header.Get("Access-Control-Allow-Methods"); v != "GET, POST" {
+			t.Errorf("unexpected allowed methods: %q", v)
+		}
+		for _, name := range []string{"Content-Type", "Connect-Protocol-Version", "Connect-Timeout-Ms", "X-Tenant-Id"} {
+			if v := header.Get("Access-Control-Allow-Headers"); !strings.Contains(v, name) {
+				t.Errorf("header %s not allowed: %q", name, v)
+			}
+		}
+		if v := header.Get("Access-Control-Max-Age"); v != "7200" {
+			t.Errorf("unexpected max age: %q", v)
+		}
+		if v := header.Get("Access-Control-Allow-Credentials"); v != "" {
+			t.Errorf("unexpected allow credentials: %q", v)
+		}
+
+		w = httptest.NewRecorder()
+		h.ServeHTTP(w, call("https://app.example.com"))
+		if w.Code != http.StatusOK {
+			t.Errorf("unexpected status: %d", w.Code)
+		}
+		if v := w.Header().Get("Access-Control-Expose-Headers"); !strings.Contains(v, "Grpc-Status-Details-Bin") {
+			t.Errorf("unexpected exposed headers: %q", v)
+		}
+	})
+
+	t.Run("disallowed origin", func(t *testing.T) {
+		h := newHandler(t, gcpconnect.CORSOptions{AllowedOrigins: []string{"https://app.example.com"}})
+		for _, r := range []*http.Request{preflight("https://evil.example.com"), call("https://evil.example.com")} {
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			for name := range w.Header() {
+				if strings.HasPrefix(name, "Access-Control-") {
+					t.Errorf("unexpected header for %s: %s", r.Method, name)
+				}
+			}
+		}
+	})
+
+	t.Run("any origin", func(t *testing.T) {
+		h := newHandler(t, gcpconnect.CORSOptions{AllowedOrigins: []string{"*"}})
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, preflight("https://app.example.com"))
+		if v := w.Header().Get("Access-Control-Allow-Origin"); v != "*" {
+			t.Errorf("unexpected allowed origin: %q", v)
+		}
+	})
+
+	t.Run("credentials", func(t *testing.T) {
+		h := newHandler(t, gcpconnect.CORSOptions{
+			AllowedOrigins:   []string{"https://app.example.com"},
+			AllowCredentials: true,
+		})
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, preflight("https://app.example.com"))
+		if v := w.Header().Get("Access-Control-Allow-Origin"); v != "https://app.example.com" {
+			t.Errorf("unexpected allowed origin: %q", v)
+		}
+		if v := w.Header().Get("Access-Control-Allow-Credentials"); v != "true" {
+			t.Errorf("unexpected allow credentials: %q", v)
+		}
+	})
+
+	t.Run("credentials from any origin", func(t *testing.T) {
+		_, err := gcpconnect.NewServer(context.Background(), "127.0.0.1:0", "/", http.NotFoundHandler(),
+			gcpconnect.WithCORS(gcpconnect.CORSOptions{AllowedOrigins: []string{"*"}, AllowCredentials: true}),
+		)
+		if err == nil {
+			t.Fatal("expected error")
+		}
+	})
+}
@@ -4,8 +4,11 @@ go 1.21
 
 require (
 	connectrpc.com/connect v1.11.1
+	connectrpc.com/cors v0.1.0
+	connectrpc.com/grpchealth v1.3.0
 	connectrpc.com/otelconnect v0.6.0
 	github.com/mycujoo/go-stdlib/pkg/connectlog v1.0.0
+	go.opentelemetry.io/otel/metric v1.19.0
 	golang.org/x/net v0.17.0
 	google.golang.org/protobuf v1.31.0
 )
@@ -20,7 +23,6 @@ require (
 	github.com/go-logr/stdr v1.2.2 // indirect
 	github.com/mycujoo/go-stdlib/pkg/ctxslog v1.0.0 // indirect
 	go.opentelemetry.io/otel v1.19.0 // indirect
-	go.opentelemetry.io/otel/metric v1.19.0 // indirect
 	go.opentelemetry.io/otel/trace v1.19.0 // indirect
 	golang.org/x/text v0.13.0 // indirect
 )
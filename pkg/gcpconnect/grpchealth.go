@@ -0,0 +1,47 @@
+package gcpconnect
+
+import (
+	"context"
+	"fmt"
+
+	"connectrpc.com/connect"
+	"connectrpc.com/grpchealth"
+)
+
+// WithGRPCHealth mounts the standard gRPC health service, grpc.health.v1.Health, reporting the same status as
+// /healthz, for gRPC load balancers and gRPC probes of Kubernetes.
+// The status of the server is reported for the empty service name and for the given service names,
+// e.g. "some.package.v1.Service", other names are not found.
+func WithGRPCHealth(services ...string) ServerOption {
+	return func(o *serverOptions) {
+		o.grpcHealth = true
+		o.healthServices = append(o.healthServices, services...)
+	}
+}
+
+// healthChecker is a grpchealth.Checker reporting the health of a server.
+type healthChecker struct {
+	health   *health
+	services map[string]bool
+}
+
+func newHealthChecker(h *health, services []string) *healthChecker {
+	c := &healthChecker{
+		health:   h,
+		services: map[string]bool{"": true},
+	}
+	for _, s := range services {
+		c.services[s] = true
+	}
+	return c
+}
+
+func (c *healthChecker) Check(_ context.Context, req *grpchealth.CheckRequest) (*grpchealth.CheckResponse, error) {
+	if !c.services[req.Service] {
+		return nil, connect.NewError(connect.CodeNotFound, fmt.Errorf("unknown service %q", req.Service))
+	}
+	if !c.health.serving() {
+		return &grpchealth.CheckResponse{Status: grpchealth.StatusNotServing}, nil
+	}
+	return &grpchealth.CheckResponse{Status: grpchealth.StatusServing}, nil
+}
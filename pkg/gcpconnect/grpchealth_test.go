@@ -0,0 +1,78 @@
+package gcpconnect_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mycujoo/go-stdlib/pkg/gcpconnect"
+)
+
+// checkHealth calls the Check method of the gRPC health service with the Connect protocol and returns the response.
+func checkHealth(t *testing.T, url string, service string) (int, string) {
+	t.Helper()
+	resp, err := http.Post(
+		url+"/grpc.health.v1.Health/Check",
+		"application/json",
+		strings.NewReader(`{"service":"`+service+`"}`),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, string(body)
+}
+
+func TestWithGRPCHealth(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	srv, err := gcpconnect.NewServer(context.Background(), "127.0.0.1:0", "/test.v1.Service/", http.NotFoundHandler(),
+		gcpconnect.WithGRPCHealth("test.v1.Service"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Serve the handler of the server, since the address of the listener of Run is not known.
+	ts := httptest.NewServer(srv.Handler)
+	defer ts.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- gcpconnect.Run(ctx, srv, gcpconnect.WithDrainPeriod(time.Second))
+	}()
+
+	for _, service := range []string{"", "test.v1.Service"} {
+		if code, body := checkHealth(t, ts.URL, service); code != http.StatusOK || body != `{"status":"SERVING_STATUS_SERVING"}` {
+			t.Errorf("unexpected response for %q: %d %s", service, code, body)
+		}
+	}
+	if code, body := checkHealth(t, ts.URL, "unknown.v1.Service"); code != http.StatusNotFound {
+		t.Errorf("unexpected response for unknown service: %d %s", code, body)
+	}
+
+	cancel()
+	deadline := time.Now().Add(time.Second)
+	for {
+		_, body := checkHealth(t, ts.URL, "")
+		if body == `{"status":"SERVING_STATUS_NOT_SERVING"}` {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("unexpected response while shutting down: %s", body)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
@@ -17,23 +17,35 @@ func GetHandlerOptions(logger *slog.Logger, opts ...Option) []connect.HandlerOpt
 			// Fill unpopulated fields with their default values
 			EmitUnpopulated: true,
 		},
+		readMaxBytes: DefaultReadMaxBytes,
 	}
 	for _, opt := range opts {
 		opt(&o)
 	}
 
-	return []connect.HandlerOption{
+	otelOptions := []otelconnect.Option{otelconnect.WithoutServerPeerAttributes()}
+	switch {
+	case !o.metrics:
+		// Disable metrics by default since they are producing a lot of data
+		otelOptions = append(otelOptions, otelconnect.WithoutMetrics())
+	case o.meterProvider != nil:
+		otelOptions = append(otelOptions, otelconnect.WithMeterProvider(o.meterProvider))
+	}
+
+	handlerOptions := []connect.HandlerOption{
 		connect.WithCodec(NewJSONCodec(o.marshalOptions)),
-		connect.WithInterceptors(
-			// Disable metrics since they are producing a lot of data
-			otelconnect.NewInterceptor(
-				otelconnect.WithoutMetrics(),
-				otelconnect.WithoutServerPeerAttributes(),
-			),
-		),
+		connect.WithReadMaxBytes(o.readMaxBytes),
+		connect.WithSendMaxBytes(o.sendMaxBytes),
+		connect.WithInterceptors(otelconnect.NewInterceptor(otelOptions...)),
+	}
+	if o.handlerTimeout > 0 {
+		// Outside the logging interceptor, so that the deadline budget of the timeout is logged.
+		handlerOptions = append(handlerOptions, connect.WithInterceptors(newTimeoutInterceptor(o.handlerTimeout)))
+	}
+	return append(handlerOptions,
 		connect.WithRecover(connectlog.NewLoggingRecoverHandler(logger)),
 		// We log after recover so panic logs are not duplicated.
 		// Internally, `connect.WithRecover` is adding interceptor.
 		connect.WithInterceptors(connectlog.NewLoggingInterceptor(logger, o.logOptions...)),
-	}
+	)
 }
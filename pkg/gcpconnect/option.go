@@ -1,7 +1,10 @@
 package gcpconnect
 
 import (
+	"time"
+
 	"github.com/mycujoo/go-stdlib/pkg/connectlog"
+	"go.opentelemetry.io/otel/metric"
 	"google.golang.org/protobuf/encoding/protojson"
 )
 
@@ -10,6 +13,11 @@ type Option func(o *options)
 type options struct {
 	logOptions     []connectlog.Option
 	marshalOptions protojson.MarshalOptions
+	metrics        bool
+	meterProvider  metric.MeterProvider
+	handlerTimeout time.Duration
+	readMaxBytes   int
+	sendMaxBytes   int
 }
 
 // WithLogOptions sets the options for the logging interceptor.
@@ -27,3 +35,36 @@ func WithJSONMarshalOptions(opts protojson.MarshalOptions) Option {
 		o.marshalOptions = opts
 	}
 }
+
+// WithMetrics enables the metrics of the otelconnect interceptor, which are disabled by default, recorded with the
+// given provider, or the global one if nil. The durations of calls are recorded in milliseconds; use DurationBuckets
+// in a view of the provider for histogram buckets fitting the latencies of RPCs.
+func WithMetrics(provider metric.MeterProvider) Option {
+	return func(o *options) {
+		o.metrics = true
+		o.meterProvider = provider
+	}
+}
+
+// DurationBuckets are histogram bucket boundaries, in milliseconds, for the rpc.server.duration metric.
+// The default buckets of the OpenTelemetry SDK go up to 10 seconds but are too coarse below 5 milliseconds.
+var DurationBuckets = []float64{1, 2.5, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000, 30000}
+
+// DefaultReadMaxBytes is the default maximum size of request messages, like the default of gRPC servers.
+const DefaultReadMaxBytes = 4 * 1024 * 1024 // 4MiB
+
+// WithReadMaxBytes sets the maximum size of request messages, DefaultReadMaxBytes by default.
+// Larger requests fail with code resource_exhausted before they are read into memory. 0 disables the limit.
+func WithReadMaxBytes(n int) Option {
+	return func(o *options) {
+		o.readMaxBytes = n
+	}
+}
+
+// WithSendMaxBytes sets the maximum size of response messages. Larger responses fail with code resource_exhausted.
+// Not limited by default.
+func WithSendMaxBytes(n int) Option {
+	return func(o *options) {
+		o.sendMaxBytes = n
+	}
+}
@@ -0,0 +1,112 @@
+package gcpconnect
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+type RunOption func(o *runOptions)
+
+type runOptions struct {
+	drainPeriod     time.Duration
+	shutdownTimeout time.Duration
+	closers         []func(ctx context.Context) error
+}
+
+// WithDrainPeriod sets how long Run waits after reporting the server as not serving before shutting it down,
+// so that load balancers stop sending requests to it. Defaults to 0.
+func WithDrainPeriod(d time.Duration) RunOption {
+	return func(o *runOptions) {
+		o.drainPeriod = d
+	}
+}
+
+// WithShutdownTimeout sets how long Run waits for active requests to finish when shutting down the server,
+// and for closers. Defaults to 10 seconds.
+func WithShutdownTimeout(d time.Duration) RunOption {
+	return func(o *runOptions) {
+		o.shutdownTimeout = d
+	}
+}
+
+// WithCloser registers a function called by Run after the server has shut down, e.g. to flush traces and logs:
+//
+//	WithCloser(tracerProvider.Shutdown),
+//	WithCloser(func(context.Context) error { return logHandler.Close() }),
+//
+// Closers are called in the order they were registered.
+func WithCloser(closer func(ctx context.Context) error) RunOption {
+	return func(o *runOptions) {
+		o.closers = append(o.closers, closer)
+	}
+}
+
+// Run serves srv until ctx is done or the process receives SIGTERM or SIGINT, and then shuts it down gracefully:
+// it reports the server as not serving on /healthz, waits for the drain period, shuts the server down, waiting for
+// active requests up to the shutdown timeout, and calls the registered closers. A second signal skips the rest of the
+// drain period.
+// It returns the errors of serving, shutting down and closers, or nil.
+func Run(ctx context.Context, srv *http.Server, opts ...RunOption) error {
+	o := runOptions{
+		shutdownTimeout: 10 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	defer healths.Delete(srv)
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.ListenAndServe()
+	}()
+
+	var errs []error
+	select {
+	case err := <-serveErr:
+		// The server failed to start or was closed without Run.
+		if !errors.Is(err, http.ErrServerClosed) {
+			errs = append(errs, err)
+		}
+	case <-ctx.Done():
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		defer signal.Stop(signals)
+
+		if h, ok := healths.Load(srv); ok {
+			h.(*health).shutdown()
+		}
+		drain := time.NewTimer(o.drainPeriod)
+		defer drain.Stop()
+		select {
+		case <-drain.C:
+		case <-signals:
+		}
+
+		shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), o.shutdownTimeout)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			errs = append(errs, err)
+		}
+		if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+			errs = append(errs, err)
+		}
+	}
+
+	closeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), o.shutdownTimeout)
+	defer cancel()
+	for _, closer := range o.closers {
+		if err := closer(closeCtx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
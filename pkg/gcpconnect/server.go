@@ -3,26 +3,104 @@ package gcpconnect
 import (
 	"context"
 	"net/http"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"connectrpc.com/grpchealth"
 	"golang.org/x/net/http2"
 	"golang.org/x/net/http2/h2c"
 )
 
+type ServerOption func(o *serverOptions)
+
+type serverOptions struct {
+	cors           *CORSOptions
+	grpcHealth     bool
+	healthServices []string
+	muxHooks       []func(mux *http.ServeMux)
+}
+
 // NewServer creates a new HTTP server.
 // It contains a healthz endpoint and a handler for the given path.
-// Healthz will return 200 OK if the given context is not done.
-func NewServer(ctx context.Context, addr string, path string, handler http.Handler) (*http.Server, error) {
+// Healthz will return 200 OK if the given context is not done and the server is not shutting down with Run.
+func NewServer(ctx context.Context, addr string, path string, handler http.Handler, opts ...ServerOption) (*http.Server, error) {
+	return NewServerMux().Handle(path, handler).NewServer(ctx, addr, opts...)
+}
+
+// WithMux calls hook with the mux of the server after the handlers of the server are registered, so that it can
+// register other routes, e.g. webhooks or status pages, which are served with the middlewares and h2c support of the
+// server. Registering a path that is already registered, e.g. /healthz, panics.
+func WithMux(hook func(mux *http.ServeMux)) ServerOption {
+	return func(o *serverOptions) {
+		o.muxHooks = append(o.muxHooks, hook)
+	}
+}
+
+// ServerMux holds the handlers of a server hosting several services or other HTTP routes, e.g.
+//
+//	srv, err := gcpconnect.NewServerMux().
+//		Handle(elizav1connect.NewElizaServiceHandler(eliza, handlerOpts...)).
+//		Handle(greetv1connect.NewGreetServiceHandler(greet, handlerOpts...)).
+//		HandleFunc("/webhook", webhook).
+//		NewServer(ctx, addr)
+type ServerMux struct {
+	routes []route
+}
+
+type route struct {
+	path    string
+	handler http.Handler
+}
+
+// NewServerMux returns an empty ServerMux.
+func NewServerMux() *ServerMux {
+	return &ServerMux{}
+}
+
+// Handle registers the handler for the given path, like http.ServeMux.Handle.
+func (m *ServerMux) Handle(path string, handler http.Handler) *ServerMux {
+	m.routes = append(m.routes, route{path: path, handler: handler})
+	return m
+}
+
+// HandleFunc registers the handler function for the given path, like http.ServeMux.HandleFunc.
+func (m *ServerMux) HandleFunc(path string, handler func(http.ResponseWriter, *http.Request)) *ServerMux {
+	return m.Handle(path, http.HandlerFunc(handler))
+}
+
+// NewServer creates a new HTTP server with the registered handlers, like the NewServer function.
+func (m *ServerMux) NewServer(ctx context.Context, addr string, opts ...ServerOption) (*http.Server, error) {
+	o := serverOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	mux := http.NewServeMux()
 
-	mux.Handle(path, handler)
-	mux.HandleFunc("/healthz", healthZHandleFunc(ctx))
+	h := newHealth(ctx)
+
+	for _, r := range m.routes {
+		mux.Handle(r.path, r.handler)
+	}
+	mux.HandleFunc("/healthz", healthZHandleFunc(h))
+	if o.grpcHealth {
+		mux.Handle(grpchealth.NewHandler(newHealthChecker(h, o.healthServices)))
+	}
+	for _, hook := range o.muxHooks {
+		hook(mux)
+	}
+
+	handler, err := o.handler(mux)
+	if err != nil {
+		return nil, err
+	}
 
 	srv := &http.Server{
 		Addr: addr,
 		// Use h2c, so we can serve HTTP/2 without TLS.
 		Handler: h2c.NewHandler(
-			mux,
+			handler,
 			&http2.Server{},
 		),
 		ReadHeaderTimeout: time.Second,
@@ -31,6 +109,11 @@ func NewServer(ctx context.Context, addr string, path string, handler http.Handl
 		MaxHeaderBytes:    16 * 1024, // 16KiB
 	}
 
+	healths.Store(srv, h)
+	srv.RegisterOnShutdown(func() {
+		healths.Delete(srv)
+	})
+
 	return srv, nil
 }
 
@@ -39,10 +122,46 @@ var (
 	statusOK    = []byte(`{"status":"SERVING"}`)
 )
 
-func healthZHandleFunc(ctx context.Context) http.HandlerFunc {
+// handler returns the handler of the server, wrapped by the middlewares of the options.
+func (o *serverOptions) handler(mux *http.ServeMux) (http.Handler, error) {
+	var h http.Handler = mux
+	if o.cors != nil {
+		var err error
+		if h, err = corsHandler(h, *o.cors); err != nil {
+			return nil, err
+		}
+	}
+	return h, nil
+}
+
+// health is the serving status of a server created by NewServer.
+type health struct {
+	ctx          context.Context
+	shuttingDown atomic.Bool
+}
+
+func newHealth(ctx context.Context) *health {
+	return &health{ctx: ctx}
+}
+
+// serving returns false when the context of the server is done or the server is shutting down.
+func (h *health) serving() bool {
+	return h.ctx.Err() == nil && !h.shuttingDown.Load()
+}
+
+// shutdown reports the server as not serving.
+func (h *health) shutdown() {
+	h.shuttingDown.Store(true)
+}
+
+// healths maps servers created by NewServer to their health, so that Run can report them as not serving.
+// Servers are deleted when they are shut down, or when Run returns.
+var healths sync.Map
+
+func healthZHandleFunc(h *health) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Add("Content-Type", "application/json")
-		if ctx.Err() != nil {
+		if !h.serving() {
 			w.WriteHeader(http.StatusInternalServerError)
 			_, _ = w.Write(statusError)
 			return
@@ -0,0 +1,40 @@
+package gcpconnect
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"connectrpc.com/connect"
+)
+
+// WithHandlerTimeout limits the duration of unary handlers: their context is canceled after d, or at the deadline of
+// the client if it is earlier, and the error of handlers returning after d is returned with code deadline_exceeded.
+// Streaming handlers are not limited, since streams can be long-lived.
+func WithHandlerTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.handlerTimeout = d
+	}
+}
+
+// newTimeoutInterceptor returns an interceptor limiting the duration of unary handlers to timeout.
+func newTimeoutInterceptor(timeout time.Duration) connect.UnaryInterceptorFunc {
+	return func(next connect.UnaryFunc) connect.UnaryFunc {
+		return func(ctx context.Context, request connect.AnyRequest) (connect.AnyResponse, error) {
+			if request.Spec().IsClient {
+				return next(ctx, request)
+			}
+
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			resp, err := next(ctx, request)
+			if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) &&
+				connect.CodeOf(err) != connect.CodeDeadlineExceeded {
+				// The handler failed because of the timeout, e.g. with an internal error of a canceled call.
+				return resp, connect.NewError(connect.CodeDeadlineExceeded, err)
+			}
+			return resp, err
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package gcplog
+
+import (
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// AsyncWriterOptions configures an AsyncWriter.
+type AsyncWriterOptions struct {
+	// Maximum number of lines waiting to be written. Defaults to 1024.
+	QueueSize int
+	// Drop lines when the queue is full instead of blocking until there is room.
+	// The number of dropped lines is reported by Dropped.
+	DropWhenFull bool
+}
+
+// AsyncWriter is an io.Writer that writes to an underlying writer from a background goroutine through a bounded queue,
+// so that logging does not wait for slow writes. It is meant to be passed to NewHandler:
+//
+//	w := gcplog.NewAsyncWriter(os.Stderr, nil)
+//	defer w.Close()
+//	logger := slog.New(gcplog.NewHandler(w, opts))
+//
+// Lines that are still queued are lost when the program exits without calling Flush or Close.
+type AsyncWriter struct {
+	w            io.Writer
+	dropWhenFull bool
+	queue        chan asyncItem
+	done         chan struct{}
+	mu           sync.RWMutex
+	closed       bool
+	err          atomic.Pointer[error]
+	dropped      atomic.Int64
+}
+
+type asyncItem struct {
+	data    []byte
+	flushed chan struct{}
+}
+
+// NewAsyncWriter returns an AsyncWriter writing to w and starts its background goroutine.
+func NewAsyncWriter(w io.Writer, opts *AsyncWriterOptions) *AsyncWriter {
+	if opts == nil {
+		opts = &AsyncWriterOptions{}
+	}
+	queueSize := opts.QueueSize
+	if queueSize <= 0 {
+		queueSize = 1024
+	}
+	a := &AsyncWriter{
+		w:            w,
+		dropWhenFull: opts.DropWhenFull,
+		queue:        make(chan asyncItem, queueSize),
+		done:         make(chan struct{}),
+	}
+	go a.run()
+	return a
+}
+
+func (a *AsyncWriter) run() {
+	defer close(a.done)
+	for item := range a.queue {
+		if item.flushed != nil {
+			close(item.flushed)
+			continue
+		}
+		if _, err := a.w.Write(item.data); err != nil {
+			a.err.Store(&err)
+		}
+	}
+}
+
+// Write queues a copy of p to be written. It never returns errors of the underlying writer, those are returned by
+// Flush and Close instead.
+func (a *AsyncWriter) Write(p []byte) (int, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.closed {
+		return 0, os.ErrClosed
+	}
+
+	item := asyncItem{data: append([]byte(nil), p...)}
+	if a.dropWhenFull {
+		select {
+		case a.queue <- item:
+		default:
+			a.dropped.Add(1)
+		}
+		return len(p), nil
+	}
+	a.queue <- item
+	return len(p), nil
+}
+
+// Flush waits until all lines queued before the call are written,
+// and returns the last error of the underlying writer since the previous call to Flush.
+func (a *AsyncWriter) Flush() error {
+	a.mu.RLock()
+	if a.closed {
+		a.mu.RUnlock()
+		return a.takeErr()
+	}
+	flushed := make(chan struct{})
+	a.queue <- asyncItem{flushed: flushed}
+	a.mu.RUnlock()
+
+	<-flushed
+	return a.takeErr()
+}
+
+// Close writes all queued lines and stops the background goroutine. Writes after Close fail with os.ErrClosed.
+// It returns the last error of the underlying writer since the previous call to Flush.
+func (a *AsyncWriter) Close() error {
+	a.mu.Lock()
+	if !a.closed {
+		a.closed = true
+		close(a.queue)
+	}
+	a.mu.Unlock()
+
+	<-a.done
+	return a.takeErr()
+}
+
+// Dropped returns the number of lines dropped because the queue was full.
+func (a *AsyncWriter) Dropped() int64 {
+	return a.dropped.Load()
+}
+
+func (a *AsyncWriter) takeErr() error {
+	if err := a.err.Swap(nil); err != nil {
+		return *err
+	}
+	return nil
+}
+
+var _ io.WriteCloser = (*AsyncWriter)(nil)
@@ -0,0 +1,87 @@
+package gcplog_test
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mycujoo/go-stdlib/pkg/gcplog"
+	"github.com/mycujoo/go-stdlib/pkg/gcplog/internal/require"
+)
+
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+	err error
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.err != nil {
+		return 0, b.err
+	}
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestAsyncWriter(t *testing.T) {
+	t.Run("flush", func(t *testing.T) {
+		var buf lockedBuffer
+		w := gcplog.NewAsyncWriter(&buf, &gcplog.AsyncWriterOptions{QueueSize: 2})
+		defer w.Close()
+		logger := slog.New(gcplog.NewHandler(w, nil))
+
+		for i := 0; i < 10; i++ {
+			logger.Info("hello", "i", i)
+		}
+		require.NoError(t, w.Flush())
+		require.Equal(t, 10, strings.Count(buf.String(), "\n"))
+		require.Equal(t, int64(0), w.Dropped())
+	})
+
+	t.Run("close", func(t *testing.T) {
+		var buf lockedBuffer
+		w := gcplog.NewAsyncWriter(&buf, nil)
+		_, err := w.Write([]byte("line\n"))
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		require.Equal(t, "line\n", buf.String())
+
+		_, err = w.Write([]byte("late\n"))
+		require.Equal(t, true, errors.Is(err, os.ErrClosed), "write after close")
+		require.NoError(t, w.Close())
+	})
+
+	t.Run("write error", func(t *testing.T) {
+		writeErr := errors.New("disk full")
+		buf := lockedBuffer{err: writeErr}
+		w := gcplog.NewAsyncWriter(&buf, nil)
+		defer w.Close()
+
+		_, err := w.Write([]byte("line\n"))
+		require.NoError(t, err)
+		require.Equal(t, writeErr, w.Flush())
+		require.NoError(t, w.Flush())
+	})
+
+	t.Run("copies lines", func(t *testing.T) {
+		var buf lockedBuffer
+		w := gcplog.NewAsyncWriter(&buf, nil)
+		p := []byte("first\n")
+		_, err := w.Write(p)
+		require.NoError(t, err)
+		copy(p, "xxxxx\n")
+		require.NoError(t, w.Close())
+		require.Equal(t, "first\n", buf.String())
+	})
+}
@@ -0,0 +1,195 @@
+// Package cloudlogging provides a gcplog.Handler that writes entries with the Cloud Logging API instead of stdout,
+// for workloads running outside GKE and Cloud Run, e.g. on-premises or in other clouds.
+//
+// It is a separate module, so that gcplog does not depend on the gRPC client of Cloud Logging.
+package cloudlogging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"cloud.google.com/go/logging"
+	"cloud.google.com/go/logging/apiv2/loggingpb"
+	"github.com/mycujoo/go-stdlib/pkg/gcplog"
+	"google.golang.org/api/option"
+)
+
+// Fields of the GCP structured logging format that are fields of entries of the Cloud Logging API.
+const (
+	fieldTimestamp      = "time"
+	fieldTimestampObj   = "timestamp"
+	fieldSeverity       = "severity"
+	fieldSourceLocation = "logging.googleapis.com/sourceLocation"
+	fieldLabels         = "logging.googleapis.com/labels"
+	fieldTraceID        = "logging.googleapis.com/trace"
+	fieldTraceSpanID    = "logging.googleapis.com/spanId"
+	fieldTraceSampled   = "logging.googleapis.com/trace_sampled"
+	fieldInsertID       = "logging.googleapis.com/insertId"
+)
+
+// entryLogger is implemented by *logging.Logger.
+type entryLogger interface {
+	Log(e logging.Entry)
+	Flush() error
+}
+
+// NewHandler returns a gcplog.Handler that writes entries with logger, which batches them and detects the monitored
+// resource of the process. Entries have the same fields as with gcplog.NewHandler: the severity, time, trace, source
+// location and labels are set as fields of the entries, and the other fields are in their JSON payload.
+// The schema of opts is ignored and its ErrorWriter is not used.
+//
+// Flush of the handler flushes logger.
+func NewHandler(logger *logging.Logger, opts *gcplog.HandlerOptions) *gcplog.Handler {
+	return newHandler(logger, opts)
+}
+
+// New creates a client of the Cloud Logging API for the given project and returns a handler writing entries to the log
+// with the given ID, e.g. "app". The GCPProjectID of opts defaults to projectID, so that entries are correlated with
+// traces. Close of the handler flushes the entries and closes the client.
+func New(ctx context.Context, projectID, logID string, opts *gcplog.HandlerOptions, clientOpts ...option.ClientOption) (*gcplog.Handler, error) {
+	client, err := logging.NewClient(ctx, projectID, clientOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("cloudlogging: %w", err)
+	}
+	o := gcplog.HandlerOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.GCPProjectID == "" {
+		o.GCPProjectID = projectID
+	}
+	return newHandler(&clientLogger{Logger: client.Logger(logID), client: client}, &o), nil
+}
+
+func newHandler(logger entryLogger, opts *gcplog.HandlerOptions) *gcplog.Handler {
+	o := gcplog.HandlerOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	o.Schema = gcplog.SchemaGCP
+	o.ErrorWriter = nil
+	return gcplog.NewHandler(&writer{logger: logger}, &o)
+}
+
+// clientLogger is a logger that owns its client.
+type clientLogger struct {
+	*logging.Logger
+	client *logging.Client
+}
+
+// writer writes the lines of gcplog.Handler as entries of logger.
+type writer struct {
+	logger entryLogger
+}
+
+// Write converts the line p to an entry and logs it. It is called once per line by gcplog.Handler.
+func (w *writer) Write(p []byte) (int, error) {
+	e, err := entryFromJSON(p)
+	if err != nil {
+		return 0, err
+	}
+	w.logger.Log(e)
+	return len(p), nil
+}
+
+// Flush flushes the entries buffered by the logger.
+func (w *writer) Flush() error {
+	return w.logger.Flush()
+}
+
+// Close flushes the entries and closes the client, if the logger owns it.
+func (w *writer) Close() error {
+	if l, ok := w.logger.(*clientLogger); ok {
+		return l.client.Close()
+	}
+	return w.logger.Flush()
+}
+
+// entryFromJSON converts a line of the GCP structured logging format to an entry.
+func entryFromJSON(line []byte) (logging.Entry, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(line, &fields); err != nil {
+		return logging.Entry{}, fmt.Errorf("cloudlogging: invalid line: %w", err)
+	}
+
+	var e logging.Entry
+	var err error
+	if v, ok := fields[fieldTimestamp]; ok {
+		err = json.Unmarshal(v, &e.Timestamp)
+		delete(fields, fieldTimestamp)
+	} else if v, ok := fields[fieldTimestampObj]; ok {
+		var ts struct {
+			Seconds int64 `json:"seconds"`
+			Nanos   int64 `json:"nanos"`
+		}
+		if err = json.Unmarshal(v, &ts); err == nil {
+			e.Timestamp = time.Unix(ts.Seconds, ts.Nanos)
+			delete(fields, fieldTimestampObj)
+		}
+	}
+	if err != nil {
+		return logging.Entry{}, fmt.Errorf("cloudlogging: invalid time: %w", err)
+	}
+
+	var severity string
+	if err := unmarshalField(fields, fieldSeverity, &severity); err != nil {
+		return logging.Entry{}, err
+	}
+	e.Severity = logging.ParseSeverity(severity)
+
+	var source *struct {
+		File     string          `json:"file"`
+		Line     json.RawMessage `json:"line"`
+		Function string          `json:"function"`
+	}
+	if err := unmarshalField(fields, fieldSourceLocation, &source); err != nil {
+		return logging.Entry{}, err
+	}
+	if source != nil {
+		// The line is a number, or a string in the format of Cloud Logging.
+		line, _ := strconv.ParseInt(string(trimQuotes(source.Line)), 10, 64)
+		e.SourceLocation = &loggingpb.LogEntrySourceLocation{
+			File:     source.File,
+			Line:     line,
+			Function: source.Function,
+		}
+	}
+
+	for key, dst := range map[string]any{
+		fieldLabels:       &e.Labels,
+		fieldTraceID:      &e.Trace,
+		fieldTraceSpanID:  &e.SpanID,
+		fieldTraceSampled: &e.TraceSampled,
+		fieldInsertID:     &e.InsertID,
+	} {
+		if err := unmarshalField(fields, key, dst); err != nil {
+			return logging.Entry{}, err
+		}
+	}
+
+	e.Payload = fields
+	return e, nil
+}
+
+// unmarshalField unmarshals the field with the given key to dst, if present, and removes it from fields.
+func unmarshalField(fields map[string]json.RawMessage, key string, dst any) error {
+	v, ok := fields[key]
+	if !ok {
+		return nil
+	}
+	delete(fields, key)
+	if err := json.Unmarshal(v, dst); err != nil {
+		return fmt.Errorf("cloudlogging: invalid %s: %w", key, err)
+	}
+	return nil
+}
+
+func trimQuotes(b []byte) []byte {
+	if len(b) >= 2 && b[0] == '"' && b[len(b)-1] == '"' {
+		return b[1 : len(b)-1]
+	}
+	return b
+}
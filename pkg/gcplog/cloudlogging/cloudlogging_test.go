@@ -0,0 +1,120 @@
+package cloudlogging
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/logging"
+	"github.com/mycujoo/go-stdlib/pkg/gcplog"
+)
+
+type fakeLogger struct {
+	mu      sync.Mutex
+	entries []logging.Entry
+	flushes int
+}
+
+func (l *fakeLogger) Log(e logging.Entry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, e)
+}
+
+func (l *fakeLogger) Flush() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.flushes++
+	return nil
+}
+
+func TestHandler(t *testing.T) {
+	fake := &fakeLogger{}
+	h := newHandler(fake, &gcplog.HandlerOptions{
+		AddSource:    true,
+		GCPProjectID: "my-project",
+		Labels:       map[string]string{"env": "test"},
+		Level:        slog.LevelDebug,
+		// Ignored, all entries go through the API.
+		ErrorWriter: errWriter{},
+		Schema:      gcplog.SchemaOTLP,
+	})
+	logger := slog.New(h)
+
+	ctx := gcplog.ContextWithTraceHeader(context.Background(), "105445aa7843bc8bf206b12000100000/1;o=1")
+	ctx = gcplog.WithLabels(ctx, map[string]string{"tenant": "a"})
+	before := time.Now()
+	logger.ErrorContext(ctx, "failed", "user", slog.GroupValue(slog.String("id", "42")), "count", 3)
+
+	if len(fake.entries) != 1 {
+		t.Fatalf("unexpected entries: %v", fake.entries)
+	}
+	e := fake.entries[0]
+	if e.Severity != logging.Error {
+		t.Errorf("unexpected severity: %v", e.Severity)
+	}
+	if e.Timestamp.Before(before.Add(-time.Second)) || e.Timestamp.After(time.Now()) {
+		t.Errorf("unexpected timestamp: %v", e.Timestamp)
+	}
+	if e.Trace != "projects/my-project/traces/105445aa7843bc8bf206b12000100000" || e.SpanID == "" || !e.TraceSampled {
+		t.Errorf("unexpected trace: %q %q %v", e.Trace, e.SpanID, e.TraceSampled)
+	}
+	if expected := map[string]string{"env": "test", "tenant": "a"}; !reflect.DeepEqual(e.Labels, expected) {
+		t.Errorf("unexpected labels: %v", e.Labels)
+	}
+	if e.SourceLocation == nil || !strings.HasSuffix(e.SourceLocation.File, "cloudlogging_test.go") ||
+		e.SourceLocation.Line == 0 || !strings.HasSuffix(e.SourceLocation.Function, "TestHandler") {
+		t.Errorf("unexpected source location: %v", e.SourceLocation)
+	}
+
+	payload, err := json.Marshal(e.Payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `{"count":3,"message":"failed","user":{"id":"42"}}`; string(payload) != expected {
+		t.Errorf("unexpected payload: %s, expected %s", payload, expected)
+	}
+
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if fake.flushes != 1 {
+		t.Errorf("unexpected flushes: %d", fake.flushes)
+	}
+}
+
+func TestEntryFromJSON(t *testing.T) {
+	t.Run("timestamp object", func(t *testing.T) {
+		e, err := entryFromJSON([]byte(`{"message":"hi","timestamp":{"seconds":1700000000,"nanos":5},"severity":"WARNING"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !e.Timestamp.Equal(time.Unix(1700000000, 5)) || e.Severity != logging.Warning {
+			t.Errorf("unexpected entry: %v", e)
+		}
+	})
+
+	t.Run("invalid line", func(t *testing.T) {
+		if _, err := entryFromJSON([]byte(`{"message":`)); err == nil {
+			t.Error("expected error")
+		}
+	})
+
+	t.Run("invalid field", func(t *testing.T) {
+		if _, err := entryFromJSON([]byte(`{"message":"hi","logging.googleapis.com/labels":"a"}`)); err == nil {
+			t.Error("expected error")
+		}
+	})
+}
+
+type errWriter struct{}
+
+func (errWriter) Write([]byte) (int, error) {
+	return 0, errors.New("unexpected write")
+}
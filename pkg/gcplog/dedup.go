@@ -0,0 +1,150 @@
+package gcplog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/jussi-kalliokoski/goldjson"
+)
+
+// DuplicateKeys selects how attributes with the same key in the same group of an entry are handled, e.g. when a key
+// is added with WithAttrs and again when logging. Only attributes added directly to the group are compared, not
+// those of nested groups.
+type DuplicateKeys int
+
+const (
+	// DuplicateKeysKeep writes all attributes, so the entry contains the key more than once.
+	DuplicateKeysKeep DuplicateKeys = iota
+	// DuplicateKeysFirstWins writes only the first attribute with a key.
+	DuplicateKeysFirstWins
+	// DuplicateKeysLastWins writes only the last attribute with a key.
+	DuplicateKeysLastWins
+)
+
+func (h *Handler) trackDuplicateKeys() bool {
+	return h.opts.DuplicateKeys != DuplicateKeysKeep || h.opts.ReportDuplicateKeys
+}
+
+// dedupAttrs returns the attributes to write according to mode, and the keys duplicated by attributes at index from
+// and after, as the ones before were already checked.
+func dedupAttrs(attrs []slog.Attr, from int, mode DuplicateKeys) ([]slog.Attr, []string) {
+	// Index of the attribute written for every key.
+	seen := make(map[string]int, len(attrs))
+	var dups []string
+	for i, a := range attrs {
+		if _, ok := seen[a.Key]; ok {
+			if i >= from {
+				dups = append(dups, a.Key)
+			}
+			if mode == DuplicateKeysLastWins {
+				seen[a.Key] = i
+			}
+			continue
+		}
+		seen[a.Key] = i
+	}
+	if len(dups) == 0 || mode == DuplicateKeysKeep {
+		return attrs, dups
+	}
+	kept := make([]slog.Attr, 0, len(seen))
+	for i, a := range attrs {
+		if seen[a.Key] == i {
+			kept = append(kept, a)
+		}
+	}
+	return kept, dups
+}
+
+func duplicateKeysError(dups []string) error {
+	var err error
+	for _, k := range dups {
+		err = errors.Join(err, fmt.Errorf("duplicate key: %s", k))
+	}
+	return err
+}
+
+// withAttrsDedup merges as with the attributes of the previous WithAttrs call in the same group, so that duplicates
+// across calls are found.
+func (h *Handler) withAttrsDedup(as []slog.Attr) slog.Handler {
+	clone := *h
+	segments := h.segments
+	var prev segment
+	if n := len(segments); n > 0 && segments[n-1].group == "" {
+		prev = segments[n-1]
+		segments = segments[:n-1]
+	}
+
+	attrs := cloneSlice(prev.attrs, len(as))
+	for _, a := range as {
+		if h.groupPrefix != "" {
+			a.Key = h.groupPrefix + a.Key
+		}
+		if a, ok := h.attrEncoder.process(context.Background(), a); ok {
+			attrs = append(attrs, resolveAttr(a, 0))
+		}
+	}
+	kept, dups := dedupAttrs(attrs, len(prev.attrs), h.opts.DuplicateKeys)
+	seg := segment{attrs: kept, dups: cloneAppend(prev.dups, dups...)}
+
+	staticFields, w := goldjson.NewStaticFields()
+	var err error
+	for _, a := range kept {
+		err = errors.Join(err, addAttr(w, a, &h.attrEncoder))
+	}
+	if h.opts.ReportDuplicateKeys {
+		err = errors.Join(err, duplicateKeysError(seg.dups))
+	}
+	seg.staticFields, seg.err = staticFields, errors.Join(err, w.End())
+
+	clone.segments = cloneAppend(segments, seg)
+	return &clone
+}
+
+// addAttrsDedup writes the attributes of the record together with those of the last WithAttrs call in the same group,
+// without their pre-encoded fields, so that duplicates can be dropped from either.
+func (h *Handler) addAttrsDedup(ctx context.Context, l *goldjson.LineWriter, r *slog.Record) error {
+	segments := h.segments
+	var last segment
+	if n := len(segments); n > 0 && segments[n-1].group == "" {
+		last = segments[n-1]
+		segments = segments[:n-1]
+	}
+
+	attrs := make([]slog.Attr, 0, len(last.attrs)+r.NumAttrs())
+	attrs = append(attrs, last.attrs...)
+	r.Attrs(func(a slog.Attr) bool {
+		if h.groupPrefix != "" {
+			a.Key = h.groupPrefix + a.Key
+		}
+		if a, ok := h.attrEncoder.process(ctx, a); ok {
+			attrs = append(attrs, a)
+		}
+		return true
+	})
+	kept, dups := dedupAttrs(attrs, len(last.attrs), h.opts.DuplicateKeys)
+
+	var err error
+	var groups int
+	for _, seg := range segments {
+		if seg.group != "" {
+			l.StartRecord(seg.group)
+			groups++
+			continue
+		}
+		l.AddStaticFields(seg.staticFields)
+		err = errors.Join(err, seg.err)
+	}
+	for _, a := range kept {
+		err = errors.Join(err, addAttr(l, a, &h.attrEncoder))
+	}
+	if h.opts.ReportDuplicateKeys {
+		// Attributes of the last segment are encoded again, so only its duplicate keys are taken from it.
+		err = errors.Join(err, duplicateKeysError(last.dups), duplicateKeysError(dups))
+	}
+	for ; groups > 0; groups-- {
+		l.EndRecord()
+	}
+	return err
+}
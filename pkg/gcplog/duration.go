@@ -0,0 +1,30 @@
+package gcplog
+
+import (
+	"time"
+
+	"github.com/jussi-kalliokoski/goldjson"
+)
+
+// DurationFormat is the encoding of slog.KindDuration values.
+type DurationFormat int
+
+const (
+	// DurationNanos encodes durations as an integer number of nanoseconds, e.g. 1500000000.
+	DurationNanos DurationFormat = iota
+	// DurationSeconds encodes durations as a floating point number of seconds, e.g. 1.5.
+	DurationSeconds
+	// DurationString encodes durations as a string formatted by time.Duration.String, e.g. "1.5s".
+	DurationString
+)
+
+func addDuration(l *goldjson.LineWriter, key string, d time.Duration, format DurationFormat) {
+	switch format {
+	case DurationSeconds:
+		l.AddFloat64(key, d.Seconds())
+	case DurationString:
+		l.AddString(key, d.String())
+	default:
+		l.AddInt64(key, int64(d))
+	}
+}
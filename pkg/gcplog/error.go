@@ -1,8 +1,11 @@
 package gcplog
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
+	"reflect"
+	"runtime"
 
 	"github.com/jussi-kalliokoski/goldjson"
 )
@@ -12,7 +15,20 @@ func Error(err error) slog.Attr {
 	return slog.Any("error", err)
 }
 
-func addError(l *goldjson.LineWriter, key string, err error) error {
+// errorFrame is a single frame of the stack of an error, emitted under the "<key>Frames" field.
+type errorFrame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// errorCause is a single error of the chain of wrapped errors, emitted under the "<key>Causes" field.
+type errorCause struct {
+	Message string `json:"message"`
+	Type    string `json:"type"`
+}
+
+func addError(l *goldjson.LineWriter, key string, err error, ae *attrEncoder) error {
 	basic := err.Error()
 	l.AddString(key, basic)
 
@@ -25,5 +41,95 @@ func addError(l *goldjson.LineWriter, key string, err error) error {
 			l.AddString(key+"Verbose", verbose)
 		}
 	}
+
+	var merr error
+	if frames := errorFrames(err); len(frames) > 0 {
+		merr = l.AddMarshal(key+"Frames", frames)
+	}
+	if ae.errorCauses {
+		if causes := errorCauses(err, nil); len(causes) > 0 {
+			merr = errors.Join(merr, l.AddMarshal(key+"Causes", causes))
+		}
+	}
+	return merr
+}
+
+// errorCauses appends the errors wrapped by err to causes, depth first, flattening errors joined with errors.Join.
+func errorCauses(err error, causes []errorCause) []errorCause {
+	var wrapped []error
+	switch u := err.(type) {
+	case interface{ Unwrap() []error }:
+		wrapped = u.Unwrap()
+	case interface{ Unwrap() error }:
+		wrapped = []error{u.Unwrap()}
+	}
+	for _, e := range wrapped {
+		if e == nil {
+			continue
+		}
+		causes = append(causes, errorCause{Message: e.Error(), Type: fmt.Sprintf("%T", e)})
+		causes = errorCauses(e, causes)
+	}
+	return causes
+}
+
+// errorFrames returns the frames of the stacks carried by err. For a chain of wrapped errors, the deepest stack is
+// used, as it is the closest to where the error originated. For errors joined with errors.Join, the frames of every
+// joined error are concatenated.
+func errorFrames(err error) []errorFrame {
+	var frames []errorFrame
+	for _, pcs := range errorStacks(err) {
+		fs := runtime.CallersFrames(pcs)
+		for {
+			f, more := fs.Next()
+			frames = append(frames, errorFrame{Function: f.Function, File: f.File, Line: f.Line})
+			if !more {
+				break
+			}
+		}
+	}
+	return frames
+}
+
+func errorStacks(err error) [][]uintptr {
+	if err == nil {
+		return nil
+	}
+	switch u := err.(type) {
+	case interface{ Unwrap() []error }:
+		var stacks [][]uintptr
+		for _, e := range u.Unwrap() {
+			stacks = append(stacks, errorStacks(e)...)
+		}
+		if len(stacks) > 0 {
+			return stacks
+		}
+	case interface{ Unwrap() error }:
+		if stacks := errorStacks(u.Unwrap()); len(stacks) > 0 {
+			return stacks
+		}
+	}
+	if pcs := stackTracerPCs(err); len(pcs) > 0 {
+		return [][]uintptr{pcs}
+	}
 	return nil
 }
+
+// stackTracerPCs returns the program counters of errors implementing the StackTracer interface of
+// github.com/pkg/errors, or any method StackTrace returning a slice of program counters, without depending on it.
+func stackTracerPCs(err error) []uintptr {
+	m := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !m.IsValid() {
+		return nil
+	}
+	t := m.Type()
+	if t.NumIn() != 0 || t.NumOut() != 1 || t.Out(0).Kind() != reflect.Slice || t.Out(0).Elem().Kind() != reflect.Uintptr {
+		return nil
+	}
+	st := m.Call(nil)[0]
+	pcs := make([]uintptr, st.Len())
+	for i := range pcs {
+		pcs[i] = uintptr(st.Index(i).Uint())
+	}
+	return pcs
+}
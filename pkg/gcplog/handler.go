@@ -4,7 +4,7 @@ package gcplog
 // Changes:
 // Integrated with open telemetry directly.
 // Trace context is optional.
-// Labels removed.
+// Labels are set through options and context.
 // Added service context.
 // Richer error reporting.
 
@@ -22,8 +22,10 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"maps"
 	"os"
 	"runtime"
+	"strconv"
 
 	"cloud.google.com/go/compute/metadata"
 	"github.com/jussi-kalliokoski/goldjson"
@@ -36,6 +38,18 @@ const (
 	svcNameKey        = "GCPLOG_SERVICE_NAME"
 	otelSvcVersionKey = "OTEL_SERVICE_VERSION"
 	svcVersionKey     = "GCPLOG_SERVICE_VERSION"
+	// Set by Cloud Run and Cloud Functions.
+	kServiceKey       = "K_SERVICE"
+	kRevisionKey      = "K_REVISION"
+	functionTargetKey = "FUNCTION_TARGET"
+	// Set in every Kubernetes pod.
+	kubernetesHostKey = "KUBERNETES_SERVICE_HOST"
+	// Configuration of NewAutoHandler.
+	logLevelKey      = "LOG_LEVEL"
+	logFormatKey     = "LOG_FORMAT"
+	logSourceKey     = "LOG_SOURCE"
+	logFormatJSON    = "json"
+	logFormatConsole = "console"
 )
 
 // Value for this variable can be set during build.
@@ -48,27 +62,107 @@ type HandlerOptions struct {
 	AddSource bool
 
 	// Minimal log level to log, defaults to slog.LevelInfo
+	// Use a *slog.LevelVar to change the level at runtime, e.g. with LevelHandler or ToggleDebugOnSignal.
 	Level slog.Leveler
 
 	// Service name and version to add to the log
 	ServiceName    string
 	ServiceVersion string
 
+	// Name of the component logging, e.g. "ingest-worker", written in the scope field so that entries of large
+	// binaries can be filtered by subsystem. Can be set per logger with WithScope.
+	Scope string
+
 	// If this is set to true, errors will be reported to GCP error reporting.
+	// Reported entries get the @type of a ReportedErrorEvent, so that Error Reporting picks them up regardless of
+	// their message. They also get a stack_trace field with the stack of the log call,
+	// so that Error Reporting groups them by stack.
 	ReportErrors bool
 
+	// Add an array of the errors wrapped by logged errors, with their message and type, under "<key>Causes",
+	// so that wrapped errors can be queried.
+	ErrorCauses bool
+
+	// Minimal level of entries reported to GCP error reporting, defaults to slog.LevelError.
+	MinReportLevel slog.Leveler
+
 	// GCP project ID to use for trace context
 	GCPProjectID string
+
+	// Maximum number of nested groups started with WithGroup. Further groups are flattened into the keys of their
+	// attributes, e.g. "a.b.key", and Handle returns an error. Defaults to no limit.
+	MaxGroupDepth int
+
+	// Writer for entries of level ERROR and above, e.g. os.Stderr when writing other entries to os.Stdout.
+	// Defaults to writing all entries to the writer of the handler.
+	ErrorWriter io.Writer
+
+	// Receives counts of entries, bytes, errors and dropped entries, e.g. a *Counters published with expvar.
+	Metrics Metrics
+
+	// Sample entries with identical level and message. Defaults to logging all entries.
+	Sampling *SamplingOptions
+	// Drop entries below slog.LevelWarn logged with the context of an unsampled trace, so that detailed entries are
+	// only kept for sampled requests. Entries logged without a trace are not dropped.
+	DropUnsampledTraces bool
+
+	// Returns attributes, in the form of the args of slog.Logger.Log, to add to every entry logged with a context,
+	// even when logging directly with slog instead of a logger from the context.
+	// Set it to ctxslog.Args to add the attributes added with ctxslog.AddArgs. Entries logged with ctxslog functions or
+	// loggers returned by ctxslog.Extract already have them, so they should not be mixed.
+	ContextArgs func(ctx context.Context) []any
+
+	// Labels to add to every entry, e.g. to route entries with log sinks or to use them in log-based metrics.
+	// Labels can also be added to entries logged with a context through WithLabels.
+	Labels map[string]string
+
+	// Functions transforming every attribute before it is encoded, applied in order, before RedactKeys.
+	AttrProcessors []AttrProcessor
+
+	// Keys of attributes whose values are replaced with "[REDACTED]", e.g. "password" or "*token*".
+	// Keys are matched case-insensitively, either exactly or as a path.Match pattern, at any depth:
+	// inside groups and inside the JSON encoding of values logged with slog.Any.
+	RedactKeys []string
+
+	// Handling of attributes with the same key in the same group, defaults to DuplicateKeysKeep.
+	DuplicateKeys DuplicateKeys
+	// Return an error from Handle for every duplicate key, e.g. to find them in tests with a failing handler.
+	ReportDuplicateKeys bool
+
+	// Encoding of slog.KindDuration values, defaults to DurationNanos.
+	DurationFormat DurationFormat
+
+	// Encoding of the timestamp of entries, defaults to TimestampRFC3339Nano.
+	TimestampFormat TimestampFormat
+	// Convert the timestamp of entries to UTC, so that it does not depend on the time zone of the container.
+	// Time values of attributes are not converted.
+	TimestampUTC bool
+
+	// Field names of the entries, defaults to SchemaGCP.
+	Schema SchemaMode
+
+	// Add a process group to every entry with the pid and hostname, and the container name, pod name and namespace
+	// from the CONTAINER_NAME, POD_NAME and POD_NAMESPACE environment variables, e.g. set through the Kubernetes
+	// downward API.
+	AddProcess bool
 }
 
 // NewAutoHandler returns slog.Handler that writes to w using GCP structured logging format.
 // It automatically detects GCP project ID.
 // If the program is not running on GCE, it returns console handler.
+//
+// Options can also be set with environment variables, explicit options take precedence:
+// LOG_LEVEL sets the level when HandlerOptions.Level is nil, e.g. "debug" or "WARN+2",
+// LOG_SOURCE=true enables AddSource, and LOG_FORMAT=json or LOG_FORMAT=console forces the handler
+// regardless of the environment, e.g. to get JSON locally.
 func NewAutoHandler(w io.Writer, opts *HandlerOptions) slog.Handler {
 	if opts == nil {
 		opts = &HandlerOptions{}
 	}
-	if !metadata.OnGCE() {
+	applyEnv(opts)
+	format := os.Getenv(logFormatKey)
+	onGCE := format != logFormatConsole && metadata.OnGCE()
+	if format == logFormatConsole || (format != logFormatJSON && !onGCE) {
 		o := &console.HandlerOptions{
 			Level:     opts.Level,
 			AddSource: opts.AddSource,
@@ -76,7 +170,7 @@ func NewAutoHandler(w io.Writer, opts *HandlerOptions) slog.Handler {
 
 		return console.NewHandler(os.Stderr, o)
 	}
-	if opts.GCPProjectID == "" {
+	if opts.GCPProjectID == "" && onGCE {
 		// Detect project ID
 		opts.GCPProjectID, _ = metadata.ProjectID()
 	}
@@ -87,9 +181,23 @@ func NewAutoHandler(w io.Writer, opts *HandlerOptions) slog.Handler {
 	if opts.ServiceVersion == "" {
 		opts.ServiceVersion = detectServiceVersion()
 	}
+	opts.Labels = detectLabels(opts.Labels, onGCE)
 	return NewHandler(w, opts)
 }
 
+// applyEnv sets options from LOG_LEVEL and LOG_SOURCE, unless they are set explicitly. Invalid values are ignored.
+func applyEnv(opts *HandlerOptions) {
+	if v := os.Getenv(logLevelKey); v != "" && opts.Level == nil {
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(v)); err == nil {
+			opts.Level = level
+		}
+	}
+	if v := os.Getenv(logSourceKey); v != "" && !opts.AddSource {
+		opts.AddSource, _ = strconv.ParseBool(v)
+	}
+}
+
 func detectServiceName() string {
 	// check GCPLOG_SERVICE_NAME
 	sn := os.Getenv(svcNameKey)
@@ -97,7 +205,16 @@ func detectServiceName() string {
 		return sn
 	}
 	// Fallback to OTEL_SERVICE_NAME
-	return os.Getenv(otelSvcNameKey)
+	sn = os.Getenv(otelSvcNameKey)
+	if sn != "" {
+		return sn
+	}
+	// Fallback to the Cloud Run service or Cloud Functions function
+	sn = os.Getenv(kServiceKey)
+	if sn != "" {
+		return sn
+	}
+	return os.Getenv(functionTargetKey)
 }
 
 func detectServiceVersion() string {
@@ -114,7 +231,35 @@ func detectServiceVersion() string {
 		return sv
 	}
 	// Fallback to OTEL_SERVICE_VERSION
-	return os.Getenv(otelSvcVersionKey)
+	sv = os.Getenv(otelSvcVersionKey)
+	if sv != "" {
+		return sv
+	}
+	// Fallback to the Cloud Run or Cloud Functions revision
+	return os.Getenv(kRevisionKey)
+}
+
+// detectLabels adds labels describing the environment to labels, without overwriting them:
+// the Cloud Functions entry point, and the GKE cluster name and location. The cluster is only looked up on GCE, since
+// metadata requests block for seconds elsewhere, e.g. in other Kubernetes clusters.
+func detectLabels(labels map[string]string, onGCE bool) map[string]string {
+	detected := make(map[string]string)
+	if ft := os.Getenv(functionTargetKey); ft != "" {
+		detected["function_target"] = ft
+	}
+	if onGCE && os.Getenv(kubernetesHostKey) != "" {
+		if name, err := metadata.InstanceAttributeValue("cluster-name"); err == nil && name != "" {
+			detected["cluster_name"] = name
+		}
+		if location, err := metadata.InstanceAttributeValue("cluster-location"); err == nil && location != "" {
+			detected["cluster_location"] = location
+		}
+	}
+	if len(detected) == 0 {
+		return labels
+	}
+	maps.Copy(detected, labels)
+	return detected
 }
 
 // NewHandler returns slog.Handler that writes to w using GCP structured logging format.
@@ -122,10 +267,57 @@ func NewHandler(w io.Writer, opts *HandlerOptions) *Handler {
 	if opts == nil {
 		opts = &HandlerOptions{}
 	}
+	writers := []io.Writer{w}
+	var errEncoder *goldjson.Encoder
+	if opts.ErrorWriter != nil {
+		errEncoder = newEncoder(opts.ErrorWriter, opts)
+		if opts.ErrorWriter != w {
+			writers = append(writers, opts.ErrorWriter)
+		}
+	}
+	var s *sampler
+	if opts.Sampling != nil {
+		s = newSampler(*opts.Sampling)
+	}
+	var process *goldjson.StaticFields
+	if opts.AddProcess {
+		// Encoding strings and ints can't fail.
+		process, _ = newProcessFields()
+	}
+	return &Handler{
+		opts:       *opts,
+		encoder:    newEncoder(w, opts),
+		errEncoder: errEncoder,
+		sampler:    s,
+		attrEncoder: attrEncoder{
+			redactor:       newRedactor(opts.RedactKeys),
+			durationFormat: opts.DurationFormat,
+			errorCauses:    opts.ErrorCauses,
+			processors:     opts.AttrProcessors,
+		},
+		process: process,
+		writers: writers,
+	}
+}
+
+// newEncoder returns an encoder writing to w, with the keys used with opts prepared.
+func newEncoder(w io.Writer, opts *HandlerOptions) *goldjson.Encoder {
+	if opts.Metrics != nil {
+		w = &meteredWriter{w: w, metrics: opts.Metrics}
+	}
 	encoder := goldjson.NewEncoder(w)
+	if opts.Schema == SchemaOTLP {
+		prepareOTLPKeys(encoder)
+	}
 	encoder.PrepareKey(fieldMessage)
 	encoder.PrepareKey(fieldTimestamp)
+	if opts.TimestampFormat == TimestampSecondsNanos {
+		encoder.PrepareKey(fieldTimestampObject)
+		encoder.PrepareKey(fieldTimestampSeconds)
+		encoder.PrepareKey(fieldTimestampNanos)
+	}
 	encoder.PrepareKey(fieldSeverity)
+	encoder.PrepareKey(fieldScope)
 	if opts.AddSource {
 		encoder.PrepareKey(fieldSourceLocation)
 		encoder.PrepareKey(fieldSourceFile)
@@ -143,115 +335,218 @@ func NewHandler(w io.Writer, opts *HandlerOptions) *Handler {
 		encoder.PrepareKey(fieldVersion)
 	}
 	if opts.ReportErrors {
+		encoder.PrepareKey(fieldType)
 		encoder.PrepareKey(fieldContext)
+		encoder.PrepareKey(fieldStackTrace)
 	}
-	return &Handler{
-		opts:    *opts,
-		encoder: encoder,
+	encoder.PrepareKey(fieldLabels)
+	if opts.Sampling != nil {
+		encoder.PrepareKey(fieldSampled)
+	}
+	if opts.AddProcess {
+		encoder.PrepareKey(fieldProcess)
 	}
+	return encoder
 }
 
 type Handler struct {
-	opts         HandlerOptions
-	encoder      *goldjson.Encoder
-	attrBuilders []func(ctx context.Context, h *Handler, l *goldjson.LineWriter, next func(context.Context) error) error
+	opts        HandlerOptions
+	encoder     *goldjson.Encoder
+	errEncoder  *goldjson.Encoder
+	sampler     *sampler
+	attrEncoder attrEncoder
+	process     *goldjson.StaticFields
+	segments    []segment
+	// Writers of the encoders, flushed and closed by Flush and Close.
+	writers []io.Writer
+	// Number of groups started with WithGroup, and the prefix of keys and the error of groups flattened after
+	// reaching HandlerOptions.MaxGroupDepth.
+	groupDepth  int
+	groupPrefix string
+	groupErr    error
+}
+
+// segment is either attributes added with WithAttrs, or a group started with WithGroup.
+// They are kept in a flat list, rather than e.g. a chain of closures, so that Handle does not allocate.
+type segment struct {
+	staticFields *goldjson.StaticFields
+	err          error
+	group        string
+	// Resolved attributes and duplicated keys, only kept when tracking duplicate keys.
+	attrs []slog.Attr
+	dups  []string
 }
 
-func (h *Handler) Enabled(_ context.Context, level slog.Level) bool {
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
 	minLevel := slog.LevelInfo
 	if h.opts.Level != nil {
 		minLevel = h.opts.Level.Level()
 	}
-	return level >= minLevel
+	if level < minLevel {
+		return false
+	}
+	return !h.opts.DropUnsampledTraces || level >= slog.LevelWarn || !unsampledTrace(ctx)
+}
+
+func (h *Handler) minReportLevel() slog.Level {
+	if h.opts.MinReportLevel != nil {
+		return h.opts.MinReportLevel.Level()
+	}
+	return slog.LevelError
 }
 
 func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
-	l := h.encoder.NewLine()
+	var sampled int64
+	if h.sampler != nil {
+		var ok bool
+		ok, sampled = h.sampler.sample(&r)
+		if !ok {
+			if h.opts.Metrics != nil {
+				h.opts.Metrics.Dropped(r.Level)
+			}
+			return nil
+		}
+	}
+
+	encoder := h.encoder
+	if h.errEncoder != nil && r.Level >= slog.LevelError {
+		encoder = h.errEncoder
+	}
+	l := encoder.NewLine()
+	otlp := h.opts.Schema == SchemaOTLP
+	messageKey, timestampKey, severityKey := fieldMessage, fieldTimestamp, fieldSeverity
+	if h.opts.TimestampFormat == TimestampSecondsNanos {
+		timestampKey = fieldTimestampObject
+	}
+	if otlp {
+		messageKey, timestampKey, severityKey = fieldOTLPBody, fieldOTLPTimestamp, fieldOTLPSeverityText
+	}
 
 	// Add message
-	l.AddString(fieldMessage, r.Message)
+	l.AddString(messageKey, r.Message)
 
 	// Add timestamp
 	time := r.Time.Round(0) // strip monotonic to match Attr behavior
-	_ = l.AddTime(fieldTimestamp, time)
+	if h.opts.TimestampUTC {
+		time = time.UTC()
+	}
+	addTimestamp(l, timestampKey, time, h.opts.TimestampFormat)
 
 	// Add severity
-	switch {
-	case r.Level >= slog.LevelError:
-		l.AddString(fieldSeverity, severityError)
-	case r.Level >= slog.LevelWarn:
-		l.AddString(fieldSeverity, severityWarn)
-	case r.Level >= slog.LevelInfo:
-		l.AddString(fieldSeverity, severityInfo)
-	default:
-		l.AddString(fieldSeverity, severityDebug)
+	l.AddString(severityKey, severity(r.Level))
+	if otlp {
+		l.AddInt64(fieldOTLPSeverityNumber, otlpSeverityNumber(r.Level))
 	}
 
 	if h.opts.AddSource {
-		addSourceLocation(l, &r)
+		if otlp {
+			addOTLPSourceLocation(l, &r)
+		} else {
+			addSourceLocation(l, &r)
+		}
 	}
 
-	if h.opts.GCPProjectID != "" {
+	if otlp {
+		// Trace IDs don't need the project ID to be correlated.
+		addOTLPTrace(ctx, l)
+	} else if h.opts.GCPProjectID != "" {
 		addTrace(ctx, l, h.opts.GCPProjectID)
 	}
 
 	if h.opts.ServiceName != "" {
-		addServiceContext(l, h.opts.ServiceName, h.opts.ServiceVersion)
+		if otlp {
+			addOTLPService(l, h.opts.ServiceName, h.opts.ServiceVersion)
+		} else {
+			addServiceContext(l, h.opts.ServiceName, h.opts.ServiceVersion)
+		}
+	}
+
+	if h.opts.Scope != "" {
+		addScope(l, h.opts.Scope, otlp)
+	}
+
+	addLabels(ctx, l, h.opts.Labels)
+
+	if h.process != nil {
+		l.AddStaticFields(h.process)
+	}
+
+	if sampled > 0 {
+		l.AddInt64(fieldSampled, sampled)
 	}
 
 	// Error reporting doesn't work without a service name
-	if h.opts.ServiceName != "" && h.opts.ReportErrors && r.Level >= slog.LevelError {
-		var hasReport bool
+	if h.opts.ServiceName != "" && h.opts.ReportErrors && r.Level >= h.minReportLevel() {
+		l.AddString(fieldType, reportedErrorEventType)
+		var hasReport, hasStackTrace bool
 		r.Attrs(func(attr slog.Attr) bool {
-			if attr.Key == fieldContext {
+			switch attr.Key {
+			case fieldContext:
 				// We already have context as Attr
 				hasReport = true
-				return false
+			case fieldStackTrace:
+				hasStackTrace = true
 			}
-			return true
+			return !hasReport || !hasStackTrace
 		})
 		if !hasReport {
 			r.AddAttrs(NewReportContext(r.PC))
 		}
+		if !hasStackTrace && r.PC != 0 {
+			l.AddString(fieldStackTrace, stackTrace(r.PC))
+		}
 	}
 
 	// Add attributes
-	err := h.addAttrs(ctx, l, &r)
+	err := h.addContextArgs(ctx, l)
+	err = errors.Join(err, h.groupErr, h.addAttrs(ctx, l, &r))
 	err = errors.Join(err, l.End())
 
+	if h.opts.Metrics != nil {
+		h.opts.Metrics.Entry(r.Level)
+		if err != nil {
+			h.opts.Metrics.Error(err)
+		}
+	}
 	return err
 }
 
 func (h *Handler) WithAttrs(as []slog.Attr) slog.Handler {
+	if h.trackDuplicateKeys() {
+		return h.withAttrsDedup(as)
+	}
 	clone := *h
 	staticFields, w := goldjson.NewStaticFields()
 	var err error
 	for _, attr := range as {
-		err = errors.Join(err, addAttr(w, attr))
+		if h.groupPrefix != "" {
+			attr.Key = h.groupPrefix + attr.Key
+		}
+		if attr, ok := h.attrEncoder.process(context.Background(), attr); ok {
+			err = errors.Join(err, addAttr(w, attr, &h.attrEncoder))
+		}
 	}
-	clone.attrBuilders = cloneAppend(
-		h.attrBuilders,
-		func(ctx context.Context, h *Handler, l *goldjson.LineWriter, next func(context.Context) error) error {
-			l.AddStaticFields(staticFields)
-			return errors.Join(err, next(ctx))
-		},
-	)
-	err = w.End()
+	err = errors.Join(err, w.End())
+	clone.segments = cloneAppend(h.segments, segment{staticFields: staticFields, err: err})
 	return &clone
 }
 
 func (h *Handler) WithGroup(name string) slog.Handler {
 	clone := *h
+	if h.opts.MaxGroupDepth > 0 && h.groupDepth >= h.opts.MaxGroupDepth {
+		// Flatten groups that are nested too deep into the keys of their attributes.
+		clone.groupPrefix = h.groupPrefix + name + "."
+		clone.groupErr = errors.Join(h.groupErr, fmt.Errorf("group nested too deep: %s", name))
+		return &clone
+	}
+	clone.groupDepth++
 	clone.encoder = h.encoder.Clone()
 	clone.encoder.PrepareKey(name)
-	clone.attrBuilders = cloneAppend(
-		h.attrBuilders,
-		func(ctx context.Context, h *Handler, l *goldjson.LineWriter, next func(context.Context) error) error {
-			l.StartRecord(name)
-			defer l.EndRecord()
-			return next(ctx)
-		},
-	)
+	if h.errEncoder != nil {
+		clone.errEncoder = h.errEncoder.Clone()
+		clone.errEncoder.PrepareKey(name)
+	}
+	clone.segments = cloneAppend(h.segments, segment{group: name})
 	return &clone
 }
 
@@ -270,6 +565,7 @@ func addSourceLocation(l *goldjson.LineWriter, r *slog.Record) {
 func addTrace(ctx context.Context, l *goldjson.LineWriter, projectName string) {
 	sc := trace.SpanContextFromContext(ctx)
 	if !sc.IsValid() {
+		addTraceHeader(ctx, l, projectName)
 		return
 	}
 
@@ -278,6 +574,28 @@ func addTrace(ctx context.Context, l *goldjson.LineWriter, projectName string) {
 	l.AddBool(fieldTraceSampled, sc.IsSampled())
 }
 
+// unsampledTrace reports whether ctx has a trace that is not sampled, using the same trace as addTrace.
+func unsampledTrace(ctx context.Context) bool {
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		return !sc.IsSampled()
+	}
+	th, ok := traceHeaderFromContext(ctx)
+	return ok && !th.sampled
+}
+
+func addTraceHeader(ctx context.Context, l *goldjson.LineWriter, projectName string) {
+	th, ok := traceHeaderFromContext(ctx)
+	if !ok {
+		return
+	}
+
+	l.AddString(fieldTraceID, fmt.Sprintf("projects/%s/traces/%s", projectName, th.traceID))
+	if th.spanID != "" {
+		l.AddString(fieldTraceSpanID, th.spanID)
+	}
+	l.AddBool(fieldTraceSampled, th.sampled)
+}
+
 func addServiceContext(l *goldjson.LineWriter, name, version string) {
 	l.StartRecord(fieldServiceContext)
 	defer l.EndRecord()
@@ -287,39 +605,104 @@ func addServiceContext(l *goldjson.LineWriter, name, version string) {
 }
 
 func (h *Handler) addAttrs(ctx context.Context, l *goldjson.LineWriter, r *slog.Record) error {
-	if len(h.attrBuilders) == 0 {
-		return addAttrsRaw(l, r)
+	if h.trackDuplicateKeys() {
+		return h.addAttrsDedup(ctx, l, r)
 	}
-
-	b := func(ctx context.Context) error {
-		return addAttrsRaw(l, r)
+	var err error
+	var groups int
+	for _, seg := range h.segments {
+		if seg.group != "" {
+			l.StartRecord(seg.group)
+			groups++
+			continue
+		}
+		l.AddStaticFields(seg.staticFields)
+		if seg.err != nil {
+			err = errors.Join(err, seg.err)
+		}
+	}
+	if rerr := addAttrsRaw(ctx, l, r, h.groupPrefix, &h.attrEncoder); rerr != nil {
+		err = errors.Join(err, rerr)
+	}
+	for ; groups > 0; groups-- {
+		l.EndRecord()
 	}
+	return err
+}
 
-	for i := range h.attrBuilders {
-		attrBuilder := h.attrBuilders[len(h.attrBuilders)-1-i]
-		next := b
-		b = func(ctx context.Context) error {
-			return attrBuilder(ctx, h, l, next)
+func (h *Handler) addContextArgs(ctx context.Context, l *goldjson.LineWriter) error {
+	if h.opts.ContextArgs == nil {
+		return nil
+	}
+	args := h.opts.ContextArgs(ctx)
+	if len(args) == 0 {
+		return nil
+	}
+	var err error
+	// Converts args to attributes like slog.Logger.Log does.
+	for _, a := range slog.Group("", args...).Value.Group() {
+		if a, ok := h.attrEncoder.process(ctx, a); ok {
+			err = errors.Join(err, addAttr(l, a, &h.attrEncoder))
 		}
 	}
+	return err
+}
 
-	return b(ctx)
+// attrEncoder holds the options applied when encoding attributes.
+type attrEncoder struct {
+	redactor       *redactor
+	durationFormat DurationFormat
+	errorCauses    bool
+	processors     []AttrProcessor
 }
 
-func addAttrsRaw(l *goldjson.LineWriter, r *slog.Record) error {
+func addAttrsRaw(ctx context.Context, l *goldjson.LineWriter, r *slog.Record, prefix string, ae *attrEncoder) error {
 	var err error
 	r.Attrs(func(attr slog.Attr) bool {
-		err = errors.Join(err, addAttr(l, attr))
+		if prefix != "" {
+			attr.Key = prefix + attr.Key
+		}
+		if attr, ok := ae.process(ctx, attr); ok {
+			err = errors.Join(err, addAttr(l, attr, ae))
+		}
 		return true
 	})
 	return err
 }
 
-func addAttr(l *goldjson.LineWriter, a slog.Attr) error {
-	a.Value.Resolve()
+// maxGroupDepth limits nesting of groups produced by slog.LogValuer values, which could otherwise recurse forever.
+const maxGroupDepth = 100
+
+// resolveAttr resolves slog.LogValuer values of a and of the attributes of its groups, at any depth.
+// Like with slog.JSONHandler, values inside other types, e.g. struct fields, are encoded with encoding/json.
+func resolveAttr(a slog.Attr, depth int) slog.Attr {
+	a.Value = a.Value.Resolve()
+	if a.Value.Kind() != slog.KindGroup {
+		return a
+	}
+	if depth >= maxGroupDepth {
+		return slog.String(a.Key, "!ERROR: groups nested too deep")
+	}
+	attrs := a.Value.Group()
+	resolved := make([]slog.Attr, len(attrs))
+	for i, ga := range attrs {
+		resolved[i] = resolveAttr(ga, depth+1)
+	}
+	return slog.Attr{Key: a.Key, Value: slog.GroupValue(resolved...)}
+}
+
+func addAttr(l *goldjson.LineWriter, a slog.Attr, ae *attrEncoder) error {
+	return addResolvedAttr(l, resolveAttr(a, 0), ae)
+}
+
+func addResolvedAttr(l *goldjson.LineWriter, a slog.Attr, ae *attrEncoder) error {
+	if ae.redactor.match(a.Key) {
+		l.AddString(a.Key, redactedValue)
+		return nil
+	}
 	switch a.Value.Kind() {
 	case slog.KindGroup:
-		return addGroup(l, a)
+		return addGroup(l, a, ae)
 	case slog.KindString:
 		l.AddString(a.Key, a.Value.String())
 		return nil
@@ -336,17 +719,17 @@ func addAttr(l *goldjson.LineWriter, a slog.Attr) error {
 		l.AddBool(a.Key, a.Value.Bool())
 		return nil
 	case slog.KindDuration:
-		l.AddInt64(a.Key, int64(a.Value.Duration()))
+		addDuration(l, a.Key, a.Value.Duration(), ae.durationFormat)
 		return nil
 	case slog.KindTime:
 		return l.AddTime(a.Key, a.Value.Time())
 	case slog.KindAny:
-		return addAny(l, a)
+		return addAny(l, a, ae)
 	}
 	return fmt.Errorf("bad kind: %s", a.Value.Kind())
 }
 
-func addGroup(l *goldjson.LineWriter, a slog.Attr) error {
+func addGroup(l *goldjson.LineWriter, a slog.Attr, ae *attrEncoder) error {
 	attrs := a.Value.Group()
 	if len(attrs) == 0 {
 		return nil
@@ -355,20 +738,43 @@ func addGroup(l *goldjson.LineWriter, a slog.Attr) error {
 	defer l.EndRecord()
 	var err error
 	for _, a := range attrs {
-		err = errors.Join(err, addAttr(l, a))
+		err = errors.Join(err, addResolvedAttr(l, a, ae))
 	}
 	return err
 }
 
-func addAny(l *goldjson.LineWriter, a slog.Attr) error {
+func addAny(l *goldjson.LineWriter, a slog.Attr, ae *attrEncoder) error {
 	v := a.Value.Any()
 	_, jm := v.(json.Marshaler)
 	if err, ok := v.(error); ok && !jm {
-		return addError(l, a.Key, err)
+		return addError(l, a.Key, err, ae)
 	}
-	return l.AddMarshal(a.Key, v)
+	if raw, ok := v.(json.RawMessage); ok && ae.redactor == nil {
+		// AddMarshal validates and writes it as is, apart from insignificant whitespace. Invalid JSON is written as a
+		// string, so that the payload is not lost.
+		if err := l.AddMarshal(a.Key, raw); err != nil {
+			l.AddString(a.Key, string(raw))
+			return fmt.Errorf("invalid JSON: %s", a.Key)
+		}
+		return nil
+	}
+	if ae.redactor != nil {
+		var err error
+		if v, err = ae.redactor.redactValue(v); err != nil {
+			l.AddString(a.Key, marshalErrorPrefix+err.Error())
+			return err
+		}
+	}
+	if err := l.AddMarshal(a.Key, v); err != nil {
+		// Keep the key in the entry, so that it shows that data was lost.
+		l.AddString(a.Key, marshalErrorPrefix+err.Error())
+		return err
+	}
+	return nil
 }
 
+const marshalErrorPrefix = "!ERROR marshaling: "
+
 const (
 	fieldMessage        = "message"
 	fieldTimestamp      = "time"
@@ -386,10 +792,13 @@ const (
 )
 
 const (
-	severityError = "ERROR"
-	severityWarn  = "WARNING"
-	severityInfo  = "INFO"
-	severityDebug = "DEBUG"
+	severityEmergency = "EMERGENCY"
+	severityAlert     = "ALERT"
+	severityCritical  = "CRITICAL"
+	severityError     = "ERROR"
+	severityWarn      = "WARNING"
+	severityInfo      = "INFO"
+	severityDebug     = "DEBUG"
 )
 
 func cloneSlice[T any](slice []T, extraCap int) []T {
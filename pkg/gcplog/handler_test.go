@@ -7,7 +7,10 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"os"
 	"runtime"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 	"unsafe"
@@ -55,6 +58,36 @@ func TestHandler(t *testing.T) {
 		}
 	})
 
+	t.Run("auto handler from environment", func(t *testing.T) {
+		tests := []struct {
+			name         string
+			format       string
+			level        string
+			opts         *gcplog.HandlerOptions
+			expectedJSON bool
+			expectedMin  slog.Level
+		}{
+			{"json", "json", "debug", nil, true, slog.LevelDebug},
+			{"console", "console", "warn", nil, false, slog.LevelWarn},
+			{"explicit level", "json", "debug", &gcplog.HandlerOptions{Level: slog.LevelError}, true, slog.LevelError},
+			{"invalid level", "json", "loud", nil, true, slog.LevelInfo},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				t.Setenv("LOG_FORMAT", tt.format)
+				t.Setenv("LOG_LEVEL", tt.level)
+
+				h := gcplog.NewAutoHandler(io.Discard, tt.opts)
+				_, isJSON := h.(*gcplog.Handler)
+
+				require.Equal(t, tt.expectedJSON, isJSON)
+				require.Equal(t, true, h.Enabled(context.Background(), tt.expectedMin))
+				require.Equal(t, false, h.Enabled(context.Background(), tt.expectedMin-1))
+			})
+		}
+	})
+
 	t.Run("message", func(t *testing.T) {
 		type Entry struct {
 			Message string `json:"message"`
@@ -88,6 +121,11 @@ func TestHandler(t *testing.T) {
 			{"below warn", slog.LevelWarn - 1, "INFO"},
 			{"below error", slog.LevelError - 1, "WARNING"},
 			{"above error", slog.LevelError + 1, "ERROR"},
+			{"critical", gcplog.LevelCritical, "CRITICAL"},
+			{"alert", gcplog.LevelAlert, "ALERT"},
+			{"emergency", gcplog.LevelEmergency, "EMERGENCY"},
+			{"below critical", gcplog.LevelCritical - 1, "ERROR"},
+			{"above emergency", gcplog.LevelEmergency + 1, "EMERGENCY"},
 		}
 
 		for _, tt := range tests {
@@ -261,6 +299,67 @@ func TestHandler(t *testing.T) {
 					TraceSampled: vptr(false),
 				},
 			},
+			{
+				"traceparent header",
+				&gcplog.HandlerOptions{
+					GCPProjectID: "my-project",
+				},
+				gcplog.ContextWithTraceHeader(context.Background(), "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"),
+				TraceInfo{
+					TraceID:      vptr("projects/my-project/traces/4bf92f3577b34da6a3ce929d0e0e4736"),
+					SpanID:       vptr("00f067aa0ba902b7"),
+					TraceSampled: vptr(true),
+				},
+			},
+			{
+				"cloud trace context header",
+				&gcplog.HandlerOptions{
+					GCPProjectID: "my-project",
+				},
+				gcplog.ContextWithTraceHeader(context.Background(), "105445aa7843bc8bf206b12000100000/255;o=1"),
+				TraceInfo{
+					TraceID:      vptr("projects/my-project/traces/105445aa7843bc8bf206b12000100000"),
+					SpanID:       vptr("00000000000000ff"),
+					TraceSampled: vptr(true),
+				},
+			},
+			{
+				"cloud trace context header without span",
+				&gcplog.HandlerOptions{
+					GCPProjectID: "my-project",
+				},
+				gcplog.ContextWithTraceHeader(context.Background(), "105445aa7843bc8bf206b12000100000"),
+				TraceInfo{
+					TraceID:      vptr("projects/my-project/traces/105445aa7843bc8bf206b12000100000"),
+					TraceSampled: vptr(false),
+				},
+			},
+			{
+				"invalid header",
+				&gcplog.HandlerOptions{
+					GCPProjectID: "my-project",
+				},
+				gcplog.ContextWithTraceHeader(context.Background(), "00-00000000000000000000000000000000-00f067aa0ba902b7-01"),
+				TraceInfo{},
+			},
+			{
+				"span takes precedence over header",
+				&gcplog.HandlerOptions{
+					GCPProjectID: "my-project",
+				},
+				trace.ContextWithSpanContext(
+					gcplog.ContextWithTraceHeader(context.Background(), "105445aa7843bc8bf206b12000100000/1;o=1"),
+					trace.NewSpanContext(trace.SpanContextConfig{
+						TraceID: [16]byte{1, 1},
+						SpanID:  trace.SpanID{2},
+					}),
+				),
+				TraceInfo{
+					TraceID:      vptr("projects/my-project/traces/01010000000000000000000000000000"),
+					SpanID:       vptr("0200000000000000"),
+					TraceSampled: vptr(false),
+				},
+			},
 		}
 
 		for _, tt := range tests {
@@ -281,6 +380,699 @@ func TestHandler(t *testing.T) {
 		}
 	})
 
+	t.Run("labels", func(t *testing.T) {
+		type Entry struct {
+			Labels map[string]string `json:"logging.googleapis.com/labels"`
+		}
+
+		tests := []struct {
+			name     string
+			opts     *gcplog.HandlerOptions
+			ctx      context.Context
+			expected map[string]string
+		}{
+			{
+				"no labels",
+				nil,
+				context.Background(),
+				nil,
+			},
+			{
+				"options",
+				&gcplog.HandlerOptions{
+					Labels: map[string]string{"env": "prod"},
+				},
+				context.Background(),
+				map[string]string{"env": "prod"},
+			},
+			{
+				"context",
+				nil,
+				gcplog.WithLabels(context.Background(), map[string]string{"tenant": "acme"}),
+				map[string]string{"tenant": "acme"},
+			},
+			{
+				"context overrides options",
+				&gcplog.HandlerOptions{
+					Labels: map[string]string{"env": "prod", "tenant": "none"},
+				},
+				gcplog.WithLabels(
+					gcplog.WithLabels(context.Background(), map[string]string{"tenant": "acme", "team": "payments"}),
+					map[string]string{"team": "billing"},
+				),
+				map[string]string{"env": "prod", "tenant": "acme", "team": "billing"},
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				var capture slogtest.Capture[Entry]
+				logger, errs := slogtest.NewWithErrorHandler(gcplog.NewHandler(&capture, tt.opts))
+
+				logger.InfoContext(tt.ctx, "labels")
+				entries := capture.Entries()
+				err := errs.Err()
+
+				require.NoError(t, err)
+				require.Equal(t, tt.expected, entries[0].Labels)
+			})
+		}
+	})
+
+	t.Run("OTLP schema", func(t *testing.T) {
+		type Entry struct {
+			Body           string  `json:"body"`
+			Timestamp      string  `json:"timestamp"`
+			SeverityText   string  `json:"severity_text"`
+			SeverityNumber int     `json:"severity_number"`
+			TraceID        string  `json:"trace_id"`
+			SpanID         string  `json:"span_id"`
+			TraceFlags     string  `json:"trace_flags"`
+			CodeFunction   string  `json:"code.function"`
+			ServiceName    string  `json:"service.name"`
+			ServiceVersion string  `json:"service.version"`
+			Message        *string `json:"message"`
+			Severity       *string `json:"severity"`
+		}
+
+		ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    [16]byte{1, 1},
+			SpanID:     trace.SpanID{2},
+			TraceFlags: trace.FlagsSampled,
+		}))
+		var capture slogtest.Capture[Entry]
+		h := gcplog.NewHandler(&capture, &gcplog.HandlerOptions{
+			Schema:         gcplog.SchemaOTLP,
+			AddSource:      true,
+			ServiceName:    "my-service",
+			ServiceVersion: "v1",
+		})
+
+		pc := getPC()
+		caller, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+		r := slog.NewRecord(time.Date(2023, 6, 15, 19, 24, 13, 0, time.UTC), gcplog.LevelCritical, "otlp", pc)
+		require.NoError(t, h.Handle(ctx, r))
+
+		expected := Entry{
+			Body:           "otlp",
+			Timestamp:      "2023-06-15T19:24:13Z",
+			SeverityText:   "CRITICAL",
+			SeverityNumber: 18,
+			TraceID:        "01010000000000000000000000000000",
+			SpanID:         "0200000000000000",
+			TraceFlags:     "01",
+			CodeFunction:   caller.Function,
+			ServiceName:    "my-service",
+			ServiceVersion: "v1",
+		}
+		require.Equal(t, expected, capture.Entries()[0])
+	})
+
+	t.Run("context args", func(t *testing.T) {
+		type Entry struct {
+			RequestID string `json:"request_id"`
+			User      string `json:"user"`
+			Group     struct {
+				Method string `json:"method"`
+			} `json:"group"`
+		}
+
+		type argsKey struct{}
+		var capture slogtest.Capture[Entry]
+		h := gcplog.NewHandler(&capture, &gcplog.HandlerOptions{
+			ContextArgs: func(ctx context.Context) []any {
+				args, _ := ctx.Value(argsKey{}).([]any)
+				return args
+			},
+		})
+		logger, errs := slogtest.NewWithErrorHandler(h)
+
+		ctx := context.WithValue(context.Background(), argsKey{}, []any{"request_id", "abc", slog.String("user", "john")})
+		logger.WithGroup("group").InfoContext(ctx, "with context args", "method", "GET")
+		logger.Info("without context args")
+		entries := capture.Entries()
+
+		require.NoError(t, errs.Err())
+		require.Equal(t, "abc", entries[0].RequestID)
+		require.Equal(t, "john", entries[0].User)
+		require.Equal(t, "GET", entries[0].Group.Method)
+		require.Equal(t, "", entries[1].RequestID)
+	})
+
+	t.Run("process", func(t *testing.T) {
+		type Process struct {
+			PID       int    `json:"pid"`
+			Hostname  string `json:"hostname"`
+			Container string `json:"container"`
+			Pod       string `json:"pod"`
+			Namespace string `json:"namespace"`
+		}
+		type Entry struct {
+			Process *Process `json:"process"`
+		}
+
+		t.Setenv("CONTAINER_NAME", "app")
+		t.Setenv("POD_NAME", "app-7d9f8b6c5-x2v4q")
+		t.Setenv("POD_NAMESPACE", "")
+		hostname, err := os.Hostname()
+		require.NoError(t, err)
+
+		var capture slogtest.Capture[Entry]
+		h := gcplog.NewHandler(&capture, &gcplog.HandlerOptions{AddProcess: true})
+		logger, errs := slogtest.NewWithErrorHandler(h.WithAttrs([]slog.Attr{slog.String("other", "attr")}))
+		logger.Info("with process")
+
+		var captureWithout slogtest.Capture[Entry]
+		slog.New(gcplog.NewHandler(&captureWithout, nil)).Info("without process")
+
+		expected := &Process{
+			PID:       os.Getpid(),
+			Hostname:  hostname,
+			Container: "app",
+			Pod:       "app-7d9f8b6c5-x2v4q",
+		}
+		require.NoError(t, errs.Err())
+		require.Equal(t, expected, capture.Entries()[0].Process)
+		require.Equal(t, (*Process)(nil), captureWithout.Entries()[0].Process)
+	})
+
+	t.Run("stack trace", func(t *testing.T) {
+		type Entry struct {
+			StackTrace *string `json:"stack_trace"`
+		}
+
+		tests := []struct {
+			name     string
+			opts     *gcplog.HandlerOptions
+			level    slog.Level
+			expected bool
+		}{
+			{"error", &gcplog.HandlerOptions{ServiceName: "my-service", ReportErrors: true}, slog.LevelError, true},
+			{"warn", &gcplog.HandlerOptions{ServiceName: "my-service", ReportErrors: true}, slog.LevelWarn, false},
+			{"warn with min report level", &gcplog.HandlerOptions{ServiceName: "my-service", ReportErrors: true, MinReportLevel: slog.LevelWarn}, slog.LevelWarn, true},
+			{"not reporting errors", &gcplog.HandlerOptions{ServiceName: "my-service"}, slog.LevelError, false},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				var capture slogtest.Capture[Entry]
+				logger, errs := slogtest.NewWithErrorHandler(gcplog.NewHandler(&capture, tt.opts))
+
+				logger.Log(context.Background(), tt.level, "failed")
+				fs := runtime.CallersFrames([]uintptr{getPC()})
+				caller, _ := fs.Next()
+				entries := capture.Entries()
+				err := errs.Err()
+
+				require.NoError(t, err)
+				if !tt.expected {
+					require.Equal(t, (*string)(nil), entries[0].StackTrace)
+					return
+				}
+				stack := *entries[0].StackTrace
+				require.Equal(t, true, strings.HasPrefix(stack, "goroutine "), stack)
+				require.Equal(t, true, strings.Contains(stack, "\n"+caller.Function+"(...)\n\t"+caller.File+":"+strconv.Itoa(caller.Line-1)+" +0x"), stack)
+			})
+		}
+	})
+
+	t.Run("reported error event type", func(t *testing.T) {
+		type Entry struct {
+			Type *string `json:"@type"`
+		}
+
+		tests := []struct {
+			name     string
+			opts     *gcplog.HandlerOptions
+			level    slog.Level
+			expected *string
+		}{
+			{"error", &gcplog.HandlerOptions{ServiceName: "my-service", ReportErrors: true}, slog.LevelError, vptr("type.googleapis.com/google.devtools.clouderrorreporting.v1beta1.ReportedErrorEvent")},
+			{"warn", &gcplog.HandlerOptions{ServiceName: "my-service", ReportErrors: true}, slog.LevelWarn, nil},
+			{"not reporting errors", &gcplog.HandlerOptions{ServiceName: "my-service"}, slog.LevelError, nil},
+			{"no service name", &gcplog.HandlerOptions{ReportErrors: true}, slog.LevelError, nil},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				var capture slogtest.Capture[Entry]
+				logger, errs := slogtest.NewWithErrorHandler(gcplog.NewHandler(&capture, tt.opts))
+
+				logger.Log(context.Background(), tt.level, "failed")
+				entries := capture.Entries()
+
+				require.NoError(t, errs.Err())
+				require.Equal(t, tt.expected, entries[0].Type)
+			})
+		}
+	})
+
+	t.Run("recover and report", func(t *testing.T) {
+		type ReportLocation struct {
+			FunctionName string `json:"functionName"`
+		}
+		type Entry struct {
+			Message    string `json:"message"`
+			Severity   string `json:"severity"`
+			Type       string `json:"@type"`
+			StackTrace string `json:"stack_trace"`
+			Context    struct {
+				ReportLocation ReportLocation `json:"reportLocation"`
+			} `json:"context"`
+		}
+
+		var capture slogtest.Capture[Entry]
+		defer slog.SetDefault(slog.Default())
+		slog.SetDefault(slog.New(gcplog.NewHandler(&capture, &gcplog.HandlerOptions{ServiceName: "my-service", ReportErrors: true})))
+
+		func() {
+			defer gcplog.RecoverAndReport(context.Background())
+			panicking()
+		}()
+		entries := capture.Entries()
+
+		require.Equal(t, 1, len(entries))
+		require.Equal(t, "panic: boom", entries[0].Message)
+		require.Equal(t, "ERROR", entries[0].Severity)
+		require.Equal(t, "type.googleapis.com/google.devtools.clouderrorreporting.v1beta1.ReportedErrorEvent", entries[0].Type)
+		require.Equal(t, "github.com/mycujoo/go-stdlib/pkg/gcplog_test.panicking", entries[0].Context.ReportLocation.FunctionName)
+		require.Equal(t, true, strings.HasPrefix(entries[0].StackTrace, "panic: boom\n\ngoroutine "), entries[0].StackTrace)
+	})
+
+	t.Run("error writer", func(t *testing.T) {
+		type Entry struct {
+			Message string `json:"message"`
+			Group   struct {
+				Key string `json:"key"`
+			} `json:"group"`
+		}
+
+		var out, errOut slogtest.Capture[Entry]
+		h := gcplog.NewHandler(&out, &gcplog.HandlerOptions{ErrorWriter: &errOut})
+		logger, errs := slogtest.NewWithErrorHandler(h.WithGroup("group"))
+
+		logger.Info("info", "key", "a")
+		logger.Warn("warn", "key", "b")
+		logger.Error("error", "key", "c")
+		logger.Log(context.Background(), gcplog.LevelCritical, "critical", "key", "d")
+
+		require.NoError(t, errs.Err())
+		outEntries, errEntries := out.Entries(), errOut.Entries()
+		require.Equal(t, 2, len(outEntries))
+		require.Equal(t, "info", outEntries[0].Message)
+		require.Equal(t, "warn", outEntries[1].Message)
+		require.Equal(t, 2, len(errEntries))
+		require.Equal(t, "error", errEntries[0].Message)
+		require.Equal(t, "c", errEntries[0].Group.Key)
+		require.Equal(t, "critical", errEntries[1].Message)
+	})
+
+	t.Run("metrics", func(t *testing.T) {
+		var buf strings.Builder
+		var counters gcplog.Counters
+		h := gcplog.NewHandler(&buf, &gcplog.HandlerOptions{
+			Metrics:  &counters,
+			Sampling: &gcplog.SamplingOptions{First: 1},
+		})
+		logger := slog.New(h)
+
+		logger.Info("info")
+		logger.Error("error")
+		logger.Error("error")
+		logger.Log(context.Background(), gcplog.LevelCritical, "critical")
+		logger.Info("marshal", "erroring", ErroringMarshal{})
+
+		require.Equal(t, int64(2), counters.Entries("INFO"))
+		require.Equal(t, int64(1), counters.Entries("ERROR"))
+		require.Equal(t, int64(1), counters.Entries("CRITICAL"))
+		require.Equal(t, int64(1), counters.DroppedEntries("ERROR"))
+		require.Equal(t, int64(1), counters.Errors())
+		require.Equal(t, int64(buf.Len()), counters.BytesWritten())
+
+		var published struct {
+			Entries map[string]int64 `json:"entries"`
+			Bytes   int64            `json:"bytes"`
+			Errors  int64            `json:"errors"`
+			Dropped map[string]int64 `json:"dropped"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(counters.String()), &published))
+		require.Equal(t, int64(2), published.Entries["INFO"])
+		require.Equal(t, int64(0), published.Entries["DEBUG"])
+		require.Equal(t, int64(buf.Len()), published.Bytes)
+		require.Equal(t, int64(1), published.Errors)
+		require.Equal(t, int64(1), published.Dropped["ERROR"])
+	})
+
+	t.Run("sampling", func(t *testing.T) {
+		type Entry struct {
+			Message string `json:"message"`
+			Sampled int64  `json:"sampled"`
+		}
+
+		ctx := context.Background()
+		var capture slogtest.Capture[Entry]
+		h := gcplog.NewHandler(&capture, &gcplog.HandlerOptions{
+			Sampling: &gcplog.SamplingOptions{
+				Interval:   time.Second,
+				First:      2,
+				Thereafter: 3,
+			},
+		})
+
+		start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+		for i := 0; i < 10; i++ {
+			require.NoError(t, h.Handle(ctx, slog.NewRecord(start, slog.LevelInfo, "loop", 0)))
+		}
+		require.NoError(t, h.Handle(ctx, slog.NewRecord(start, slog.LevelInfo, "other", 0)))
+		require.NoError(t, h.Handle(ctx, slog.NewRecord(start.Add(time.Second), slog.LevelInfo, "loop", 0)))
+
+		expected := []Entry{
+			{"loop", 0},
+			{"loop", 0},
+			{"loop", 2},
+			{"loop", 2},
+			{"other", 0},
+			{"loop", 2},
+		}
+		require.Equal(t, expected, capture.Entries())
+	})
+
+	t.Run("attr processors", func(t *testing.T) {
+		type Request struct {
+			UserID string `json:"user_id"`
+			Path   string `json:"path"`
+		}
+		type Entry struct {
+			UserID    string  `json:"user_id"`
+			Tenant    string  `json:"tenant"`
+			Request   Request `json:"request"`
+			DebugOnly *string `json:"debug_only"`
+		}
+
+		type tenantKey struct{}
+		hashUserID := func(_ context.Context, a slog.Attr) slog.Attr {
+			if a.Key == "user_id" {
+				return slog.String(a.Key, "hash("+a.Value.String()+")")
+			}
+			return a
+		}
+		tenantFromContext := func(ctx context.Context, a slog.Attr) slog.Attr {
+			if a.Key == "tenant" {
+				tenant, _ := ctx.Value(tenantKey{}).(string)
+				return slog.String(a.Key, tenant)
+			}
+			return a
+		}
+		dropDebug := func(_ context.Context, a slog.Attr) slog.Attr {
+			if a.Key == "debug_only" {
+				return slog.Attr{}
+			}
+			return a
+		}
+
+		var capture slogtest.Capture[Entry]
+		var h slog.Handler = gcplog.NewHandler(&capture, &gcplog.HandlerOptions{
+			AttrProcessors: []gcplog.AttrProcessor{hashUserID, tenantFromContext, dropDebug},
+		})
+		h = h.WithAttrs([]slog.Attr{slog.String("user_id", "1")})
+		logger, errs := slogtest.NewWithErrorHandler(h)
+
+		ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+		logger.LogAttrs(ctx, slog.LevelInfo, "processed",
+			slog.String("tenant", ""),
+			slog.Group("request", slog.String("user_id", "2"), slog.String("path", "/")),
+			slog.Bool("debug_only", true),
+		)
+
+		expected := Entry{
+			UserID:  "hash(1)",
+			Tenant:  "acme",
+			Request: Request{UserID: "hash(2)", Path: "/"},
+		}
+		require.NoError(t, errs.Err())
+		require.Equal(t, expected, capture.Entries()[0])
+	})
+
+	t.Run("redaction", func(t *testing.T) {
+		type Credentials struct {
+			User     string            `json:"user"`
+			Password string            `json:"password"`
+			Headers  map[string]string `json:"headers"`
+		}
+		type Request struct {
+			Path  string `json:"path"`
+			Email string `json:"email"`
+		}
+		type Entry struct {
+			Token       string      `json:"token"`
+			AccessToken string      `json:"access_token"`
+			Credentials Credentials `json:"credentials"`
+			Request     Request     `json:"request"`
+			Count       string      `json:"count"`
+		}
+
+		ctx := context.Background()
+		var capture slogtest.Capture[Entry]
+		var h slog.Handler = gcplog.NewHandler(&capture, &gcplog.HandlerOptions{
+			RedactKeys: []string{"Password", "email", "count", "*token*", "authorization"},
+		})
+		h = h.WithAttrs([]slog.Attr{slog.String("token", "secret")})
+		logger, errs := slogtest.NewWithErrorHandler(h)
+
+		logger.LogAttrs(ctx, slog.LevelInfo, "redacted",
+			slog.String("access_token", "secret"),
+			slog.Any("credentials", Credentials{
+				User:     "john",
+				Password: "secret",
+				Headers:  map[string]string{"Authorization": "Bearer secret", "Accept": "*/*"},
+			}),
+			slog.Group("request", slog.String("path", "/"), slog.String("email", "john@example.com")),
+			slog.Int("count", 1),
+		)
+
+		expected := Entry{
+			Token:       "[REDACTED]",
+			AccessToken: "[REDACTED]",
+			Credentials: Credentials{
+				User:     "john",
+				Password: "[REDACTED]",
+				Headers:  map[string]string{"Authorization": "[REDACTED]", "Accept": "*/*"},
+			},
+			Request: Request{Path: "/", Email: "[REDACTED]"},
+			Count:   "[REDACTED]",
+		}
+		require.NoError(t, errs.Err())
+		require.Equal(t, expected, capture.Entries()[0])
+	})
+
+	t.Run("duplicate keys", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			mode     gcplog.DuplicateKeys
+			expected string
+		}{
+			{"keep", gcplog.DuplicateKeysKeep, `"a":1,"b":2,"a":3,"g":{"c":4,"c":5,"b":6,"c":7}`},
+			{"first wins", gcplog.DuplicateKeysFirstWins, `"a":1,"b":2,"g":{"c":4,"b":6}`},
+			{"last wins", gcplog.DuplicateKeysLastWins, `"b":2,"a":3,"g":{"b":6,"c":7}`},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				var buf strings.Builder
+				var h slog.Handler = gcplog.NewHandler(&buf, &gcplog.HandlerOptions{
+					DuplicateKeys:       tt.mode,
+					ReportDuplicateKeys: true,
+				})
+				h = h.WithAttrs([]slog.Attr{slog.Int("a", 1), slog.Int("b", 2)})
+				h = h.WithAttrs([]slog.Attr{slog.Int("a", 3)})
+				h = h.WithGroup("g").WithAttrs([]slog.Attr{slog.Int("c", 4)}).WithAttrs([]slog.Attr{slog.Int("c", 5)})
+
+				r := slog.NewRecord(time.Time{}, slog.LevelInfo, "dup", 0)
+				r.AddAttrs(slog.Int("b", 6), slog.Int("c", 7))
+				err := h.Handle(context.Background(), r)
+
+				require.Error(t, err)
+				require.Equal(t, "duplicate key: a\nduplicate key: c\nduplicate key: c", err.Error())
+				_, attrs, _ := strings.Cut(strings.TrimSpace(buf.String()), `"severity":"INFO",`)
+				require.Equal(t, tt.expected+"}", attrs)
+			})
+		}
+	})
+
+	t.Run("scope", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			logger   func(w io.Writer) *slog.Logger
+			expected string
+		}{
+			{
+				"option",
+				func(w io.Writer) *slog.Logger {
+					return slog.New(gcplog.NewHandler(w, &gcplog.HandlerOptions{Scope: "ingest-worker"}))
+				},
+				`"scope":"ingest-worker"`,
+			},
+			{
+				"with scope",
+				func(w io.Writer) *slog.Logger {
+					h := gcplog.NewHandler(w, &gcplog.HandlerOptions{Scope: "ingest-worker"})
+					return gcplog.WithScope(slog.New(h), "billing")
+				},
+				`"scope":"billing"`,
+			},
+			{
+				"OTLP schema",
+				func(w io.Writer) *slog.Logger {
+					h := gcplog.NewHandler(w, &gcplog.HandlerOptions{Schema: gcplog.SchemaOTLP})
+					return slog.New(h.WithScope("billing"))
+				},
+				`"scope.name":"billing"`,
+			},
+			{
+				"other handler",
+				func(w io.Writer) *slog.Logger {
+					return gcplog.WithScope(slog.New(slog.NewJSONHandler(w, nil)), "billing")
+				},
+				`"scope":"billing"`,
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				var buf strings.Builder
+				tt.logger(&buf).Info("scoped")
+				require.Equal(t, true, strings.Contains(buf.String(), tt.expected), buf.String())
+			})
+		}
+	})
+
+	t.Run("flush and close", func(t *testing.T) {
+		var out, errOut lockedBuffer
+		w := gcplog.NewAsyncWriter(&out, nil)
+		ew := gcplog.NewAsyncWriter(&errOut, nil)
+		h := gcplog.NewHandler(w, &gcplog.HandlerOptions{ErrorWriter: ew})
+		logger := slog.New(h.WithAttrs([]slog.Attr{slog.Int("a", 1)}))
+
+		logger.Info("first")
+		require.NoError(t, h.Flush())
+		require.Equal(t, true, strings.Contains(out.String(), `"message":"first"`), out.String())
+
+		logger.Error("second")
+		require.NoError(t, h.Close())
+		require.Equal(t, true, strings.Contains(errOut.String(), `"message":"second"`), errOut.String())
+		_, err := w.Write([]byte("{}\n"))
+		require.Equal(t, os.ErrClosed, err)
+	})
+
+	t.Run("close does not close other writers", func(t *testing.T) {
+		f, err := os.CreateTemp(t.TempDir(), "log")
+		require.NoError(t, err)
+		defer f.Close()
+		h := gcplog.NewHandler(f, nil)
+
+		require.NoError(t, h.Close())
+		_, err = f.WriteString("{}\n")
+		require.NoError(t, err)
+	})
+
+	t.Run("drop unsampled traces", func(t *testing.T) {
+		h := gcplog.NewHandler(io.Discard, &gcplog.HandlerOptions{Level: slog.LevelDebug, DropUnsampledTraces: true})
+		sampled := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    [16]byte{1, 1},
+			SpanID:     trace.SpanID{2},
+			TraceFlags: trace.FlagsSampled,
+		}))
+		unsampled := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID: [16]byte{1, 1},
+			SpanID:  trace.SpanID{2},
+		}))
+		unsampledHeader := gcplog.ContextWithTraceHeader(context.Background(), "105445aa7843bc8bf206b12000100000/1;o=0")
+
+		tests := []struct {
+			name     string
+			ctx      context.Context
+			level    slog.Level
+			expected bool
+		}{
+			{"no trace", context.Background(), slog.LevelDebug, true},
+			{"sampled debug", sampled, slog.LevelDebug, true},
+			{"unsampled debug", unsampled, slog.LevelDebug, false},
+			{"unsampled info", unsampled, slog.LevelInfo, false},
+			{"unsampled warn", unsampled, slog.LevelWarn, true},
+			{"unsampled error", unsampled, slog.LevelError, true},
+			{"unsampled header info", unsampledHeader, slog.LevelInfo, false},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				require.Equal(t, tt.expected, h.Enabled(tt.ctx, tt.level))
+			})
+		}
+	})
+
+	t.Run("timestamp format", func(t *testing.T) {
+		ts := time.Date(2024, 1, 2, 5, 4, 5, 60000000, time.FixedZone("EET", 2*60*60))
+		tests := []struct {
+			name     string
+			opts     gcplog.HandlerOptions
+			expected string
+		}{
+			{"RFC3339Nano", gcplog.HandlerOptions{}, `"time":"2024-01-02T05:04:05.06+02:00"`},
+			{"RFC3339Nano UTC", gcplog.HandlerOptions{TimestampUTC: true}, `"time":"2024-01-02T03:04:05.06Z"`},
+			{
+				"seconds and nanos",
+				gcplog.HandlerOptions{TimestampFormat: gcplog.TimestampSecondsNanos},
+				`"timestamp":{"seconds":1704164645,"nanos":60000000}`,
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				var buf strings.Builder
+				h := gcplog.NewHandler(&buf, &tt.opts)
+
+				err := h.Handle(context.Background(), slog.NewRecord(ts, slog.LevelInfo, "ts", 0))
+
+				require.NoError(t, err)
+				require.Equal(t, `{"message":"ts",`+tt.expected+`,"severity":"INFO"}`, strings.TrimSpace(buf.String()))
+			})
+		}
+	})
+
+	t.Run("raw JSON", func(t *testing.T) {
+		var buf strings.Builder
+		h := gcplog.NewHandler(&buf, nil)
+
+		r := slog.NewRecord(time.Time{}, slog.LevelInfo, "raw", 0)
+		r.AddAttrs(
+			gcplog.RawJSON("body", []byte(`{"id": 1, "tags": ["a", "<b>"]}`)),
+			slog.Any("payload", json.RawMessage(`[1,2]`)),
+			gcplog.RawJSON("invalid", []byte(`{"id":`)),
+		)
+		err := h.Handle(context.Background(), r)
+
+		require.Error(t, err)
+		require.Equal(t, "invalid JSON: invalid", err.Error())
+		_, attrs, _ := strings.Cut(strings.TrimSpace(buf.String()), `"severity":"INFO",`)
+		require.Equal(t, `"body":{"id":1,"tags":["a","<b>"]},"payload":[1,2],"invalid":"{\"id\":"}`, attrs)
+	})
+
+	t.Run("max group depth", func(t *testing.T) {
+		var buf strings.Builder
+		var h slog.Handler = gcplog.NewHandler(&buf, &gcplog.HandlerOptions{MaxGroupDepth: 2})
+		h = h.WithGroup("a").WithGroup("b").WithAttrs([]slog.Attr{slog.Int("x", 1)})
+		h = h.WithGroup("c").WithAttrs([]slog.Attr{slog.Int("y", 2)}).WithGroup("d")
+
+		r := slog.NewRecord(time.Time{}, slog.LevelInfo, "deep", 0)
+		r.AddAttrs(slog.Int("z", 3))
+		err := h.Handle(context.Background(), r)
+
+		require.Error(t, err)
+		require.Equal(t, "group nested too deep: c\ngroup nested too deep: d", err.Error())
+		_, attrs, _ := strings.Cut(strings.TrimSpace(buf.String()), `"severity":"INFO",`)
+		require.Equal(t, `"a":{"b":{"x":1,"c.y":2,"c.d.z":3}}}`, attrs)
+	})
+
 	t.Run("groups and attrs", func(t *testing.T) {
 		t.Run("nested", func(t *testing.T) {
 			type Nested2 struct {
@@ -359,6 +1151,48 @@ func TestHandler(t *testing.T) {
 			require.Equal(t, expected, received)
 		})
 
+		t.Run("log valuer", func(t *testing.T) {
+			type User struct {
+				ID   string
+				Name string
+			}
+			type Group struct {
+				User User
+			}
+			type Entry struct {
+				User  User
+				Group Group
+				Loop  any
+			}
+
+			ctx := context.Background()
+			var capture slogtest.Capture[Entry]
+			var h slog.Handler = gcplog.NewHandler(&capture, nil)
+			h = h.WithAttrs([]slog.Attr{slog.Any("User", LogValuerUser{"1", "john"})})
+			logger, errs := slogtest.NewWithErrorHandler(h)
+			expected := Entry{
+				User:  User{ID: "1", Name: "john"},
+				Group: Group{User: User{ID: "2", Name: "jane"}},
+			}
+
+			logger.LogAttrs(ctx, slog.LevelError, "attrs",
+				slog.Group("Group", slog.Any("User", LogValuerUser{"2", "jane"})),
+				slog.Any("Loop", LoopValuer{}),
+			)
+			entries := capture.Entries()
+			received := entries[0]
+			err := errs.Err()
+
+			require.NoError(t, err)
+			loop := received.Loop
+			for depth := 0; depth < 100; depth++ {
+				loop = loop.(map[string]any)["Loop"]
+			}
+			require.Equal(t, any("!ERROR: groups nested too deep"), loop)
+			require.Equal(t, expected.User, received.User)
+			require.Equal(t, expected.Group, received.Group)
+		})
+
 		t.Run("empty group", func(t *testing.T) {
 			type Entry struct {
 				Group *struct{}
@@ -500,6 +1334,39 @@ func TestHandler(t *testing.T) {
 			require.Equal(t, expected, received)
 		})
 
+		t.Run("duration format", func(t *testing.T) {
+			type Entry struct {
+				Duration any
+			}
+
+			tests := []struct {
+				name     string
+				format   gcplog.DurationFormat
+				expected any
+			}{
+				{"nanos", gcplog.DurationNanos, float64(1500000000)},
+				{"seconds", gcplog.DurationSeconds, 1.5},
+				{"string", gcplog.DurationString, "1.5s"},
+			}
+
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					ctx := context.Background()
+					var capture slogtest.Capture[Entry]
+					logger, errs := slogtest.NewWithErrorHandler(gcplog.NewHandler(&capture, &gcplog.HandlerOptions{
+						DurationFormat: tt.format,
+					}))
+
+					logger.LogAttrs(ctx, slog.LevelError, "attrs", slog.Duration("Duration", 1500*time.Millisecond))
+					entries := capture.Entries()
+					err := errs.Err()
+
+					require.NoError(t, err)
+					require.Equal(t, tt.expected, entries[0].Duration)
+				})
+			}
+		})
+
 		t.Run("time", func(t *testing.T) {
 			type Entry struct {
 				TimeVal1 string
@@ -569,6 +1436,95 @@ func TestHandler(t *testing.T) {
 			require.Equal(t, expected, received)
 		})
 
+		t.Run("error with stack", func(t *testing.T) {
+			type Frame struct {
+				Function string `json:"function"`
+				File     string `json:"file"`
+				Line     int    `json:"line"`
+			}
+			type Entry struct {
+				Error       string
+				ErrorFrames []Frame
+			}
+
+			tests := []struct {
+				name           string
+				err            func() (error, int)
+				expectedFrames int
+			}{
+				{"stack tracer", func() (error, int) { return newStackError("foo"), 1 }, 1},
+				{"wrapped", func() (error, int) { return fmt.Errorf("wrapped: %w", newStackError("foo")), 1 }, 1},
+				{"joined", func() (error, int) {
+					return errors.Join(newStackError("foo"), errors.New("bar"), newStackError("baz")), 2
+				}, 2},
+			}
+
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					ctx := context.Background()
+					var capture slogtest.Capture[Entry]
+					logger, errs := slogtest.NewWithErrorHandler(gcplog.NewHandler(&capture, nil))
+
+					err, stacks := tt.err()
+					logger.LogAttrs(ctx, slog.LevelError, "attrs", gcplog.Error(err))
+					received := capture.Entries()[0]
+
+					require.NoError(t, errs.Err())
+					require.Equal(t, err.Error(), received.Error)
+					var found int
+					for _, f := range received.ErrorFrames {
+						if f.Function == "github.com/mycujoo/go-stdlib/pkg/gcplog_test.newStackError" {
+							found++
+							require.Equal(t, true, strings.HasSuffix(f.File, "handler_test.go"), f.File)
+						}
+					}
+					require.Equal(t, stacks, found)
+				})
+			}
+		})
+
+		t.Run("error causes", func(t *testing.T) {
+			type Cause struct {
+				Message string `json:"message"`
+				Type    string `json:"type"`
+			}
+			type Entry struct {
+				Error       string
+				ErrorCauses []Cause
+			}
+
+			ctx := context.Background()
+			var capture slogtest.Capture[Entry]
+			logger, errs := slogtest.NewWithErrorHandler(gcplog.NewHandler(&capture, &gcplog.HandlerOptions{ErrorCauses: true}))
+
+			joined := errors.Join(fmt.Errorf("open: %w", os.ErrNotExist), ExtendedError{"bar"})
+			logger.LogAttrs(ctx, slog.LevelError, "attrs", gcplog.Error(fmt.Errorf("load: %w", joined)))
+			logger.LogAttrs(ctx, slog.LevelError, "attrs", gcplog.Error(errors.New("plain")))
+			entries := capture.Entries()
+			expected := []Cause{
+				{joined.Error(), "*errors.joinError"},
+				{"open: file does not exist", "*fmt.wrapError"},
+				{"file does not exist", "*errors.errorString"},
+				{"bar", "gcplog_test.ExtendedError"},
+			}
+
+			require.NoError(t, errs.Err())
+			require.Equal(t, expected, entries[0].ErrorCauses)
+			require.Equal(t, []Cause(nil), entries[1].ErrorCauses)
+		})
+
+		t.Run("error without stack", func(t *testing.T) {
+			ctx := context.Background()
+			var capture slogtest.Capture[map[string]any]
+			logger, errs := slogtest.NewWithErrorHandler(gcplog.NewHandler(&capture, nil))
+
+			logger.LogAttrs(ctx, slog.LevelError, "attrs", gcplog.Error(errors.Join(errors.New("foo"), ExtendedError{"bar"})))
+			_, hasFrames := capture.Entries()[0]["errorFrames"]
+
+			require.NoError(t, errs.Err())
+			require.Equal(t, false, hasFrames)
+		})
+
 		t.Run("error with custom marshal", func(t *testing.T) {
 			type Entry struct {
 				JSONErrorVal JSONError
@@ -615,13 +1571,12 @@ func TestHandler(t *testing.T) {
 		t.Run("error", func(t *testing.T) {
 			type Entry struct {
 				Correct  string
-				Erroring *struct{}
+				Erroring string
 			}
 
 			ctx := context.Background()
 			var capture slogtest.Capture[Entry]
 			logger, errs := slogtest.NewWithErrorHandler(gcplog.NewHandler(&capture, nil))
-			expected := Entry{"correct", nil}
 
 			logger.LogAttrs(ctx, slog.LevelError, "attrs", slog.String("Correct", "correct"), slog.Any("erroring", ErroringMarshal{}))
 			entries := capture.Entries()
@@ -629,19 +1584,20 @@ func TestHandler(t *testing.T) {
 			err := errs.Err()
 
 			require.Error(t, err)
-			require.Equal(t, expected, received)
+			require.Equal(t, "correct", received.Correct)
+			require.Equal(t, true, strings.HasPrefix(received.Erroring, "!ERROR marshaling: "), received.Erroring)
+			require.Equal(t, true, strings.Contains(received.Erroring, "cannot be marshaled"), received.Erroring)
 		})
 
 		t.Run("WithAttrs error", func(t *testing.T) {
 			type Entry struct {
 				Correct  string
-				Erroring *struct{}
+				Erroring string
 			}
 
 			ctx := context.Background()
 			var capture slogtest.Capture[Entry]
 			logger, errs := slogtest.NewWithErrorHandler(gcplog.NewHandler(&capture, nil))
-			expected := Entry{"correct", nil}
 
 			logger.LogAttrs(ctx, slog.LevelError, "attrs", slog.String("Correct", "correct"), slog.Any("Erroring", ErroringMarshal{}))
 			entries := capture.Entries()
@@ -649,7 +1605,9 @@ func TestHandler(t *testing.T) {
 			err := errs.Err()
 
 			require.Error(t, err)
-			require.Equal(t, expected, received)
+			require.Equal(t, "correct", received.Correct)
+			require.Equal(t, true, strings.HasPrefix(received.Erroring, "!ERROR marshaling: "), received.Erroring)
+			require.Equal(t, true, strings.Contains(received.Erroring, "cannot be marshaled"), received.Erroring)
 		})
 
 		t.Run("Invalid Attr Kind", func(t *testing.T) {
@@ -684,6 +1642,26 @@ func TestHandler(t *testing.T) {
 		})
 	})
 
+	t.Run("no allocations", func(t *testing.T) {
+		if raceEnabled {
+			t.Skip("sync.Pool allocates with the race detector")
+		}
+		ctx := context.Background()
+		logger := slog.New(gcplog.NewHandler(&IgnoreWriter{}, nil)).
+			With(slog.String("component", "http")).
+			WithGroup("request").
+			With(slog.String("id", "abc"))
+
+		allocs := testing.AllocsPerRun(100, func() {
+			logger.LogAttrs(ctx, slog.LevelInfo, "hello world",
+				slog.String("method", "GET"),
+				slog.Int("status", 200),
+				slog.Bool("cached", true),
+			)
+		})
+		require.Equal(t, float64(0), allocs)
+	})
+
 	t.Run("Writer error", func(t *testing.T) {
 		ctx := context.Background()
 		var w ErrorWriter
@@ -715,6 +1693,28 @@ func Benchmark(b *testing.B) {
 			jsonLogger.Info("hello world")
 		}
 	})
+
+	ctx := context.Background()
+	attrs := func(logger *slog.Logger) func(b *testing.B) {
+		return func(b *testing.B) {
+			b.ReportAllocs()
+			for n := 0; n < b.N; n++ {
+				logger.LogAttrs(ctx, slog.LevelInfo, "hello world",
+					slog.String("method", "GET"),
+					slog.Int("status", 200),
+					slog.Bool("cached", true),
+				)
+			}
+		}
+	}
+	withGroup := func(logger *slog.Logger) *slog.Logger {
+		return logger.With(slog.String("component", "http")).WithGroup("request").With(slog.String("id", "abc"))
+	}
+
+	b.Run("gcplog attrs", attrs(slogdriverLogger))
+	b.Run("cloud logging JSONHandler attrs", attrs(jsonLogger))
+	b.Run("gcplog attrs with group", attrs(withGroup(slogdriverLogger)))
+	b.Run("cloud logging JSONHandler attrs with group", attrs(withGroup(jsonLogger)))
 }
 
 func NewCloudLoggingJSONHandler(w io.Writer, level slog.Leveler) *slog.JSONHandler {
@@ -800,6 +1800,51 @@ func (e ExtendedError) Format(s fmt.State, verb rune) {
 	}
 }
 
+// StackError mimics errors carrying a stack trace from github.com/pkg/errors.
+type StackError struct {
+	Message string
+	Stack   StackTrace
+}
+
+type StackTrace []Frame
+
+type Frame uintptr
+
+//go:noinline
+func newStackError(message string) StackError {
+	var pcs [32]uintptr
+	n := runtime.Callers(1, pcs[:])
+	stack := make(StackTrace, n)
+	for i, pc := range pcs[:n] {
+		stack[i] = Frame(pc)
+	}
+	return StackError{Message: message, Stack: stack}
+}
+
+func (e StackError) Error() string {
+	return e.Message
+}
+
+func (e StackError) StackTrace() StackTrace {
+	return e.Stack
+}
+
+type LogValuerUser struct {
+	ID   string
+	Name string
+}
+
+func (u LogValuerUser) LogValue() slog.Value {
+	return slog.GroupValue(slog.String("ID", u.ID), slog.String("Name", u.Name))
+}
+
+// LoopValuer nests itself in a group forever.
+type LoopValuer struct{}
+
+func (v LoopValuer) LogValue() slog.Value {
+	return slog.GroupValue(slog.Any("Loop", v))
+}
+
 type JSONError struct {
 	Message string
 }
@@ -847,6 +1892,11 @@ func (ErroringMarshal) MarshalJSON() ([]byte, error) {
 	return nil, fmt.Errorf("cannot be marshaled")
 }
 
+//go:noinline
+func panicking() {
+	panic("boom")
+}
+
 func getPC() uintptr {
 	var pcs [1]uintptr
 	runtime.Callers(2, pcs[:])
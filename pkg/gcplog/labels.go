@@ -0,0 +1,58 @@
+package gcplog
+
+import (
+	"context"
+	"maps"
+	"sort"
+
+	"github.com/jussi-kalliokoski/goldjson"
+)
+
+const fieldLabels = "logging.googleapis.com/labels"
+
+type labelsKey struct{}
+
+// WithLabels returns a copy of ctx carrying labels, which are added to all entries logged with the returned context.
+// Labels already carried by ctx are kept unless overwritten, and they take precedence over HandlerOptions.Labels.
+func WithLabels(ctx context.Context, labels map[string]string) context.Context {
+	merged := maps.Clone(labelsFromContext(ctx))
+	if merged == nil {
+		merged = make(map[string]string, len(labels))
+	}
+	maps.Copy(merged, labels)
+	return context.WithValue(ctx, labelsKey{}, merged)
+}
+
+func labelsFromContext(ctx context.Context) map[string]string {
+	labels, _ := ctx.Value(labelsKey{}).(map[string]string)
+	return labels
+}
+
+func addLabels(ctx context.Context, l *goldjson.LineWriter, defaults map[string]string) {
+	ctxLabels := labelsFromContext(ctx)
+	if len(defaults) == 0 && len(ctxLabels) == 0 {
+		return
+	}
+
+	labels := defaults
+	if len(ctxLabels) > 0 {
+		labels = maps.Clone(defaults)
+		if labels == nil {
+			labels = make(map[string]string, len(ctxLabels))
+		}
+		maps.Copy(labels, ctxLabels)
+	}
+
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	l.StartRecord(fieldLabels)
+	defer l.EndRecord()
+
+	for _, k := range keys {
+		l.AddString(k, labels[k])
+	}
+}
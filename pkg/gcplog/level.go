@@ -0,0 +1,85 @@
+package gcplog
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+)
+
+// Levels above slog.LevelError, mapped to the GCP severities of the same name, e.g. to page on CRITICAL entries.
+const (
+	LevelCritical  = slog.LevelError + 4
+	LevelAlert     = slog.LevelError + 8
+	LevelEmergency = slog.LevelError + 12
+)
+
+// LevelHandler returns an http.Handler to read and change level at runtime, e.g. to enable debug logging in
+// production without a redeploy. Pass the same level as HandlerOptions.Level.
+//
+// GET responds with the current level. PUT and POST change the level to the one given by the `level` query parameter
+// or the request body, e.g. `curl -X PUT localhost:8081/loglevel?level=debug`. Levels are parsed like
+// slog.Level.UnmarshalText, e.g. "DEBUG", "info" or "WARN+2".
+//
+// The handler is not protected in any way, so it should only be served on an internal admin port.
+func LevelHandler(level *slog.LevelVar) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut, http.MethodPost:
+			value := r.URL.Query().Get("level")
+			if value == "" {
+				body, err := io.ReadAll(io.LimitReader(r.Body, 64))
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+				value = strings.TrimSpace(string(body))
+			}
+			var l slog.Level
+			if err := l.UnmarshalText([]byte(value)); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			level.Set(l)
+		default:
+			w.Header().Set("Allow", "GET, PUT, POST")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = io.WriteString(w, level.Level().String()+"\n")
+	})
+}
+
+// ToggleDebugOnSignal switches level between its current value and slog.LevelDebug every time the process receives
+// one of signals, or SIGHUP if none are given. It stops listening when ctx is done.
+func ToggleDebugOnSignal(ctx context.Context, level *slog.LevelVar, signals ...os.Signal) {
+	if len(signals) == 0 {
+		signals = []os.Signal{syscall.SIGHUP}
+	}
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, signals...)
+
+	go func() {
+		defer signal.Stop(ch)
+		previous := level.Level()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ch:
+				if current := level.Level(); current != slog.LevelDebug {
+					previous = current
+					level.Set(slog.LevelDebug)
+				} else {
+					level.Set(previous)
+				}
+			}
+		}
+	}()
+}
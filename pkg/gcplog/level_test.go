@@ -0,0 +1,48 @@
+package gcplog_test
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mycujoo/go-stdlib/pkg/gcplog"
+	"github.com/mycujoo/go-stdlib/pkg/gcplog/internal/require"
+)
+
+func TestLevelHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		target         string
+		body           string
+		expectedStatus int
+		expectedLevel  slog.Level
+	}{
+		{"get", http.MethodGet, "/", "", http.StatusOK, slog.LevelInfo},
+		{"put query", http.MethodPut, "/?level=debug", "", http.StatusOK, slog.LevelDebug},
+		{"post body", http.MethodPost, "/", "WARN+2\n", http.StatusOK, slog.LevelWarn + 2},
+		{"invalid level", http.MethodPut, "/?level=loud", "", http.StatusBadRequest, slog.LevelInfo},
+		{"invalid method", http.MethodDelete, "/", "", http.StatusMethodNotAllowed, slog.LevelInfo},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var level slog.LevelVar
+			h := gcplog.LevelHandler(&level)
+
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body)))
+			body, err := io.ReadAll(rec.Body)
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedStatus, rec.Code)
+			require.Equal(t, tt.expectedLevel, level.Level())
+			if tt.expectedStatus == http.StatusOK {
+				require.Equal(t, tt.expectedLevel.String()+"\n", string(body))
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+//go:build unix
+
+package gcplog_test
+
+import (
+	"context"
+	"log/slog"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/mycujoo/go-stdlib/pkg/gcplog"
+	"github.com/mycujoo/go-stdlib/pkg/gcplog/internal/require"
+)
+
+func TestToggleDebugOnSignal(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var level slog.LevelVar
+	level.Set(slog.LevelWarn)
+	gcplog.ToggleDebugOnSignal(ctx, &level, syscall.SIGUSR1)
+
+	waitForLevel := func(expected slog.Level) {
+		t.Helper()
+		deadline := time.Now().Add(time.Second)
+		for level.Level() != expected && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		require.Equal(t, expected, level.Level())
+	}
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+	waitForLevel(slog.LevelDebug)
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+	waitForLevel(slog.LevelWarn)
+}
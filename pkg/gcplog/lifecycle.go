@@ -0,0 +1,44 @@
+package gcplog
+
+import (
+	"errors"
+	"io"
+)
+
+var _ io.Closer = (*Handler)(nil)
+
+// flusher is implemented by writers that buffer lines, e.g. *AsyncWriter and *bufio.Writer.
+type flusher interface {
+	Flush() error
+}
+
+// Flush writes the lines buffered by the writers of the handler, if they implement Flush() error, e.g. *AsyncWriter.
+func (h *Handler) Flush() error {
+	var err error
+	for _, w := range h.writers {
+		if f, ok := w.(flusher); ok {
+			err = errors.Join(err, f.Flush())
+		}
+	}
+	return err
+}
+
+// Close flushes the writers of the handler and closes those implementing both Flush() error and io.Closer, e.g.
+// *AsyncWriter, so that buffered lines are not lost on shutdown. Other writers, e.g. os.Stderr, are not closed.
+// The writers are shared by the handlers returned by WithAttrs and WithGroup, so Close should be called once, after
+// logging has stopped.
+func (h *Handler) Close() error {
+	var err error
+	for _, w := range h.writers {
+		switch w := w.(type) {
+		case interface {
+			flusher
+			io.Closer
+		}:
+			err = errors.Join(err, w.Close())
+		case flusher:
+			err = errors.Join(err, w.Flush())
+		}
+	}
+	return err
+}
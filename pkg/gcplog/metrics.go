@@ -0,0 +1,141 @@
+package gcplog
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+)
+
+// Metrics receives counts of the entries handled by a Handler, e.g. to alert on spikes of errors or log volume.
+// Implementations must be safe for concurrent use. Counters implements Metrics.
+type Metrics interface {
+	// Entry is called for every entry written, with its level.
+	Entry(level slog.Level)
+	// Bytes is called with the size of every line written.
+	Bytes(n int)
+	// Error is called for every error returned by Handle.
+	Error(err error)
+	// Dropped is called for every entry dropped by sampling, with its level.
+	Dropped(level slog.Level)
+}
+
+// severities are the GCP severities written by Handler, in increasing order.
+var severities = [...]string{
+	severityDebug, severityInfo, severityWarn, severityError, severityCritical, severityAlert, severityEmergency,
+}
+
+// severityIndex returns the index in severities of the severity of level.
+func severityIndex(level slog.Level) int {
+	switch {
+	case level >= LevelEmergency:
+		return 6
+	case level >= LevelAlert:
+		return 5
+	case level >= LevelCritical:
+		return 4
+	case level >= slog.LevelError:
+		return 3
+	case level >= slog.LevelWarn:
+		return 2
+	case level >= slog.LevelInfo:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func severity(level slog.Level) string {
+	return severities[severityIndex(level)]
+}
+
+// Counters is a Metrics counting entries by severity, bytes, errors and dropped entries.
+// It implements expvar.Var, so it can be published with expvar.Publish.
+type Counters struct {
+	entries [len(severities)]atomic.Int64
+	bytes   atomic.Int64
+	errors  atomic.Int64
+	dropped [len(severities)]atomic.Int64
+}
+
+var _ Metrics = (*Counters)(nil)
+
+func (c *Counters) Entry(level slog.Level) {
+	c.entries[severityIndex(level)].Add(1)
+}
+
+func (c *Counters) Bytes(n int) {
+	c.bytes.Add(int64(n))
+}
+
+func (c *Counters) Error(error) {
+	c.errors.Add(1)
+}
+
+func (c *Counters) Dropped(level slog.Level) {
+	c.dropped[severityIndex(level)].Add(1)
+}
+
+// Entries returns the number of entries written with the given GCP severity, e.g. "ERROR".
+func (c *Counters) Entries(severity string) int64 {
+	for i, s := range severities {
+		if s == severity {
+			return c.entries[i].Load()
+		}
+	}
+	return 0
+}
+
+// BytesWritten returns the number of bytes written.
+func (c *Counters) BytesWritten() int64 {
+	return c.bytes.Load()
+}
+
+// Errors returns the number of errors returned by Handle.
+func (c *Counters) Errors() int64 {
+	return c.errors.Load()
+}
+
+// DroppedEntries returns the number of entries with the given GCP severity dropped by sampling.
+func (c *Counters) DroppedEntries(severity string) int64 {
+	for i, s := range severities {
+		if s == severity {
+			return c.dropped[i].Load()
+		}
+	}
+	return 0
+}
+
+// String returns the counters as JSON, as required by expvar.Var.
+func (c *Counters) String() string {
+	var sb strings.Builder
+	writeCounts := func(counts *[len(severities)]atomic.Int64) {
+		sb.WriteByte('{')
+		for i, s := range severities {
+			if i > 0 {
+				sb.WriteByte(',')
+			}
+			fmt.Fprintf(&sb, "%q:%d", s, counts[i].Load())
+		}
+		sb.WriteByte('}')
+	}
+	sb.WriteString(`{"entries":`)
+	writeCounts(&c.entries)
+	fmt.Fprintf(&sb, `,"bytes":%d,"errors":%d,"dropped":`, c.bytes.Load(), c.errors.Load())
+	writeCounts(&c.dropped)
+	sb.WriteByte('}')
+	return sb.String()
+}
+
+// meteredWriter reports the size of the lines written to w.
+type meteredWriter struct {
+	w       io.Writer
+	metrics Metrics
+}
+
+func (w *meteredWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.metrics.Bytes(n)
+	return n, err
+}
@@ -0,0 +1,5 @@
+//go:build !race
+
+package gcplog_test
+
+const raceEnabled = false
@@ -0,0 +1,249 @@
+// Package otlplog provides a gcplog.Handler that emits records with the OpenTelemetry logs SDK, e.g. exported with
+// OTLP over gRPC to a collector, so that code logging with gcplog can move to collector-based pipelines unchanged.
+//
+// It is a separate module, so that gcplog does not depend on the logs SDK and gRPC.
+package otlplog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/mycujoo/go-stdlib/pkg/gcplog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Fields of the OTLP schema of gcplog.Handler that are fields of records, instead of attributes.
+const (
+	fieldBody           = "body"
+	fieldTimestamp      = "timestamp"
+	fieldSeverityText   = "severity_text"
+	fieldSeverityNumber = "severity_number"
+	fieldTraceID        = "trace_id"
+	fieldSpanID         = "span_id"
+	fieldTraceFlags     = "trace_flags"
+	fieldScopeName      = "scope.name"
+)
+
+// defaultScope is the name of the logger of records without scope.
+const defaultScope = "github.com/mycujoo/go-stdlib/pkg/gcplog"
+
+// NewHandler returns a gcplog.Handler that emits records with the loggers of provider.
+// Records have the fields of the OTLP schema of gcplog.Handler: the body, timestamp, severity and trace context are
+// fields of the records, the scope set with gcplog.WithScope is the name of their logger, and the other fields are
+// attributes, with the same keys as in the JSON entries of gcplog. The schema of opts is ignored and its ErrorWriter
+// is not used.
+//
+// Flush of the handler flushes provider, if it implements ForceFlush(context.Context) error, like the provider of the
+// SDK.
+func NewHandler(provider log.LoggerProvider, opts *gcplog.HandlerOptions) *gcplog.Handler {
+	return newHandler(&writer{provider: provider}, opts)
+}
+
+// New returns a handler exporting records with OTLP over gRPC, batched by the logs SDK. The exporter is configured by
+// exporterOpts and the OTEL_EXPORTER_OTLP_* environment variables, and the resource has the service name and version
+// of opts. Close of the handler exports the remaining records and shuts the exporter down.
+func New(ctx context.Context, opts *gcplog.HandlerOptions, exporterOpts ...otlploggrpc.Option) (*gcplog.Handler, error) {
+	exporter, err := otlploggrpc.New(ctx, exporterOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("otlplog: %w", err)
+	}
+
+	res := resource.Default()
+	if opts != nil && opts.ServiceName != "" {
+		attrs := []attribute.KeyValue{attribute.String("service.name", opts.ServiceName)}
+		if opts.ServiceVersion != "" {
+			attrs = append(attrs, attribute.String("service.version", opts.ServiceVersion))
+		}
+		if res, err = resource.Merge(res, resource.NewSchemaless(attrs...)); err != nil {
+			return nil, fmt.Errorf("otlplog: %w", err)
+		}
+	}
+
+	provider := sdklog.NewLoggerProvider(
+		sdklog.WithProcessor(sdklog.NewBatchProcessor(exporter)),
+		sdklog.WithResource(res),
+	)
+	return newHandler(&writer{provider: provider, owned: true}, opts), nil
+}
+
+func newHandler(w *writer, opts *gcplog.HandlerOptions) *gcplog.Handler {
+	o := gcplog.HandlerOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	o.Schema = gcplog.SchemaOTLP
+	o.ErrorWriter = nil
+	return gcplog.NewHandler(w, &o)
+}
+
+// writer emits the lines of gcplog.Handler as records.
+type writer struct {
+	provider log.LoggerProvider
+	// shut down the provider on Close
+	owned   bool
+	loggers sync.Map // scope name -> log.Logger
+}
+
+// Write converts the line p to a record and emits it. It is called once per line by gcplog.Handler.
+func (w *writer) Write(p []byte) (int, error) {
+	ctx, scope, record, err := recordFromJSON(p)
+	if err != nil {
+		return 0, err
+	}
+	w.logger(scope).Emit(ctx, record)
+	return len(p), nil
+}
+
+func (w *writer) logger(scope string) log.Logger {
+	if scope == "" {
+		scope = defaultScope
+	}
+	if l, ok := w.loggers.Load(scope); ok {
+		return l.(log.Logger)
+	}
+	l, _ := w.loggers.LoadOrStore(scope, w.provider.Logger(scope))
+	return l.(log.Logger)
+}
+
+// Flush exports the records buffered by the provider.
+func (w *writer) Flush() error {
+	if f, ok := w.provider.(interface{ ForceFlush(context.Context) error }); ok {
+		return f.ForceFlush(context.Background())
+	}
+	return nil
+}
+
+// Close exports the buffered records and shuts the provider down, if it was created by New.
+func (w *writer) Close() error {
+	if s, ok := w.provider.(interface{ Shutdown(context.Context) error }); ok && w.owned {
+		return s.Shutdown(context.Background())
+	}
+	return w.Flush()
+}
+
+// recordFromJSON converts a line of the OTLP schema of gcplog.Handler to a record, the context carrying its span and
+// the name of its scope.
+func recordFromJSON(line []byte) (context.Context, string, log.Record, error) {
+	ctx := context.Background()
+	var record log.Record
+
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+	var fields map[string]any
+	if err := dec.Decode(&fields); err != nil {
+		return ctx, "", record, fmt.Errorf("otlplog: invalid line: %w", err)
+	}
+
+	if body, ok := fields[fieldBody].(string); ok {
+		record.SetBody(log.StringValue(body))
+		delete(fields, fieldBody)
+	}
+
+	switch ts := fields[fieldTimestamp].(type) {
+	case string:
+		t, err := time.Parse(time.RFC3339Nano, ts)
+		if err != nil {
+			return ctx, "", record, fmt.Errorf("otlplog: invalid timestamp: %w", err)
+		}
+		record.SetTimestamp(t)
+		delete(fields, fieldTimestamp)
+	case map[string]any:
+		// The format of gcplog.TimestampSecondsNanos.
+		seconds, _ := ts["seconds"].(json.Number).Int64()
+		nanos, _ := ts["nanos"].(json.Number).Int64()
+		record.SetTimestamp(time.Unix(seconds, nanos))
+		delete(fields, fieldTimestamp)
+	}
+
+	if text, ok := fields[fieldSeverityText].(string); ok {
+		record.SetSeverityText(text)
+		delete(fields, fieldSeverityText)
+	}
+	if n, ok := fields[fieldSeverityNumber].(json.Number); ok {
+		severity, _ := n.Int64()
+		record.SetSeverity(log.Severity(severity))
+		delete(fields, fieldSeverityNumber)
+	}
+
+	if sc := spanContext(fields); sc.IsValid() {
+		ctx = trace.ContextWithSpanContext(ctx, sc)
+	}
+
+	scope, _ := fields[fieldScopeName].(string)
+	delete(fields, fieldScopeName)
+
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		record.AddAttributes(log.KeyValue{Key: key, Value: value(fields[key])})
+	}
+	return ctx, scope, record, nil
+}
+
+// spanContext returns the span context of the trace fields, and removes them from fields.
+func spanContext(fields map[string]any) trace.SpanContext {
+	traceID, _ := fields[fieldTraceID].(string)
+	spanID, _ := fields[fieldSpanID].(string)
+	flags, _ := fields[fieldTraceFlags].(string)
+	delete(fields, fieldTraceID)
+	delete(fields, fieldSpanID)
+	delete(fields, fieldTraceFlags)
+
+	var config trace.SpanContextConfig
+	config.TraceID, _ = trace.TraceIDFromHex(traceID)
+	config.SpanID, _ = trace.SpanIDFromHex(spanID)
+	if f, err := strconv.ParseUint(flags, 16, 8); err == nil {
+		config.TraceFlags = trace.TraceFlags(f)
+	}
+	return trace.NewSpanContext(config)
+}
+
+// value converts a JSON value to a log value.
+func value(v any) log.Value {
+	switch v := v.(type) {
+	case string:
+		return log.StringValue(v)
+	case bool:
+		return log.BoolValue(v)
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return log.Int64Value(i)
+		}
+		f, _ := v.Float64()
+		return log.Float64Value(f)
+	case []any:
+		values := make([]log.Value, 0, len(v))
+		for _, item := range v {
+			values = append(values, value(item))
+		}
+		return log.SliceValue(values...)
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		kvs := make([]log.KeyValue, 0, len(v))
+		for _, key := range keys {
+			kvs = append(kvs, log.KeyValue{Key: key, Value: value(v[key])})
+		}
+		return log.MapValue(kvs...)
+	default:
+		// null
+		return log.Value{}
+	}
+}
@@ -0,0 +1,104 @@
+package otlplog_test
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mycujoo/go-stdlib/pkg/gcplog"
+	"github.com/mycujoo/go-stdlib/pkg/gcplog/otlplog"
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type recordingExporter struct {
+	mu      sync.Mutex
+	records []sdklog.Record
+}
+
+func (e *recordingExporter) Export(_ context.Context, records []sdklog.Record) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, r := range records {
+		e.records = append(e.records, r.Clone())
+	}
+	return nil
+}
+
+func (e *recordingExporter) Shutdown(context.Context) error   { return nil }
+func (e *recordingExporter) ForceFlush(context.Context) error { return nil }
+
+func TestHandler(t *testing.T) {
+	exporter := &recordingExporter{}
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(sdklog.NewSimpleProcessor(exporter)))
+	h := otlplog.NewHandler(provider, &gcplog.HandlerOptions{
+		AddSource: true,
+		Labels:    map[string]string{"env": "test"},
+		Scope:     "billing",
+	})
+	logger := slog.New(h)
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
+	before := time.Now()
+	logger.WarnContext(ctx, "slow", "user", slog.GroupValue(slog.String("id", "42")), "duration", 1.5, "count", 3)
+
+	if len(exporter.records) != 1 {
+		t.Fatalf("unexpected records: %v", exporter.records)
+	}
+	r := exporter.records[0]
+	if r.Body().AsString() != "slow" {
+		t.Errorf("unexpected body: %v", r.Body())
+	}
+	if r.Severity() != log.SeverityWarn || r.SeverityText() != "WARNING" {
+		t.Errorf("unexpected severity: %v %s", r.Severity(), r.SeverityText())
+	}
+	if r.Timestamp().Before(before.Add(-time.Second)) || r.Timestamp().After(time.Now()) {
+		t.Errorf("unexpected timestamp: %v", r.Timestamp())
+	}
+	if r.TraceID() != traceID || r.SpanID() != spanID || !r.TraceFlags().IsSampled() {
+		t.Errorf("unexpected trace: %s %s %s", r.TraceID(), r.SpanID(), r.TraceFlags())
+	}
+	if r.InstrumentationScope().Name != "billing" {
+		t.Errorf("unexpected scope: %v", r.InstrumentationScope())
+	}
+
+	attrs := map[string]log.Value{}
+	r.WalkAttributes(func(kv log.KeyValue) bool {
+		attrs[kv.Key] = kv.Value
+		return true
+	})
+	if v := attrs["count"]; v.Kind() != log.KindInt64 || v.AsInt64() != 3 {
+		t.Errorf("unexpected count: %v", v)
+	}
+	if v := attrs["duration"]; v.Kind() != log.KindFloat64 || v.AsFloat64() != 1.5 {
+		t.Errorf("unexpected duration: %v", v)
+	}
+	if v := attrs["user"]; !v.Equal(log.MapValue(log.String("id", "42"))) {
+		t.Errorf("unexpected user: %v", v)
+	}
+	if v := attrs["logging.googleapis.com/labels"]; !v.Equal(log.MapValue(log.String("env", "test"))) {
+		t.Errorf("unexpected labels: %v", v)
+	}
+	if v := attrs["code.function"]; !strings.HasSuffix(v.AsString(), "TestHandler") {
+		t.Errorf("unexpected code.function: %v", v)
+	}
+	for _, key := range []string{"body", "timestamp", "severity_text", "trace_id", "scope.name"} {
+		if _, ok := attrs[key]; ok {
+			t.Errorf("unexpected attribute %s", key)
+		}
+	}
+
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,43 @@
+package gcplog
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+
+	"github.com/jussi-kalliokoski/goldjson"
+)
+
+const fieldProcess = "process"
+
+// Environment variables of the container, usually set through the Kubernetes downward API.
+const (
+	podNameKey       = "POD_NAME"
+	podNamespaceKey  = "POD_NAMESPACE"
+	containerNameKey = "CONTAINER_NAME"
+)
+
+// processAttrs returns the attributes describing the current process. Unknown values are omitted.
+func processAttrs() []slog.Attr {
+	attrs := []slog.Attr{slog.Int("pid", os.Getpid())}
+	if hostname, err := os.Hostname(); err == nil {
+		attrs = append(attrs, slog.String("hostname", hostname))
+	}
+	for _, kv := range [][2]string{
+		{"container", containerNameKey},
+		{"pod", podNameKey},
+		{"namespace", podNamespaceKey},
+	} {
+		if v := os.Getenv(kv[1]); v != "" {
+			attrs = append(attrs, slog.String(kv[0], v))
+		}
+	}
+	return attrs
+}
+
+// newProcessFields encodes the process group once, as it is the same for every entry.
+func newProcessFields() (*goldjson.StaticFields, error) {
+	staticFields, w := goldjson.NewStaticFields()
+	err := addAttr(w, slog.Attr{Key: fieldProcess, Value: slog.GroupValue(processAttrs()...)}, &attrEncoder{})
+	return staticFields, errors.Join(err, w.End())
+}
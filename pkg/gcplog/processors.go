@@ -0,0 +1,42 @@
+package gcplog
+
+import (
+	"context"
+	"log/slog"
+)
+
+// AttrProcessor transforms an attribute before it is encoded, e.g. to hash user IDs. Returning an empty slog.Attr
+// drops the attribute. Attributes of groups are processed after the group itself.
+//
+// Values are resolved before processing. Attributes added with slog.Logger.With are processed once, with
+// context.Background, as there is no context yet.
+type AttrProcessor func(ctx context.Context, a slog.Attr) slog.Attr
+
+// process applies the attribute processors to a, and reports false if a was dropped.
+func (ae *attrEncoder) process(ctx context.Context, a slog.Attr) (slog.Attr, bool) {
+	if len(ae.processors) == 0 {
+		return a, true
+	}
+	return ae.processResolved(ctx, resolveAttr(a, 0), 0)
+}
+
+func (ae *attrEncoder) processResolved(ctx context.Context, a slog.Attr, depth int) (slog.Attr, bool) {
+	for _, p := range ae.processors {
+		a = p(ctx, a)
+		if a.Equal(slog.Attr{}) {
+			return a, false
+		}
+	}
+	a = resolveAttr(a, depth)
+	if a.Value.Kind() != slog.KindGroup {
+		return a, true
+	}
+	attrs := a.Value.Group()
+	processed := make([]slog.Attr, 0, len(attrs))
+	for _, ga := range attrs {
+		if ga, ok := ae.processResolved(ctx, ga, depth+1); ok {
+			processed = append(processed, ga)
+		}
+	}
+	return slog.Attr{Key: a.Key, Value: slog.GroupValue(processed...)}, true
+}
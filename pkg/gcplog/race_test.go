@@ -0,0 +1,6 @@
+//go:build race
+
+package gcplog_test
+
+// raceEnabled reports whether tests run with the race detector, which makes sync.Pool drop items and allocate.
+const raceEnabled = true
@@ -0,0 +1,13 @@
+package gcplog
+
+import (
+	"encoding/json"
+	"log/slog"
+)
+
+// RawJSON returns an attribute with pre-encoded JSON, e.g. a webhook body or a message encoded with protojson, that
+// is written to the entry as JSON instead of as a string or base64 encoded bytes. Attributes with a json.RawMessage
+// value are written the same way.
+func RawJSON(key string, data []byte) slog.Attr {
+	return slog.Any(key, json.RawMessage(data))
+}
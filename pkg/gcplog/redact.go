@@ -0,0 +1,83 @@
+package gcplog
+
+import (
+	"bytes"
+	"encoding/json"
+	"path"
+	"strings"
+)
+
+const redactedValue = "[REDACTED]"
+
+// redactor replaces values of attributes whose keys match HandlerOptions.RedactKeys.
+// A nil redactor matches nothing.
+type redactor struct {
+	exact    map[string]struct{}
+	patterns []string
+}
+
+func newRedactor(keys []string) *redactor {
+	if len(keys) == 0 {
+		return nil
+	}
+	r := &redactor{exact: make(map[string]struct{})}
+	for _, k := range keys {
+		k = strings.ToLower(k)
+		if strings.ContainsAny(k, `*?[\`) {
+			r.patterns = append(r.patterns, k)
+			continue
+		}
+		r.exact[k] = struct{}{}
+	}
+	return r
+}
+
+// match reports whether the value of key should be redacted. Keys are matched case-insensitively.
+func (r *redactor) match(key string) bool {
+	if r == nil {
+		return false
+	}
+	key = strings.ToLower(key)
+	if _, ok := r.exact[key]; ok {
+		return true
+	}
+	for _, p := range r.patterns {
+		if ok, _ := path.Match(p, key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// redactValue returns v with the values of matching object keys replaced, by round-tripping it through JSON.
+func (r *redactor) redactValue(v any) (any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	var decoded any
+	if err := d.Decode(&decoded); err != nil {
+		return nil, err
+	}
+	return r.redactJSON(decoded), nil
+}
+
+func (r *redactor) redactJSON(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, e := range v {
+			if r.match(k) {
+				v[k] = redactedValue
+				continue
+			}
+			v[k] = r.redactJSON(e)
+		}
+	case []any:
+		for i, e := range v {
+			v[i] = r.redactJSON(e)
+		}
+	}
+	return v
+}
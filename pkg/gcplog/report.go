@@ -1,13 +1,24 @@
 package gcplog
 
 import (
+	"bytes"
+	"context"
+	"fmt"
 	"log/slog"
 	"runtime"
+	"runtime/debug"
 	"strconv"
+	"strings"
+	"time"
 )
 
 const fieldContext = "context"
 const fieldReportLocation = "reportLocation"
+const fieldStackTrace = "stack_trace"
+const fieldType = "@type"
+
+// reportedErrorEventType makes Error Reporting analyze an entry regardless of the content of its message.
+const reportedErrorEventType = "type.googleapis.com/google.devtools.clouderrorreporting.v1beta1.ReportedErrorEvent"
 
 // NewReportContext creates a new report context.
 // see: https://cloud.google.com/error-reporting/docs/formatting-error-messages
@@ -23,3 +34,84 @@ func NewReportContext(pc uintptr) slog.Attr {
 		),
 	)
 }
+
+// stackTrace formats the stack of the current goroutine like debug.Stack, starting at the frame of pc.
+// If pc is not part of the current stack, e.g. because the record was created elsewhere, only the frame of pc is
+// included.
+func stackTrace(pc uintptr) string {
+	var sb strings.Builder
+
+	// The first line of runtime.Stack is the goroutine header, e.g. "goroutine 1 [running]:".
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	header, _, _ := bytes.Cut(buf, []byte("\n"))
+	sb.Write(header)
+	sb.WriteByte('\n')
+
+	pcs := make([]uintptr, 64)
+	pcs = pcs[:runtime.Callers(1, pcs)]
+	start := -1
+	for i, p := range pcs {
+		if p == pc {
+			start = i
+			break
+		}
+	}
+	if start >= 0 {
+		pcs = pcs[start:]
+	} else {
+		pcs = []uintptr{pc}
+	}
+
+	fs := runtime.CallersFrames(pcs)
+	for {
+		f, more := fs.Next()
+		if f.Function != "" {
+			fmt.Fprintf(&sb, "%s(...)\n\t%s:%d +0x%x\n", f.Function, f.File, f.Line, f.PC-f.Entry)
+		}
+		if !more {
+			break
+		}
+	}
+	return sb.String()
+}
+
+// RecoverAndReport recovers a panic and logs it with slog.Default at slog.LevelError, with the stack of the panic.
+// With a Handler reporting errors, the entry is reported to Error Reporting and grouped by the panicking location.
+// It must be deferred directly, and the panic is not propagated:
+//
+//	go func() {
+//		defer gcplog.RecoverAndReport(ctx)
+//		work(ctx)
+//	}()
+func RecoverAndReport(ctx context.Context) {
+	v := recover()
+	if v == nil {
+		return
+	}
+	logger := slog.Default()
+	if !logger.Enabled(ctx, slog.LevelError) {
+		return
+	}
+
+	msg := fmt.Sprintf("panic: %v", v)
+	r := slog.NewRecord(time.Now(), slog.LevelError, msg, panicPC())
+	r.AddAttrs(slog.String(fieldStackTrace, msg+"\n\n"+string(debug.Stack())))
+	if err, ok := v.(error); ok {
+		r.AddAttrs(Error(err))
+	}
+	_ = logger.Handler().Handle(ctx, r)
+}
+
+// panicPC returns the program counter of the function that panicked, when called from a deferred function.
+func panicPC() uintptr {
+	pcs := make([]uintptr, 64)
+	pcs = pcs[:runtime.Callers(2, pcs)]
+	for i, pc := range pcs {
+		f, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+		if f.Function == "runtime.gopanic" && i+1 < len(pcs) {
+			return pcs[i+1]
+		}
+	}
+	return 0
+}
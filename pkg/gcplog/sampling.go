@@ -0,0 +1,90 @@
+package gcplog
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const fieldSampled = "sampled"
+
+// maxSamplingKeys is the number of messages tracked by the sampler before expired ones are removed.
+const maxSamplingKeys = 4096
+
+// SamplingOptions configures sampling of entries with identical level and message, to prevent tight loops from
+// flooding the logs. Within every Interval, the First entries are logged, and after that every Thereafter-th entry.
+// Logged entries following dropped ones get a `sampled` attribute with the number of entries dropped in between.
+type SamplingOptions struct {
+	// Interval after which counting starts over. Defaults to one second.
+	Interval time.Duration
+	// Number of entries logged per interval before sampling starts.
+	First int
+	// Log every Thereafter-th entry after the first ones. If zero, all further entries in the interval are dropped.
+	Thereafter int
+}
+
+type sampler struct {
+	opts     SamplingOptions
+	mu       sync.Mutex
+	counters map[samplingKey]*samplingCounter
+}
+
+type samplingKey struct {
+	level   slog.Level
+	message string
+}
+
+type samplingCounter struct {
+	start   time.Time
+	count   int
+	dropped int64
+}
+
+func newSampler(opts SamplingOptions) *sampler {
+	if opts.Interval <= 0 {
+		opts.Interval = time.Second
+	}
+	return &sampler{
+		opts:     opts,
+		counters: make(map[samplingKey]*samplingCounter),
+	}
+}
+
+// sample reports whether the record should be logged, and if so, how many records with the same level and message
+// were dropped before it.
+func (s *sampler) sample(r *slog.Record) (bool, int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := samplingKey{level: r.Level, message: r.Message}
+	c, ok := s.counters[key]
+	if !ok {
+		if len(s.counters) >= maxSamplingKeys {
+			s.removeExpired(r.Time)
+		}
+		c = &samplingCounter{start: r.Time}
+		s.counters[key] = c
+	}
+	if r.Time.Sub(c.start) >= s.opts.Interval {
+		c.start = r.Time
+		c.count = 0
+	}
+	c.count++
+
+	n := c.count - s.opts.First
+	if n > 0 && (s.opts.Thereafter <= 0 || n%s.opts.Thereafter != 0) {
+		c.dropped++
+		return false, 0
+	}
+	dropped := c.dropped
+	c.dropped = 0
+	return true, dropped
+}
+
+func (s *sampler) removeExpired(now time.Time) {
+	for key, c := range s.counters {
+		if now.Sub(c.start) >= s.opts.Interval && c.dropped == 0 {
+			delete(s.counters, key)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package gcplog
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+
+	"github.com/jussi-kalliokoski/goldjson"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SchemaMode selects the field names of the entries written by Handler.
+type SchemaMode int
+
+const (
+	// SchemaGCP writes entries in the GCP structured logging format.
+	SchemaGCP SchemaMode = iota
+	// SchemaOTLP writes entries with the field names of the OpenTelemetry log data model, e.g. for the filelog
+	// receiver of an OpenTelemetry collector: body, timestamp, severity_text, severity_number, trace_id, span_id,
+	// trace_flags, code.* for the source location, service.* for the service and scope.name for the scope.
+	// Labels, error reporting and other fields without an OpenTelemetry counterpart are written as with SchemaGCP.
+	SchemaOTLP
+)
+
+const (
+	fieldOTLPBody           = "body"
+	fieldOTLPTimestamp      = "timestamp"
+	fieldOTLPSeverityText   = "severity_text"
+	fieldOTLPSeverityNumber = "severity_number"
+	fieldOTLPTraceID        = "trace_id"
+	fieldOTLPSpanID         = "span_id"
+	fieldOTLPTraceFlags     = "trace_flags"
+	fieldOTLPCodeFilepath   = "code.filepath"
+	fieldOTLPCodeLineno     = "code.lineno"
+	fieldOTLPCodeFunction   = "code.function"
+	fieldOTLPServiceName    = "service.name"
+	fieldOTLPServiceVersion = "service.version"
+	fieldOTLPScopeName      = "scope.name"
+)
+
+func prepareOTLPKeys(encoder *goldjson.Encoder) {
+	for _, key := range []string{
+		fieldOTLPBody, fieldOTLPTimestamp, fieldOTLPSeverityText, fieldOTLPSeverityNumber,
+		fieldOTLPTraceID, fieldOTLPSpanID, fieldOTLPTraceFlags,
+		fieldOTLPCodeFilepath, fieldOTLPCodeLineno, fieldOTLPCodeFunction,
+		fieldOTLPServiceName, fieldOTLPServiceVersion, fieldOTLPScopeName,
+	} {
+		encoder.PrepareKey(key)
+	}
+}
+
+// otlpSeverityNumber maps a level to a SeverityNumber of the OpenTelemetry log data model.
+func otlpSeverityNumber(level slog.Level) int64 {
+	switch {
+	case level >= LevelEmergency:
+		return 21 // FATAL
+	case level >= LevelAlert:
+		return 19 // ERROR3
+	case level >= LevelCritical:
+		return 18 // ERROR2
+	case level >= slog.LevelError:
+		return 17 // ERROR
+	case level >= slog.LevelWarn:
+		return 13 // WARN
+	case level >= slog.LevelInfo:
+		return 9 // INFO
+	default:
+		return 5 // DEBUG
+	}
+}
+
+func addOTLPSourceLocation(l *goldjson.LineWriter, r *slog.Record) {
+	fs := runtime.CallersFrames([]uintptr{r.PC})
+	f, _ := fs.Next()
+
+	l.AddString(fieldOTLPCodeFilepath, f.File)
+	l.AddInt64(fieldOTLPCodeLineno, int64(f.Line))
+	l.AddString(fieldOTLPCodeFunction, f.Function)
+}
+
+func addOTLPTrace(ctx context.Context, l *goldjson.LineWriter) {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		th, ok := traceHeaderFromContext(ctx)
+		if !ok {
+			return
+		}
+		l.AddString(fieldOTLPTraceID, th.traceID)
+		if th.spanID != "" {
+			l.AddString(fieldOTLPSpanID, th.spanID)
+		}
+		l.AddString(fieldOTLPTraceFlags, otlpTraceFlags(th.sampled))
+		return
+	}
+
+	l.AddString(fieldOTLPTraceID, sc.TraceID().String())
+	l.AddString(fieldOTLPSpanID, sc.SpanID().String())
+	l.AddString(fieldOTLPTraceFlags, otlpTraceFlags(sc.IsSampled()))
+}
+
+func otlpTraceFlags(sampled bool) string {
+	if sampled {
+		return "01"
+	}
+	return "00"
+}
+
+func addOTLPService(l *goldjson.LineWriter, name, version string) {
+	l.AddString(fieldOTLPServiceName, name)
+	if version != "" {
+		l.AddString(fieldOTLPServiceVersion, version)
+	}
+}
@@ -0,0 +1,36 @@
+package gcplog
+
+import (
+	"log/slog"
+
+	"github.com/jussi-kalliokoski/goldjson"
+)
+
+const fieldScope = "scope"
+
+// WithScope returns a handler that writes the name of a component, e.g. "ingest-worker" or "billing", in the scope
+// field of its entries, replacing HandlerOptions.Scope.
+func (h *Handler) WithScope(name string) *Handler {
+	clone := *h
+	clone.opts.Scope = name
+	return &clone
+}
+
+// WithScope returns a logger that writes the name of a component in the scope field of its entries when logging
+// through a *Handler, and as a scope attribute otherwise, e.g. with the console handler of NewAutoHandler:
+//
+//	logger := gcplog.WithScope(slog.Default(), "billing")
+func WithScope(logger *slog.Logger, name string) *slog.Logger {
+	if h, ok := logger.Handler().(*Handler); ok {
+		return slog.New(h.WithScope(name))
+	}
+	return logger.With(fieldScope, name)
+}
+
+func addScope(l *goldjson.LineWriter, name string, otlp bool) {
+	if otlp {
+		l.AddString(fieldOTLPScopeName, name)
+		return
+	}
+	l.AddString(fieldScope, name)
+}
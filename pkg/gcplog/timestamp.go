@@ -0,0 +1,35 @@
+package gcplog
+
+import (
+	"time"
+
+	"github.com/jussi-kalliokoski/goldjson"
+)
+
+const (
+	fieldTimestampObject  = "timestamp"
+	fieldTimestampSeconds = "seconds"
+	fieldTimestampNanos   = "nanos"
+)
+
+// TimestampFormat is the encoding of the timestamp of entries.
+type TimestampFormat int
+
+const (
+	// TimestampRFC3339Nano encodes the timestamp as a string in the "time" field, e.g. "2024-01-02T03:04:05.06Z".
+	TimestampRFC3339Nano TimestampFormat = iota
+	// TimestampSecondsNanos encodes the timestamp as an object with the seconds and nanoseconds since the Unix epoch in
+	// the "timestamp" field, e.g. {"seconds":1704164645,"nanos":60000000}, which does not depend on the time zone.
+	TimestampSecondsNanos
+)
+
+func addTimestamp(l *goldjson.LineWriter, key string, t time.Time, format TimestampFormat) {
+	if format == TimestampSecondsNanos {
+		l.StartRecord(key)
+		l.AddInt64(fieldTimestampSeconds, t.Unix())
+		l.AddInt64(fieldTimestampNanos, int64(t.Nanosecond()))
+		l.EndRecord()
+		return
+	}
+	_ = l.AddTime(key, t)
+}
@@ -0,0 +1,97 @@
+package gcplog
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type traceHeaderKey struct{}
+
+// traceHeader is a trace context parsed from a request header, used when there is no OpenTelemetry span in context.
+type traceHeader struct {
+	traceID string
+	spanID  string
+	sampled bool
+}
+
+// ContextWithTraceHeader returns a copy of ctx carrying the trace context of header, so that entries logged with it
+// are correlated with the trace in Cloud Logging without OpenTelemetry. The header can be either a W3C traceparent
+// header, e.g. "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", or an X-Cloud-Trace-Context header,
+// e.g. "105445aa7843bc8bf206b12000100000/1;o=1". An invalid header is ignored and ctx is returned as is.
+//
+// A span from OpenTelemetry in context takes precedence.
+func ContextWithTraceHeader(ctx context.Context, header string) context.Context {
+	th, ok := parseTraceparent(header)
+	if !ok {
+		th, ok = parseCloudTraceContext(header)
+	}
+	if !ok {
+		return ctx
+	}
+	return context.WithValue(ctx, traceHeaderKey{}, th)
+}
+
+func traceHeaderFromContext(ctx context.Context) (traceHeader, bool) {
+	th, ok := ctx.Value(traceHeaderKey{}).(traceHeader)
+	return th, ok
+}
+
+// parseTraceparent parses a W3C traceparent header: version-traceid-spanid-flags.
+func parseTraceparent(header string) (traceHeader, bool) {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || (parts[0] == "00" && len(parts) != 4) {
+		return traceHeader{}, false
+	}
+	traceID, spanID, flags := parts[1], parts[2], parts[3]
+	if !isHexID(traceID, 32) || !isHexID(spanID, 16) || len(flags) != 2 {
+		return traceHeader{}, false
+	}
+	f, err := strconv.ParseUint(flags, 16, 8)
+	if err != nil {
+		return traceHeader{}, false
+	}
+	return traceHeader{traceID: traceID, spanID: spanID, sampled: f&1 == 1}, true
+}
+
+// parseCloudTraceContext parses an X-Cloud-Trace-Context header: TRACE_ID/SPAN_ID;o=OPTIONS,
+// where SPAN_ID is a decimal number and both SPAN_ID and OPTIONS are optional.
+func parseCloudTraceContext(header string) (traceHeader, bool) {
+	header = strings.TrimSpace(header)
+	rest, options, _ := strings.Cut(header, ";")
+	traceID, spanID, hasSpan := strings.Cut(rest, "/")
+	traceID = strings.ToLower(traceID)
+	if !isHexID(traceID, 32) {
+		return traceHeader{}, false
+	}
+	th := traceHeader{traceID: traceID, sampled: options == "o=1"}
+	if hasSpan && spanID != "" {
+		id, err := strconv.ParseUint(spanID, 10, 64)
+		if err != nil {
+			return traceHeader{}, false
+		}
+		if id != 0 {
+			th.spanID = fmt.Sprintf("%016x", id)
+		}
+	}
+	return th, true
+}
+
+// isHexID reports whether id is a non-zero lowercase hex ID of length n.
+func isHexID(id string, n int) bool {
+	if len(id) != n || strings.ToLower(id) != id {
+		return false
+	}
+	b, err := hex.DecodeString(id)
+	if err != nil {
+		return false
+	}
+	for _, c := range b {
+		if c != 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,186 @@
+package bleve
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blevesearch/bleve/v2/search/query"
+	"github.com/mycujoo/go-stdlib/pkg/kqlfilter"
+)
+
+type QueryGenerator struct {
+	validateFieldName func(name string) error
+}
+
+func NewQueryGenerator(options ...Option) *QueryGenerator {
+	g := &QueryGenerator{validateFieldName: defaultFieldNameValidator}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Option is a function that configures a query generator.
+type Option func(*QueryGenerator)
+
+// WithFieldValidator allows checking incoming field names.
+// This can be used to prevent users from querying fields that they are not allowed to query.
+// Example usage:
+//
+//	WithFieldValidator(func(name string) error {
+//		if !allowedFields[name] {
+//			return fmt.Errorf("field %s is not allowed", name)
+//		}
+//		return nil
+//	})
+func WithFieldValidator(fieldValidator func(name string) error) Option {
+	return func(g *QueryGenerator) {
+		g.validateFieldName = fieldValidator
+	}
+}
+
+// ConvertAST converts a KQL AST to a Bleve query.
+func (q *QueryGenerator) ConvertAST(root kqlfilter.Node) (query.Query, error) {
+	return q.convertNodeToQuery(root, "")
+}
+
+func (q *QueryGenerator) convertNodeToQuery(node kqlfilter.Node, prefix string) (query.Query, error) {
+	switch n := node.(type) {
+	case *kqlfilter.AndNode:
+		clauses, err := q.convertNodes(n.Nodes, prefix)
+		if err != nil {
+			return nil, err
+		}
+		return query.NewConjunctionQuery(clauses), nil
+	case *kqlfilter.OrNode:
+		clauses, err := q.convertNodes(n.Nodes, prefix)
+		if err != nil {
+			return nil, err
+		}
+		return query.NewDisjunctionQuery(clauses), nil
+	case *kqlfilter.NotNode:
+		bq, err := q.convertNodeToQuery(n.Expr, prefix)
+		if err != nil {
+			return nil, err
+		}
+		// A boolean query with only must not clauses does not match anything,
+		// so match all documents except the negated ones.
+		return query.NewBooleanQuery([]query.Query{query.NewMatchAllQuery()}, nil, []query.Query{bq}), nil
+	case *kqlfilter.IsNode:
+		id := prefix + n.Identifier
+
+		nested, ok := n.Value.(*kqlfilter.NestedNode)
+		if ok {
+			// Transform x:{y:z} syntax.
+			// Prefix all identifiers with the identifier of the parent node,
+			// so it becomes x.y:z
+			return q.convertNodeToQuery(nested.Expr, id+".")
+		}
+
+		if err := q.validateFieldName(id); err != nil {
+			return nil, fmt.Errorf("%s: %w", id, err)
+		}
+
+		or, ok := n.Value.(*kqlfilter.OrNode)
+		if ok {
+			// Transform x:(y or z) syntax.
+			var clauses []query.Query
+			// Check that all children are literals
+			for _, child := range or.Nodes {
+				lit, ok := child.(*kqlfilter.LiteralNode)
+				if !ok {
+					return nil, fmt.Errorf("%s: invalid syntax", id)
+				}
+				clauses = append(clauses, convertLiteral(id, lit))
+			}
+			return query.NewDisjunctionQuery(clauses), nil
+		}
+
+		lit, ok := n.Value.(*kqlfilter.LiteralNode)
+		if !ok {
+			return nil, fmt.Errorf("%s: expected literal node", id)
+		}
+		return convertLiteral(id, lit), nil
+	case *kqlfilter.RangeNode:
+		id := prefix + n.Identifier
+
+		if err := q.validateFieldName(id); err != nil {
+			return nil, fmt.Errorf("%s: %w", id, err)
+		}
+
+		lit, ok := n.Value.(*kqlfilter.LiteralNode)
+		if !ok {
+			return nil, fmt.Errorf("%s: expected literal node", id)
+		}
+		rq, err := convertRangeNode(n.Operator, lit)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", id, err)
+		}
+		rq.SetField(id)
+		return rq, nil
+	default:
+		return nil, fmt.Errorf("unexpected node type: %T", n)
+	}
+}
+
+func (q *QueryGenerator) convertNodes(nodes []kqlfilter.Node, prefix string) ([]query.Query, error) {
+	clauses := make([]query.Query, 0, len(nodes))
+	for _, child := range nodes {
+		cq, err := q.convertNodeToQuery(child, prefix)
+		if err != nil {
+			return nil, err
+		}
+		clauses = append(clauses, cq)
+	}
+	return clauses, nil
+}
+
+// convertLiteral creates a term query, or a prefix query if the literal ends with a wildcard.
+func convertLiteral(id string, lit *kqlfilter.LiteralNode) query.Query {
+	if strings.HasSuffix(lit.Value, "*") {
+		pq := query.NewPrefixQuery(strings.TrimSuffix(lit.Value, "*"))
+		pq.SetField(id)
+		return pq
+	}
+	tq := query.NewTermQuery(lit.Value)
+	tq.SetField(id)
+	return tq
+}
+
+type fieldableQuery interface {
+	query.Query
+	SetField(f string)
+}
+
+func convertRangeNode(op kqlfilter.RangeOperator, lit *kqlfilter.LiteralNode) (fieldableQuery, error) {
+	inclusive := op == kqlfilter.RangeOperatorLte || op == kqlfilter.RangeOperatorGte
+	lower := op == kqlfilter.RangeOperatorGt || op == kqlfilter.RangeOperatorGte
+
+	// Here we check the type of the literal node, and then we can create the correct range query.
+	fVal, err := strconv.ParseFloat(lit.Value, 64)
+	if err == nil {
+		if lower {
+			return query.NewNumericRangeInclusiveQuery(&fVal, nil, &inclusive, nil), nil
+		}
+		return query.NewNumericRangeInclusiveQuery(nil, &fVal, nil, &inclusive), nil
+	}
+
+	// It is not a number, so we check if it is a date.
+	tVal, err := time.Parse(time.RFC3339, lit.Value)
+	if err != nil {
+		return nil, errors.New("expected number or date literal")
+	}
+	if lower {
+		return query.NewDateRangeInclusiveQuery(tVal, time.Time{}, &inclusive, nil), nil
+	}
+	return query.NewDateRangeInclusiveQuery(time.Time{}, tVal, nil, &inclusive), nil
+}
+
+func defaultFieldNameValidator(_ string) error {
+	return nil
+}
@@ -0,0 +1,100 @@
+package bleve
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mycujoo/go-stdlib/pkg/kqlfilter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertNodeToQuery(t *testing.T) {
+	testCases := []struct {
+		name              string
+		input             string
+		expectedError     error
+		expectedQueryJSON string
+	}{
+		{
+			name:              "simple equality",
+			input:             "type_id:team",
+			expectedQueryJSON: `{"term":"team","field":"type_id"}`,
+		},
+		{
+			name:              "multiple values for same field",
+			input:             "type_id:(team OR player)",
+			expectedQueryJSON: `{"disjuncts":[{"term":"team","field":"type_id"},{"term":"player","field":"type_id"}],"min":0}`,
+		},
+		{
+			name:              "prefix",
+			input:             "fields.name:Aj*",
+			expectedQueryJSON: `{"prefix":"Aj","field":"fields.name"}`,
+		},
+		{
+			name:  "and/not",
+			input: "type_id:team not fields.active:false",
+			expectedQueryJSON: `{
+  "conjuncts": [
+    {"term": "team", "field": "type_id"},
+    {
+      "must": {"conjuncts": [{"boost": null, "match_all": {}}]},
+      "must_not": {"disjuncts": [{"term": "false", "field": "fields.active"}], "min": 0}
+    }
+  ]
+}`,
+		},
+		{
+			name:              "numeric range",
+			input:             "fields.established_year>=1900",
+			expectedQueryJSON: `{"min":1900,"inclusive_min":true,"field":"fields.established_year"}`,
+		},
+		{
+			name:              "date range",
+			input:             `fields.birthday<"2000-01-01T00:00:00Z"`,
+			expectedQueryJSON: `{"start":"0001-01-01T00:00:00Z","end":"2000-01-01T00:00:00Z","inclusive_end":false,"field":"fields.birthday"}`,
+		},
+		{
+			name:          "range invalid",
+			input:         "fields.birthday>yesterday",
+			expectedError: errors.New("fields.birthday: expected number or date literal"),
+		},
+		{
+			name:          "invalid field",
+			input:         "other_field:team",
+			expectedError: errors.New("other_field: invalid field"),
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			n, err := kqlfilter.ParseAST(test.input)
+			require.NoError(t, err)
+
+			g := NewQueryGenerator(WithFieldValidator(
+				func(field string) error {
+					if field == "type_id" {
+						return nil
+					}
+					if strings.HasPrefix(field, "fields.") && strings.Count(field, ".") == 1 {
+						return nil
+					}
+					return errors.New("invalid field")
+				}))
+
+			q, err := g.ConvertAST(n)
+			if test.expectedError != nil {
+				require.EqualError(t, err, test.expectedError.Error())
+				return
+			}
+			require.NoError(t, err)
+
+			data, err := json.Marshal(q)
+			require.NoError(t, err)
+
+			assert.JSONEq(t, test.expectedQueryJSON, string(data))
+		})
+	}
+}
@@ -0,0 +1,29 @@
+module github.com/mycujoo/go-stdlib/pkg/kqlfilter/bleve
+
+go 1.21
+
+require (
+	github.com/blevesearch/bleve/v2 v2.3.10
+	github.com/mycujoo/go-stdlib/pkg/kqlfilter v0.3.3
+	github.com/stretchr/testify v1.8.4
+)
+
+require (
+	github.com/Masterminds/squirrel v1.5.4 // indirect
+	github.com/blevesearch/bleve_index_api v1.0.6 // indirect
+	github.com/blevesearch/geo v0.1.18 // indirect
+	github.com/blevesearch/go-porterstemmer v1.0.3 // indirect
+	github.com/blevesearch/segment v0.9.1 // indirect
+	github.com/blevesearch/snowballstem v0.9.0 // indirect
+	github.com/blevesearch/upsidedown_store_api v1.0.2 // indirect
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/golang/geo v0.0.0-20210211234256-740aa86cb551 // indirect
+	github.com/json-iterator/go v0.0.0-20171115153421-f7279a603ede // indirect
+	github.com/lann/builder v0.0.0-20180802200727-47ae307949d0 // indirect
+	github.com/lann/ps v0.0.0-20150810152359-62de8c46ede0 // indirect
+	github.com/pkg/errors v0.9.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/mycujoo/go-stdlib/pkg/kqlfilter v0.3.3 => ../
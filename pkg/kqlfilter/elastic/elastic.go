@@ -3,19 +3,68 @@ package elastic
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/operator"
 	"github.com/mycujoo/go-stdlib/pkg/kqlfilter"
 )
 
+// FieldType is the Elasticsearch mapping type of a field, which determines the queries generated for it.
+type FieldType int
+
+const (
+	// FieldTypeDefault generates term queries, and range queries typed by the value (number or date).
+	FieldTypeDefault FieldType = iota
+	// FieldTypeKeyword generates term queries, and term range queries comparing values lexicographically.
+	FieldTypeKeyword
+	// FieldTypeText generates match queries. Range queries are not supported.
+	FieldTypeText
+	// FieldTypeDate generates term and date range queries, and requires RFC3339 values.
+	FieldTypeDate
+	// FieldTypeNumeric generates term and number range queries with numeric values.
+	FieldTypeNumeric
+)
+
 type QueryGenerator struct {
 	validateFieldName func(name string) error
+	mapFieldName      func(name string) (string, error)
+	fieldTypes        map[string]FieldType
+	matchOptions      map[string]MatchOptions
+	boosts            map[string]float32
+	nestedPaths       map[string]bool
+	filterContext     bool
+	stringRanges      bool
+	queryString       bool
+}
+
+// MatchOptions configures the match queries generated for a FieldTypeText field.
+type MatchOptions struct {
+	// Maximum edit distance allowed for matching, e.g. "AUTO" or 1. Defaults to exact matching of terms.
+	Fuzziness types.Fuzziness
+	// Analyzer used to convert the query value into terms. Defaults to the search analyzer of the field.
+	Analyzer string
+	// Boolean logic used to combine the terms of the query value, operator.And or operator.Or.
+	// Defaults to operator.Or.
+	Operator *operator.Operator
+	// Minimum number of terms that must match, e.g. 2 or "75%".
+	MinimumShouldMatch types.MinimumShouldMatch
+}
+
+// TermRangeQuery is a range query comparing string values lexicographically, e.g. on keyword fields.
+// The typed client only provides number and date range queries.
+type TermRangeQuery struct {
+	Gt  *string `json:"gt,omitempty"`
+	Gte *string `json:"gte,omitempty"`
+	Lt  *string `json:"lt,omitempty"`
+	Lte *string `json:"lte,omitempty"`
 }
 
 func NewQueryGenerator(options ...Option) *QueryGenerator {
-	g := &QueryGenerator{validateFieldName: defaultFieldNameValidator}
+	g := &QueryGenerator{validateFieldName: defaultFieldNameValidator, mapFieldName: defaultFieldNameMapper}
 
 	for _, option := range options {
 		option(g)
@@ -43,22 +92,194 @@ func WithFieldValidator(fieldValidator func(name string) error) Option {
 	}
 }
 
+// WithFieldMapper allows rewriting incoming field names to the field names used in the index.
+// It is called after the field validator, so the validator checks the names as provided by the user, while field
+// types and nested paths refer to the names returned by the mapper. The mapper is also called with the name of the
+// parent in the x:{y:z} syntax, to look up its nested path.
+// Example usage:
+//
+//	WithFieldMapper(func(name string) (string, error) {
+//		if name == "fields.birthday" {
+//			return "fields.birth_date.keyword", nil
+//		}
+//		return name, nil
+//	})
+func WithFieldMapper(fieldMapper func(name string) (string, error)) Option {
+	return func(g *QueryGenerator) {
+		g.mapFieldName = fieldMapper
+	}
+}
+
+// WithFieldTypes sets the mapping type of fields, keyed by the full (dotted) field name.
+// Fields that are not present use FieldTypeDefault.
+// Example usage:
+//
+//	WithFieldTypes(map[string]FieldType{
+//		"name":       FieldTypeText,
+//		"country":    FieldTypeKeyword,
+//		"founded_at": FieldTypeDate,
+//		"capacity":   FieldTypeNumeric,
+//	})
+func WithFieldTypes(fieldTypes map[string]FieldType) Option {
+	return func(g *QueryGenerator) {
+		g.fieldTypes = fieldTypes
+	}
+}
+
+// WithMatchOptions sets the options of match queries for FieldTypeText fields, keyed by the full (dotted) field name.
+// Example usage:
+//
+//	WithMatchOptions(map[string]MatchOptions{
+//		"title": {Fuzziness: "AUTO", Operator: &operator.And},
+//	})
+func WithMatchOptions(matchOptions map[string]MatchOptions) Option {
+	return func(g *QueryGenerator) {
+		g.matchOptions = matchOptions
+	}
+}
+
+// WithFieldBoosts sets the boost of term and match queries on fields, keyed by the full (dotted) field name.
+// This allows weighing matches on some fields above others when the query is used for scoring, e.g.:
+//
+//	WithFieldBoosts(map[string]float32{
+//		"title":       2,
+//		"description": 0.5,
+//	})
+func WithFieldBoosts(boosts map[string]float32) Option {
+	return func(g *QueryGenerator) {
+		g.boosts = boosts
+	}
+}
+
+// WithNestedPaths declares the fields that are mapped as nested in the index.
+// Queries on fields below a nested path are wrapped in a nested query with that path.
+// Using the x:{y:z} syntax on a nested path x wraps the whole sub query in one nested query,
+// so that all its conditions have to match the same nested document.
+func WithNestedPaths(paths ...string) Option {
+	return func(g *QueryGenerator) {
+		g.nestedPaths = make(map[string]bool, len(paths))
+		for _, path := range paths {
+			g.nestedPaths[path] = true
+		}
+	}
+}
+
+// WithFilterContext generates queries for filter context: AND'ed clauses go into bool.filter instead of bool.must,
+// and a query that is not a bool.filter query already is wrapped in one.
+// Elasticsearch skips scoring for such queries and can cache them, which is preferable when only filtering documents.
+func WithFilterContext() Option {
+	return func(g *QueryGenerator) {
+		g.filterContext = true
+	}
+}
+
+// WithStringRanges generates a TermRangeQuery for range values that are neither a number nor an RFC3339 date,
+// instead of returning an error. This allows lexicographic ranges, e.g. on KSUID or ULID identifiers.
+func WithStringRanges() Option {
+	return func(g *QueryGenerator) {
+		g.stringRanges = true
+	}
+}
+
+// WithQueryString renders filters to a single query_string query instead of typed queries.
+// All values are strictly escaped, so no query_string syntax can be injected through them.
+// Field types, match options, boosts and nested paths do not apply in this mode.
+func WithQueryString() Option {
+	return func(g *QueryGenerator) {
+		g.queryString = true
+	}
+}
+
 // ConvertAST converts a KQL AST to an Elasticsearch query.
 func (q *QueryGenerator) ConvertAST(root kqlfilter.Node) (types.Query, error) {
-	return q.convertNodeToQuery(root, "")
+	var query types.Query
+	var err error
+	if q.queryString {
+		query, err = q.convertToQueryString(root)
+	} else {
+		query, err = q.convertNodeToQuery(root, "", "")
+	}
+	if err != nil {
+		return types.Query{}, err
+	}
+	if q.filterContext && (query.Bool == nil || query.Bool.Filter == nil) {
+		query = types.Query{
+			Bool: &types.BoolQuery{
+				Filter: []types.Query{query},
+			},
+		}
+	}
+	return query, nil
+}
+
+// ConvertString parses a KQL filter and converts it to an Elasticsearch query.
+// The default parser limits of kqlfilter.ParseAST apply unless overridden by opts.
+func (q *QueryGenerator) ConvertString(input string, opts ...kqlfilter.ParserOption) (types.Query, error) {
+	root, err := kqlfilter.ParseAST(input, opts...)
+	if err != nil {
+		return types.Query{}, err
+	}
+	return q.ConvertAST(root)
+}
+
+// ConvertASTWithHighlight converts a KQL AST to an Elasticsearch query, like ConvertAST,
+// and also returns a highlight configuration for the FieldTypeText fields matched by the query.
+// The highlight is nil if the query does not match any text fields.
+func (q *QueryGenerator) ConvertASTWithHighlight(root kqlfilter.Node) (types.Query, *types.Highlight, error) {
+	query, err := q.ConvertAST(root)
+	if err != nil {
+		return types.Query{}, nil, err
+	}
+
+	fields := make(map[string]types.HighlightField)
+	collectMatchFields(query, fields)
+	if len(fields) == 0 {
+		return query, nil, nil
+	}
+
+	highlight := types.NewHighlight()
+	highlight.Fields = fields
+	return query, highlight, nil
 }
 
-func (q *QueryGenerator) convertNodeToQuery(node kqlfilter.Node, prefix string) (types.Query, error) {
+// collectMatchFields adds the fields of all match queries in query to fields.
+// Negated queries are skipped, as documents never contain matches for them.
+func collectMatchFields(query types.Query, fields map[string]types.HighlightField) {
+	for field := range query.Match {
+		fields[field] = types.HighlightField{}
+	}
+	if query.Bool != nil {
+		for _, clauses := range [][]types.Query{query.Bool.Must, query.Bool.Should, query.Bool.Filter} {
+			for _, clause := range clauses {
+				collectMatchFields(clause, fields)
+			}
+		}
+	}
+	if query.Nested != nil && query.Nested.Query != nil {
+		collectMatchFields(*query.Nested.Query, fields)
+	}
+}
+
+// convertNodeToQuery converts node to a query, prefixing all identifiers with prefix.
+// nestedPath is the path of the nested query the result will be part of, if any.
+func (q *QueryGenerator) convertNodeToQuery(node kqlfilter.Node, prefix string, nestedPath string) (types.Query, error) {
 	switch n := node.(type) {
 	case *kqlfilter.AndNode:
 		var clauses []types.Query
 		for _, child := range n.Nodes {
-			q, err := q.convertNodeToQuery(child, prefix)
+			q, err := q.convertNodeToQuery(child, prefix, nestedPath)
 			if err != nil {
 				return types.Query{}, err
 			}
 			clauses = append(clauses, q)
 		}
+		if q.filterContext {
+			return types.Query{
+				Bool: &types.BoolQuery{
+					Filter: clauses,
+				},
+			}, nil
+		}
 		return types.Query{
 			Bool: &types.BoolQuery{
 				Must: clauses,
@@ -67,7 +288,7 @@ func (q *QueryGenerator) convertNodeToQuery(node kqlfilter.Node, prefix string)
 	case *kqlfilter.OrNode:
 		var clauses []types.Query
 		for _, child := range n.Nodes {
-			q, err := q.convertNodeToQuery(child, prefix)
+			q, err := q.convertNodeToQuery(child, prefix, nestedPath)
 			if err != nil {
 				return types.Query{}, err
 			}
@@ -79,7 +300,7 @@ func (q *QueryGenerator) convertNodeToQuery(node kqlfilter.Node, prefix string)
 			},
 		}, nil
 	case *kqlfilter.NotNode:
-		q, err := q.convertNodeToQuery(n.Expr, prefix)
+		q, err := q.convertNodeToQuery(n.Expr, prefix, nestedPath)
 		if err != nil {
 			return types.Query{}, err
 		}
@@ -96,17 +317,38 @@ func (q *QueryGenerator) convertNodeToQuery(node kqlfilter.Node, prefix string)
 			// Transform x:{y:z} syntax.
 			// Prefix all identifiers with the identifier of the parent node,
 			// so it becomes x.y:z
-			return q.convertNodeToQuery(nested.Expr, id+".")
+			// Nested paths refer to mapped names, while the fields inside are mapped with their full names.
+			path, err := q.mapFieldName(id)
+			if err != nil {
+				return types.Query{}, fmt.Errorf("%s: %w", id, err)
+			}
+			if !q.nestedPaths[path] {
+				return q.convertNodeToQuery(nested.Expr, id+".", nestedPath)
+			}
+			sub, err := q.convertNodeToQuery(nested.Expr, id+".", path)
+			if err != nil {
+				return types.Query{}, err
+			}
+			return q.wrapNested(path, nestedPath, types.Query{
+				Nested: &types.NestedQuery{
+					Path:  path,
+					Query: &sub,
+				},
+			}), nil
 		}
 
 		if err := q.validateFieldName(id); err != nil {
 			return types.Query{}, fmt.Errorf("%s: %w", id, err)
 		}
+		field, err := q.mapFieldName(id)
+		if err != nil {
+			return types.Query{}, fmt.Errorf("%s: %w", id, err)
+		}
 
 		or, ok := n.Value.(*kqlfilter.OrNode)
 		if ok {
 			// Transform x:(y or z) syntax.
-			var vals []types.FieldValue
+			var vals []string
 			// Check that all children are literals
 			for _, child := range or.Nodes {
 				if _, ok := child.(*kqlfilter.LiteralNode); !ok {
@@ -116,14 +358,11 @@ func (q *QueryGenerator) convertNodeToQuery(node kqlfilter.Node, prefix string)
 				vals = append(vals, lit.Value)
 			}
 
-			return types.Query{
-				Terms: &types.TermsQuery{
-					TermsQuery: map[string]types.TermsQueryField{
-						id: vals,
-					},
-				},
-			}, nil
-
+			tq, err := q.termsQuery(field, vals)
+			if err != nil {
+				return types.Query{}, fmt.Errorf("%s: %w", id, err)
+			}
+			return q.wrapNested(field, nestedPath, tq), nil
 		}
 
 		lit, ok := n.Value.(*kqlfilter.LiteralNode)
@@ -131,79 +370,290 @@ func (q *QueryGenerator) convertNodeToQuery(node kqlfilter.Node, prefix string)
 			return types.Query{}, fmt.Errorf("%s: expected literal node", id)
 		}
 
-		return types.Query{
-			Term: map[string]types.TermQuery{
-				n.Identifier: {
-					Value: lit.Value,
+		if lit.Value == "*" {
+			// Transform x:* syntax into a check for presence of the field.
+			return q.wrapNested(field, nestedPath, types.Query{
+				Exists: &types.ExistsQuery{
+					Field: field,
 				},
-			},
-		}, nil
+			}), nil
+		}
+
+		tq, err := q.termQuery(field, lit.Value)
+		if err != nil {
+			return types.Query{}, fmt.Errorf("%s: %w", id, err)
+		}
+		return q.wrapNested(field, nestedPath, tq), nil
 	case *kqlfilter.RangeNode:
 		id := prefix + n.Identifier
 
 		if err := q.validateFieldName(id); err != nil {
 			return types.Query{}, err
 		}
+		field, err := q.mapFieldName(id)
+		if err != nil {
+			return types.Query{}, fmt.Errorf("%s: %w", id, err)
+		}
 
 		lit, ok := n.Value.(*kqlfilter.LiteralNode)
 		if !ok {
 			return types.Query{}, fmt.Errorf("%s: expected literal node", id)
 		}
-		rq, err := convertRangeNode(n.Operator, lit)
+		rq, err := q.rangeQuery(field, n.Operator, lit)
 		if err != nil {
 			return types.Query{}, fmt.Errorf("%s: %w", id, err)
 		}
-		return types.Query{
+		return q.wrapNested(field, nestedPath, types.Query{
 			Range: map[string]types.RangeQuery{
-				id: rq,
+				field: rq,
 			},
-		}, nil
+		}), nil
+	case *kqlfilter.DistanceNode:
+		id := prefix + n.Identifier
+
+		if err := q.validateFieldName(id); err != nil {
+			return types.Query{}, fmt.Errorf("%s: %w", id, err)
+		}
+		field, err := q.mapFieldName(id)
+		if err != nil {
+			return types.Query{}, fmt.Errorf("%s: %w", id, err)
+		}
+		return q.wrapNested(field, nestedPath, types.Query{
+			GeoDistance: &types.GeoDistanceQuery{
+				Distance: strconv.FormatFloat(n.Distance, 'f', -1, 64) + "m",
+				GeoDistanceQuery: map[string]types.GeoLocation{
+					field: types.LatLonGeoLocation{
+						Lat: types.Float64(n.Lat),
+						Lon: types.Float64(n.Lon),
+					},
+				},
+			},
+		}), nil
 	default:
 		return types.Query{}, fmt.Errorf("unexpected node type: %T", n)
 	}
 }
 
+// wrapNested wraps a query on field id in nested queries for all nested paths id is part of,
+// except for the ones enclosing nestedPath.
+func (q *QueryGenerator) wrapNested(id string, nestedPath string, query types.Query) types.Query {
+	var paths []string
+	for path := range q.nestedPaths {
+		if strings.HasPrefix(id, path+".") && len(path) > len(nestedPath) {
+			paths = append(paths, path)
+		}
+	}
+	// Wrap the innermost path first.
+	sort.Slice(paths, func(i, j int) bool {
+		return len(paths[i]) > len(paths[j])
+	})
+	for _, path := range paths {
+		inner := query
+		query = types.Query{
+			Nested: &types.NestedQuery{
+				Path:  path,
+				Query: &inner,
+			},
+		}
+	}
+	return query
+}
+
+// termQuery creates the query matching a single value, depending on the type of the field.
+func (q *QueryGenerator) termQuery(id string, value string) (types.Query, error) {
+	switch q.fieldTypes[id] {
+	case FieldTypeText:
+		mq := types.MatchQuery{
+			Query: value,
+			Boost: q.boost(id),
+		}
+		if options, ok := q.matchOptions[id]; ok {
+			mq.Fuzziness = options.Fuzziness
+			mq.Operator = options.Operator
+			mq.MinimumShouldMatch = options.MinimumShouldMatch
+			if options.Analyzer != "" {
+				mq.Analyzer = &options.Analyzer
+			}
+		}
+		return types.Query{
+			Match: map[string]types.MatchQuery{
+				id: mq,
+			},
+		}, nil
+	default:
+		v, err := q.convertValue(id, value)
+		if err != nil {
+			return types.Query{}, err
+		}
+		return types.Query{
+			Term: map[string]types.TermQuery{
+				id: {
+					Value: v,
+					Boost: q.boost(id),
+				},
+			},
+		}, nil
+	}
+}
+
+// termsQuery creates the query matching any of the values, depending on the type of the field.
+func (q *QueryGenerator) termsQuery(id string, values []string) (types.Query, error) {
+	switch q.fieldTypes[id] {
+	case FieldTypeText:
+		var clauses []types.Query
+		for _, value := range values {
+			mq, err := q.termQuery(id, value)
+			if err != nil {
+				return types.Query{}, err
+			}
+			clauses = append(clauses, mq)
+		}
+		return types.Query{
+			Bool: &types.BoolQuery{
+				Should: clauses,
+			},
+		}, nil
+	default:
+		var vals []types.FieldValue
+		for _, value := range values {
+			v, err := q.convertValue(id, value)
+			if err != nil {
+				return types.Query{}, err
+			}
+			vals = append(vals, v)
+		}
+		return types.Query{
+			Terms: &types.TermsQuery{
+				TermsQuery: map[string]types.TermsQueryField{
+					id: vals,
+				},
+				Boost: q.boost(id),
+			},
+		}, nil
+	}
+}
+
+// boost returns the configured boost of the field, or nil if there is none.
+func (q *QueryGenerator) boost(id string) *float32 {
+	boost, ok := q.boosts[id]
+	if !ok {
+		return nil
+	}
+	return &boost
+}
+
+// convertValue validates and converts a term value according to the type of the field.
+func (q *QueryGenerator) convertValue(id string, value string) (types.FieldValue, error) {
+	switch q.fieldTypes[id] {
+	case FieldTypeNumeric:
+		fVal, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, errors.New("expected number literal")
+		}
+		return fVal, nil
+	case FieldTypeDate:
+		if _, err := time.Parse(time.RFC3339, value); err != nil {
+			return nil, errors.New("expected date literal")
+		}
+		return value, nil
+	default:
+		return value, nil
+	}
+}
+
+// rangeQuery creates a range query, depending on the type of the field.
+func (q *QueryGenerator) rangeQuery(id string, op kqlfilter.RangeOperator, lit *kqlfilter.LiteralNode) (types.RangeQuery, error) {
+	switch q.fieldTypes[id] {
+	case FieldTypeText:
+		return nil, errors.New("range queries are not supported for text fields")
+	case FieldTypeNumeric:
+		fVal, err := strconv.ParseFloat(lit.Value, 64)
+		if err != nil {
+			return nil, errors.New("expected number literal")
+		}
+		return numberRangeQuery(op, fVal), nil
+	case FieldTypeDate:
+		if _, err := time.Parse(time.RFC3339, lit.Value); err != nil {
+			return nil, errors.New("expected date literal")
+		}
+		return dateRangeQuery(op, lit.Value), nil
+	case FieldTypeKeyword:
+		return termRangeQuery(op, lit.Value), nil
+	default:
+		rq, err := convertRangeNode(op, lit)
+		if err != nil && q.stringRanges {
+			return termRangeQuery(op, lit.Value), nil
+		}
+		return rq, err
+	}
+}
+
 func convertRangeNode(op kqlfilter.RangeOperator, lit *kqlfilter.LiteralNode) (types.RangeQuery, error) {
 	// Here we check the type of the literal node, and then we can create the correct range query.
 	fVal, err := strconv.ParseFloat(lit.Value, 64)
 	if err == nil {
-		// it is an int
-		esFVal := types.Float64(fVal)
-		rq := &types.NumberRangeQuery{}
-		switch op {
-		case kqlfilter.RangeOperatorLt:
-			rq.Lt = &esFVal
-		case kqlfilter.RangeOperatorLte:
-			rq.Lte = &esFVal
-		case kqlfilter.RangeOperatorGt:
-			rq.Gt = &esFVal
-		case kqlfilter.RangeOperatorGte:
-			rq.Gte = &esFVal
-		}
-		return rq, nil
+		return numberRangeQuery(op, fVal), nil
 	}
 
 	// It is not a number, so we check if it is a date.
 	_, err = time.Parse(time.RFC3339, lit.Value)
 	if err != nil {
-		return nil, errors.New("expected number or date literal")
+		return nil, errors.New("expected number or RFC3339 date literal (e.g. 2006-01-02T15:04:05Z)")
 	}
 
-	rq := &types.DateRangeQuery{}
+	return dateRangeQuery(op, lit.Value), nil
+}
+
+func termRangeQuery(op kqlfilter.RangeOperator, value string) *TermRangeQuery {
+	rq := &TermRangeQuery{}
+	switch op {
+	case kqlfilter.RangeOperatorLt:
+		rq.Lt = &value
+	case kqlfilter.RangeOperatorLte:
+		rq.Lte = &value
+	case kqlfilter.RangeOperatorGt:
+		rq.Gt = &value
+	case kqlfilter.RangeOperatorGte:
+		rq.Gte = &value
+	}
+	return rq
+}
+
+func numberRangeQuery(op kqlfilter.RangeOperator, fVal float64) *types.NumberRangeQuery {
+	esFVal := types.Float64(fVal)
+	rq := &types.NumberRangeQuery{}
 	switch op {
 	case kqlfilter.RangeOperatorLt:
-		rq.Lt = &lit.Value
+		rq.Lt = &esFVal
 	case kqlfilter.RangeOperatorLte:
-		rq.Lte = &lit.Value
+		rq.Lte = &esFVal
 	case kqlfilter.RangeOperatorGt:
-		rq.Gt = &lit.Value
+		rq.Gt = &esFVal
 	case kqlfilter.RangeOperatorGte:
-		rq.Gte = &lit.Value
+		rq.Gte = &esFVal
 	}
+	return rq
+}
 
-	return rq, nil
+func dateRangeQuery(op kqlfilter.RangeOperator, value string) *types.DateRangeQuery {
+	rq := &types.DateRangeQuery{}
+	switch op {
+	case kqlfilter.RangeOperatorLt:
+		rq.Lt = &value
+	case kqlfilter.RangeOperatorLte:
+		rq.Lte = &value
+	case kqlfilter.RangeOperatorGt:
+		rq.Gt = &value
+	case kqlfilter.RangeOperatorGte:
+		rq.Gte = &value
+	}
+	return rq
 }
 
 func defaultFieldNameValidator(_ string) error {
 	return nil
 }
+
+func defaultFieldNameMapper(name string) (string, error) {
+	return name, nil
+}
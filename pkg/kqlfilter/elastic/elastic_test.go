@@ -6,6 +6,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/operator"
 	"github.com/mycujoo/go-stdlib/pkg/kqlfilter"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -162,10 +163,22 @@ func TestConvertNodeToQuery(t *testing.T) {
 	  }
 }`,
 		},
+		{
+			name:              "exists",
+			input:             "fields.website:*",
+			expectedError:     nil,
+			expectedQueryJSON: `{"exists":{"field":"fields.website"}}`,
+		},
+		{
+			name:              "not exists",
+			input:             "not fields.website:*",
+			expectedError:     nil,
+			expectedQueryJSON: `{"bool":{"must_not":[{"exists":{"field":"fields.website"}}]}}`,
+		},
 		{
 			name:          "range invalid",
 			input:         `type_id:player fields.birthday>=true`,
-			expectedError: errors.New("fields.birthday: expected int or date literal"),
+			expectedError: errors.New("fields.birthday: expected number or RFC3339 date literal (e.g. 2006-01-02T15:04:05Z)"),
 		},
 		{
 			name:          "nesting invalid",
@@ -214,3 +227,483 @@ func TestConvertNodeToQuery(t *testing.T) {
 		})
 	}
 }
+
+func TestFieldTypes(t *testing.T) {
+	testCases := []struct {
+		name              string
+		input             string
+		expectedError     error
+		expectedQueryJSON string
+	}{
+		{
+			name:              "keyword",
+			input:             "country:NL",
+			expectedQueryJSON: `{"term":{"country":{"value":"NL"}}}`,
+		},
+		{
+			name:              "text",
+			input:             "name:Ajax",
+			expectedQueryJSON: `{"match":{"name":{"query":"Ajax"}}}`,
+		},
+		{
+			name:              "text with multiple values",
+			input:             "name:(Ajax OR PSV)",
+			expectedQueryJSON: `{"bool":{"should":[{"match":{"name":{"query":"Ajax"}}},{"match":{"name":{"query":"PSV"}}}]}}`,
+		},
+		{
+			name:          "text range",
+			input:         "name>Ajax",
+			expectedError: errors.New("name: range queries are not supported for text fields"),
+		},
+		{
+			name:              "numeric",
+			input:             "capacity:(55000 OR 35000)",
+			expectedQueryJSON: `{"terms":{"capacity":[55000,35000]}}`,
+		},
+		{
+			name:              "numeric range",
+			input:             "capacity>=55000",
+			expectedQueryJSON: `{"range":{"capacity":{"gte":55000}}}`,
+		},
+		{
+			name:          "numeric invalid",
+			input:         "capacity:large",
+			expectedError: errors.New("capacity: expected number literal"),
+		},
+		{
+			name:              "date range",
+			input:             `founded_at<"1900-03-18T00:00:00Z"`,
+			expectedQueryJSON: `{"range":{"founded_at":{"lt":"1900-03-18T00:00:00Z"}}}`,
+		},
+		{
+			name:          "date invalid",
+			input:         "founded_at<1900",
+			expectedError: errors.New("founded_at: expected date literal"),
+		},
+		{
+			name:              "keyword range",
+			input:             "country>=NL",
+			expectedQueryJSON: `{"range":{"country":{"gte":"NL"}}}`,
+		},
+		{
+			name:              "nested field",
+			input:             "stadium:{capacity:55000}",
+			expectedQueryJSON: `{"term":{"stadium.capacity":{"value":55000}}}`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			n, err := kqlfilter.ParseAST(test.input)
+			require.NoError(t, err)
+
+			g := NewQueryGenerator(WithFieldTypes(map[string]FieldType{
+				"name":             FieldTypeText,
+				"country":          FieldTypeKeyword,
+				"founded_at":       FieldTypeDate,
+				"capacity":         FieldTypeNumeric,
+				"stadium.capacity": FieldTypeNumeric,
+			}))
+
+			q, err := g.ConvertAST(n)
+			if test.expectedError != nil {
+				require.EqualError(t, err, test.expectedError.Error())
+				return
+			}
+			require.NoError(t, err)
+
+			data, err := json.Marshal(q)
+			require.NoError(t, err)
+
+			assert.JSONEq(t, test.expectedQueryJSON, string(data))
+		})
+	}
+}
+
+func TestNestedPaths(t *testing.T) {
+	testCases := []struct {
+		name              string
+		input             string
+		expectedQueryJSON string
+	}{
+		{
+			name:              "field in nested document",
+			input:             "players.name:Cruijff",
+			expectedQueryJSON: `{"nested":{"path":"players","query":{"term":{"players.name":{"value":"Cruijff"}}}}}`,
+		},
+		{
+			name:  "conditions on the same nested document",
+			input: "players:{name:Cruijff and number>=14}",
+			expectedQueryJSON: `{
+  "nested": {
+    "path": "players",
+    "query": {
+      "bool": {
+        "must": [
+          {"term": {"players.name": {"value": "Cruijff"}}},
+          {"range": {"players.number": {"gte": 14}}}
+        ]
+      }
+    }
+  }
+}`,
+		},
+		{
+			name:  "multi-level nesting",
+			input: "players:{clubs.name:Ajax}",
+			expectedQueryJSON: `{
+  "nested": {
+    "path": "players",
+    "query": {
+      "nested": {
+        "path": "players.clubs",
+        "query": {"term": {"players.clubs.name": {"value": "Ajax"}}}
+      }
+    }
+  }
+}`,
+		},
+		{
+			name:              "field outside nested document",
+			input:             "name:Ajax",
+			expectedQueryJSON: `{"term":{"name":{"value":"Ajax"}}}`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			n, err := kqlfilter.ParseAST(test.input)
+			require.NoError(t, err)
+
+			g := NewQueryGenerator(WithNestedPaths("players", "players.clubs"))
+
+			q, err := g.ConvertAST(n)
+			require.NoError(t, err)
+
+			data, err := json.Marshal(q)
+			require.NoError(t, err)
+
+			assert.JSONEq(t, test.expectedQueryJSON, string(data))
+		})
+	}
+}
+
+func TestFilterContext(t *testing.T) {
+	testCases := []struct {
+		name              string
+		input             string
+		expectedQueryJSON string
+	}{
+		{
+			name:              "single clause",
+			input:             "type_id:team",
+			expectedQueryJSON: `{"bool":{"filter":[{"term":{"type_id":{"value":"team"}}}]}}`,
+		},
+		{
+			name:  "and/or/not",
+			input: "type_id:team (fields.active:true or not fields.established_year<2000)",
+			expectedQueryJSON: `{
+  "bool": {
+    "filter": [
+      {"term": {"type_id": {"value": "team"}}},
+      {
+        "bool": {
+          "should": [
+            {"term": {"fields.active": {"value": "true"}}},
+            {"bool": {"must_not": [{"range": {"fields.established_year": {"lt": 2000}}}]}}
+          ]
+        }
+      }
+    ]
+  }
+}`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			n, err := kqlfilter.ParseAST(test.input)
+			require.NoError(t, err)
+
+			q, err := NewQueryGenerator(WithFilterContext()).ConvertAST(n)
+			require.NoError(t, err)
+
+			data, err := json.Marshal(q)
+			require.NoError(t, err)
+
+			assert.JSONEq(t, test.expectedQueryJSON, string(data))
+		})
+	}
+}
+
+func TestStringRanges(t *testing.T) {
+	n, err := kqlfilter.ParseAST("id>2Nz7QeQJ6dRRqeHzWNQ7eJXZr5k and version<=3")
+	require.NoError(t, err)
+
+	q, err := NewQueryGenerator(WithStringRanges()).ConvertAST(n)
+	require.NoError(t, err)
+
+	data, err := json.Marshal(q)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+  "bool": {
+    "must": [
+      {"range": {"id": {"gt": "2Nz7QeQJ6dRRqeHzWNQ7eJXZr5k"}}},
+      {"range": {"version": {"lte": 3}}}
+    ]
+  }
+}`, string(data))
+}
+
+func TestFieldMapper(t *testing.T) {
+	n, err := kqlfilter.ParseAST(`fields.birthday>="2000-01-01T00:00:00Z" fields:{nickname:*}`)
+	require.NoError(t, err)
+
+	g := NewQueryGenerator(
+		WithFieldMapper(func(name string) (string, error) {
+			switch name {
+			case "fields.birthday":
+				return "fields.birth_date.keyword", nil
+			case "fields.nickname":
+				return "", errors.New("unknown field")
+			}
+			return name, nil
+		}),
+		WithFieldTypes(map[string]FieldType{
+			"fields.birth_date.keyword": FieldTypeKeyword,
+		}),
+	)
+
+	_, err = g.ConvertAST(n)
+	require.EqualError(t, err, "fields.nickname: unknown field")
+
+	n, err = kqlfilter.ParseAST(`fields.birthday>="2000-01-01T00:00:00Z"`)
+	require.NoError(t, err)
+
+	q, err := g.ConvertAST(n)
+	require.NoError(t, err)
+
+	data, err := json.Marshal(q)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{"range":{"fields.birth_date.keyword":{"gte":"2000-01-01T00:00:00Z"}}}`, string(data))
+}
+
+func TestFieldMapperWithNestedPaths(t *testing.T) {
+	n, err := kqlfilter.ParseAST(`squad:{name:Ajax and number>=10} squad.name:PSV`)
+	require.NoError(t, err)
+
+	g := NewQueryGenerator(
+		WithFieldMapper(func(name string) (string, error) {
+			return strings.Replace(name, "squad", "players", 1), nil
+		}),
+		WithNestedPaths("players"),
+	)
+
+	q, err := g.ConvertAST(n)
+	require.NoError(t, err)
+
+	data, err := json.Marshal(q)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+  "bool": {
+    "must": [
+      {
+        "nested": {
+          "path": "players",
+          "query": {
+            "bool": {
+              "must": [
+                {"term": {"players.name": {"value": "Ajax"}}},
+                {"range": {"players.number": {"gte": 10}}}
+              ]
+            }
+          }
+        }
+      },
+      {"nested": {"path": "players", "query": {"term": {"players.name": {"value": "PSV"}}}}}
+    ]
+  }
+}`, string(data))
+}
+
+func TestMatchOptions(t *testing.T) {
+	n, err := kqlfilter.ParseAST(`title:"ajax amsterdam" description:football`)
+	require.NoError(t, err)
+
+	g := NewQueryGenerator(
+		WithFieldTypes(map[string]FieldType{
+			"title":       FieldTypeText,
+			"description": FieldTypeText,
+		}),
+		WithMatchOptions(map[string]MatchOptions{
+			"title": {
+				Fuzziness:          "AUTO",
+				Analyzer:           "standard",
+				Operator:           &operator.And,
+				MinimumShouldMatch: "75%",
+			},
+		}),
+	)
+
+	q, err := g.ConvertAST(n)
+	require.NoError(t, err)
+
+	data, err := json.Marshal(q)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+  "bool": {
+    "must": [
+      {
+        "match": {
+          "title": {
+            "query": "ajax amsterdam",
+            "fuzziness": "AUTO",
+            "analyzer": "standard",
+            "operator": "and",
+            "minimum_should_match": "75%"
+          }
+        }
+      },
+      {"match": {"description": {"query": "football"}}}
+    ]
+  }
+}`, string(data))
+}
+
+func TestFieldBoosts(t *testing.T) {
+	n, err := kqlfilter.ParseAST(`title:ajax or description:ajax or tags:(football OR amsterdam)`)
+	require.NoError(t, err)
+
+	g := NewQueryGenerator(
+		WithFieldTypes(map[string]FieldType{
+			"title":       FieldTypeText,
+			"description": FieldTypeText,
+		}),
+		WithFieldBoosts(map[string]float32{
+			"title": 2,
+			"tags":  0.5,
+		}),
+	)
+
+	q, err := g.ConvertAST(n)
+	require.NoError(t, err)
+
+	data, err := json.Marshal(q)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+  "bool": {
+    "should": [
+      {"match": {"title": {"query": "ajax", "boost": 2}}},
+      {"match": {"description": {"query": "ajax"}}},
+      {"terms": {"tags": ["football", "amsterdam"], "boost": 0.5}}
+    ]
+  }
+}`, string(data))
+}
+
+func TestConvertString(t *testing.T) {
+	g := NewQueryGenerator()
+
+	q, err := g.ConvertString(`team:ajax and year>=2020`)
+	require.NoError(t, err)
+
+	data, err := json.Marshal(q)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+  "bool": {
+    "must": [
+      {"term": {"team": {"value": "ajax"}}},
+      {"range": {"year": {"gte": 2020}}}
+    ]
+  }
+}`, string(data))
+
+	_, err = g.ConvertString(`team:ajax or team:psv`, kqlfilter.DisableComplexExpressions())
+	require.Error(t, err)
+
+	_, err = g.ConvertString(`team:ajax or team:psv or team:az`, kqlfilter.WithMaxComplexity(1))
+	require.Error(t, err)
+}
+
+func TestConvertASTWithHighlight(t *testing.T) {
+	g := NewQueryGenerator(
+		WithFieldTypes(map[string]FieldType{
+			"title":          FieldTypeText,
+			"description":    FieldTypeText,
+			"comments.body":  FieldTypeText,
+			"comments.notes": FieldTypeText,
+		}),
+		WithNestedPaths("comments"),
+	)
+
+	tests := []struct {
+		name      string
+		input     string
+		highlight string
+	}{
+		{
+			name:      "text fields",
+			input:     `title:ajax or (description:(final or cup) and team:ajax)`,
+			highlight: `{"fields": {"title": {}, "description": {}}}`,
+		},
+		{
+			name:      "nested text field",
+			input:     `comments:{body:goal and not notes:offside}`,
+			highlight: `{"fields": {"comments.body": {}}}`,
+		},
+		{
+			name:  "no text fields",
+			input: `team:ajax and not title:psv`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			n, err := kqlfilter.ParseAST(test.input)
+			require.NoError(t, err)
+
+			q, h, err := g.ConvertASTWithHighlight(n)
+			require.NoError(t, err)
+
+			expected, err := g.ConvertAST(n)
+			require.NoError(t, err)
+			assert.Equal(t, expected, q)
+
+			if test.highlight == "" {
+				assert.Nil(t, h)
+				return
+			}
+			data, err := json.Marshal(h)
+			require.NoError(t, err)
+			assert.JSONEq(t, test.highlight, string(data))
+		})
+	}
+}
+
+func TestDistance(t *testing.T) {
+	n, err := kqlfilter.ParseAST(`distance(location, 52.3, 4.9) <= 10km and venue:{distance(location, 52.3, 4.9) <= 500m}`)
+	require.NoError(t, err)
+
+	g := NewQueryGenerator(WithNestedPaths("venue"))
+
+	q, err := g.ConvertAST(n)
+	require.NoError(t, err)
+
+	data, err := json.Marshal(q)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+  "bool": {
+    "must": [
+      {"geo_distance": {"distance": "10000m", "location": {"lat": 52.3, "lon": 4.9}}},
+      {"nested": {"path": "venue", "query": {"geo_distance": {"distance": "500m", "venue.location": {"lat": 52.3, "lon": 4.9}}}}}
+    ]
+  }
+}`, string(data))
+}
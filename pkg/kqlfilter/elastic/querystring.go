@@ -0,0 +1,151 @@
+package elastic
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+	"github.com/mycujoo/go-stdlib/pkg/kqlfilter"
+)
+
+// queryStringReserved are the characters that have a special meaning in query_string queries.
+const queryStringReserved = `+-=&|!(){}[]^"~*?:\/`
+
+// EscapeQueryString escapes s so that it is matched literally in a query_string query.
+// Reserved characters and whitespace are escaped with a backslash, and the operators AND, OR and NOT
+// are escaped so they are treated as terms. As < and > cannot be escaped, they are removed.
+func EscapeQueryString(s string) string {
+	switch s {
+	case "AND", "OR", "NOT":
+		return `\` + s
+	}
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '<' || r == '>':
+			continue
+		case strings.ContainsRune(queryStringReserved, r) || r == ' ' || r == '\t' || r == '\r' || r == '\n':
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// convertToQueryString converts a KQL AST to a query_string query.
+func (q *QueryGenerator) convertToQueryString(root kqlfilter.Node) (types.Query, error) {
+	s, err := q.renderQueryString(root, "")
+	if err != nil {
+		return types.Query{}, err
+	}
+	return types.Query{
+		QueryString: &types.QueryStringQuery{
+			Query: s,
+		},
+	}, nil
+}
+
+// renderQueryString renders node as a query_string expression, prefixing all identifiers with prefix.
+func (q *QueryGenerator) renderQueryString(node kqlfilter.Node, prefix string) (string, error) {
+	switch n := node.(type) {
+	case *kqlfilter.AndNode:
+		return q.renderQueryStringList(n.Nodes, prefix, " AND ")
+	case *kqlfilter.OrNode:
+		return q.renderQueryStringList(n.Nodes, prefix, " OR ")
+	case *kqlfilter.NotNode:
+		s, err := q.renderQueryString(n.Expr, prefix)
+		if err != nil {
+			return "", err
+		}
+		return "NOT " + s, nil
+	case *kqlfilter.IsNode:
+		id := prefix + n.Identifier
+
+		nested, ok := n.Value.(*kqlfilter.NestedNode)
+		if ok {
+			// Transform x:{y:z} syntax into x.y:z
+			return q.renderQueryString(nested.Expr, id+".")
+		}
+
+		field, err := q.queryStringField(id)
+		if err != nil {
+			return "", err
+		}
+
+		or, ok := n.Value.(*kqlfilter.OrNode)
+		if ok {
+			// Transform x:(y or z) syntax.
+			var vals []string
+			for _, child := range or.Nodes {
+				lit, ok := child.(*kqlfilter.LiteralNode)
+				if !ok {
+					return "", fmt.Errorf("%s: invalid syntax", id)
+				}
+				vals = append(vals, EscapeQueryString(lit.Value))
+			}
+			return field + ":(" + strings.Join(vals, " OR ") + ")", nil
+		}
+
+		lit, ok := n.Value.(*kqlfilter.LiteralNode)
+		if !ok {
+			return "", fmt.Errorf("%s: expected literal node", id)
+		}
+		if lit.Value == "*" {
+			return "_exists_:" + field, nil
+		}
+		return field + ":" + EscapeQueryString(lit.Value), nil
+	case *kqlfilter.RangeNode:
+		id := prefix + n.Identifier
+
+		field, err := q.queryStringField(id)
+		if err != nil {
+			return "", err
+		}
+
+		lit, ok := n.Value.(*kqlfilter.LiteralNode)
+		if !ok {
+			return "", fmt.Errorf("%s: expected literal node", id)
+		}
+		var op string
+		switch n.Operator {
+		case kqlfilter.RangeOperatorLt:
+			op = "<"
+		case kqlfilter.RangeOperatorLte:
+			op = "<="
+		case kqlfilter.RangeOperatorGt:
+			op = ">"
+		case kqlfilter.RangeOperatorGte:
+			op = ">="
+		default:
+			return "", fmt.Errorf("%s: unsupported range operator", id)
+		}
+		return field + ":" + op + EscapeQueryString(lit.Value), nil
+	default:
+		return "", fmt.Errorf("unexpected node type: %T", n)
+	}
+}
+
+// renderQueryStringList renders nodes as a parenthesized query_string expression joined by sep.
+func (q *QueryGenerator) renderQueryStringList(nodes []kqlfilter.Node, prefix string, sep string) (string, error) {
+	var clauses []string
+	for _, child := range nodes {
+		s, err := q.renderQueryString(child, prefix)
+		if err != nil {
+			return "", err
+		}
+		clauses = append(clauses, s)
+	}
+	return "(" + strings.Join(clauses, sep) + ")", nil
+}
+
+// queryStringField validates and maps the field id, and escapes it for use in a query_string query.
+func (q *QueryGenerator) queryStringField(id string) (string, error) {
+	if err := q.validateFieldName(id); err != nil {
+		return "", fmt.Errorf("%s: %w", id, err)
+	}
+	field, err := q.mapFieldName(id)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", id, err)
+	}
+	return EscapeQueryString(field), nil
+}
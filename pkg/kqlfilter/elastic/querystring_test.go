@@ -0,0 +1,122 @@
+package elastic
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mycujoo/go-stdlib/pkg/kqlfilter"
+)
+
+func TestEscapeQueryString(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"ajax", "ajax"},
+		{"ajax amsterdam", `ajax\ amsterdam`},
+		{"a+b-c=d", `a\+b\-c\=d`},
+		{"(x) {y} [z]", `\(x\)\ \{y\}\ \[z\]`},
+		{`"quoted"`, `\"quoted\"`},
+		{"a&&b||!c", `a\&\&b\|\|\!c`},
+		{"^~*?:/", `\^\~\*\?\:\/`},
+		{`back\slash`, `back\\slash`},
+		{"<script>", "script"},
+		{"AND", `\AND`},
+		{"and", "and"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			assert.Equal(t, test.expected, EscapeQueryString(test.input))
+		})
+	}
+}
+
+func TestQueryString(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expected    string
+		expectedErr bool
+	}{
+		{
+			name:     "single field",
+			input:    `team:ajax`,
+			expected: `team:ajax`,
+		},
+		{
+			name:     "and, or and not",
+			input:    `team:ajax and (year>=2020 or not city:"den haag")`,
+			expected: `(team:ajax AND (year:>=2020 OR NOT city:den\ haag))`,
+		},
+		{
+			name:     "multiple values",
+			input:    `team:(ajax or psv)`,
+			expected: `team:(ajax OR psv)`,
+		},
+		{
+			name:     "nested fields",
+			input:    `team:{name:ajax and city:amsterdam}`,
+			expected: `(team.name:ajax AND team.city:amsterdam)`,
+		},
+		{
+			name:     "exists",
+			input:    `team:*`,
+			expected: `_exists_:team`,
+		},
+		{
+			name:     "date range",
+			input:    `date<"2023-01-01T00:00:00Z"`,
+			expected: `date:<2023\-01\-01T00\:00\:00Z`,
+		},
+		{
+			name:     "injection",
+			input:    `team:"ajax OR _exists_:secret"`,
+			expected: `team:ajax\ OR\ _exists_\:secret`,
+		},
+		{
+			name:        "disallowed field",
+			input:       `secret:x`,
+			expectedErr: true,
+		},
+	}
+
+	g := NewQueryGenerator(
+		WithQueryString(),
+		WithFieldValidator(func(name string) error {
+			if name == "secret" {
+				return errors.New("not allowed")
+			}
+			return nil
+		}),
+	)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			n, err := kqlfilter.ParseAST(test.input)
+			require.NoError(t, err)
+
+			q, err := g.ConvertAST(n)
+			if test.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			data, err := json.Marshal(q)
+			require.NoError(t, err)
+
+			expected, err := json.Marshal(map[string]any{
+				"query_string": map[string]any{
+					"query": test.expected,
+				},
+			})
+			require.NoError(t, err)
+			assert.JSONEq(t, string(expected), string(data))
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package kqlfilter
+
+import (
+	"fmt"
+	"time"
+)
+
+type FieldType int
+
+const (
+	FieldTypeString FieldType = iota
+	FieldTypeInt
+	FieldTypeFloat
+	FieldTypeBool
+	FieldTypeTimestamp
+	// Geography column, only supporting geo-distance clauses.
+	FieldTypeGeography
+)
+
+// FieldConfig describes a field that may be queried via a filter, independent of the converter used.
+// It can be turned into the config of a specific converter with SpannerFieldConfigs or SquirrelSqlFieldConfigs,
+// so that services supporting multiple databases only have to maintain one config map per resource.
+type FieldConfig struct {
+	// Column name. Can be omitted if the column name is equal to the key in the fieldConfigs map.
+	ColumnName string
+	// Column type. Defaults to FieldTypeString.
+	Type FieldType
+	// Allow prefix matching when a wildcard (`*`) is present at the end of a string.
+	// Only applicable for FieldTypeString. Defaults to false.
+	AllowPrefixMatch bool
+	// Allow multiple values for this field. Defaults to false.
+	AllowMultipleValues bool
+	// Allow range operators with lexicographic comparison. Only applicable for FieldTypeString. Defaults to false.
+	AllowStringRanges bool
+	// Operators (as in Clause.Operator) that may be used with this field. Defaults to all operators supported by the
+	// converter.
+	AllowedOperators []string
+	// Trim whitespace from values, remove duplicate values and sort them before building the statement.
+	NormalizeValues bool
+	// Location parsed timestamps are converted to. Only applicable for FieldTypeTimestamp.
+	TimestampLocation *time.Location
+	// Precision parsed timestamps are truncated to. Only applicable for FieldTypeTimestamp.
+	TimestampPrecision time.Duration
+	// A function that takes a string value as provided by the user and converts it to `any` result that matches how it
+	// is stored in the database. This should return an error when the user is providing a value that is illegal for
+	// this particular field. Defaults to using the provided value as-is.
+	MapValue func(string) (any, error)
+}
+
+// SpannerFieldConfigs converts field configs to be used with Filter.ToSpannerSQL.
+func SpannerFieldConfigs(fieldConfigs map[string]FieldConfig) map[string]FilterToSpannerFieldConfig {
+	out := make(map[string]FilterToSpannerFieldConfig, len(fieldConfigs))
+	for name, c := range fieldConfigs {
+		out[name] = c.SpannerFieldConfig()
+	}
+	return out
+}
+
+// SquirrelSqlFieldConfigs converts field configs to be used with Filter.ToSquirrelSql or Filter.ToSqlizer.
+func SquirrelSqlFieldConfigs(fieldConfigs map[string]FieldConfig) map[string]FilterToSquirrelSqlFieldConfig {
+	out := make(map[string]FilterToSquirrelSqlFieldConfig, len(fieldConfigs))
+	for name, c := range fieldConfigs {
+		out[name] = c.SquirrelSqlFieldConfig()
+	}
+	return out
+}
+
+// SpannerFieldConfig converts the field config to be used with Filter.ToSpannerSQL.
+func (c FieldConfig) SpannerFieldConfig() FilterToSpannerFieldConfig {
+	var columnType FilterToSpannerFieldColumnType
+	switch c.Type {
+	case FieldTypeInt:
+		columnType = FilterToSpannerFieldColumnTypeInt64
+	case FieldTypeFloat:
+		columnType = FilterToSpannerFieldColumnTypeFloat64
+	case FieldTypeBool:
+		columnType = FilterToSpannerFieldColumnTypeBool
+	case FieldTypeTimestamp:
+		columnType = FilterToSpannerFieldColumnTypeTimestamp
+	case FieldTypeGeography:
+		columnType = FilterToSpannerFieldColumnTypeGeography
+	default:
+		columnType = FilterToSpannerFieldColumnTypeString
+	}
+	return FilterToSpannerFieldConfig{
+		ColumnName:          c.ColumnName,
+		ColumnType:          columnType,
+		AllowPrefixMatch:    c.AllowPrefixMatch,
+		AllowMultipleValues: c.AllowMultipleValues,
+		AllowStringRanges:   c.AllowStringRanges,
+		AllowedOperators:    c.AllowedOperators,
+		NormalizeValues:     c.NormalizeValues,
+		TimestampLocation:   c.TimestampLocation,
+		TimestampPrecision:  c.TimestampPrecision,
+		MapValue:            c.MapValue,
+	}
+}
+
+// SquirrelSqlFieldConfig converts the field config to be used with Filter.ToSquirrelSql or Filter.ToSqlizer.
+func (c FieldConfig) SquirrelSqlFieldConfig() FilterToSquirrelSqlFieldConfig {
+	var columnType FilterToSquirrelSqlFieldColumnType
+	switch c.Type {
+	case FieldTypeInt:
+		columnType = FilterToSquirrelSqlFieldColumnTypeInt
+	case FieldTypeFloat:
+		columnType = FilterToSquirrelSqlFieldColumnTypeFloat
+	case FieldTypeBool:
+		columnType = FilterToSquirrelSqlFieldColumnTypeBool
+	case FieldTypeTimestamp:
+		columnType = FilterToSquirrelSqlFieldColumnTypeTimestamp
+	case FieldTypeGeography:
+		columnType = FilterToSquirrelSqlFieldColumnTypeGeography
+	default:
+		columnType = FilterToSquirrelSqlFieldColumnTypeString
+	}
+	return FilterToSquirrelSqlFieldConfig{
+		ColumnName:           c.ColumnName,
+		ColumnType:           columnType,
+		AllowPrefixMatch:     c.AllowPrefixMatch,
+		AllowMultipleValues:  c.AllowMultipleValues,
+		DisallowStringRanges: !c.AllowStringRanges,
+		AllowedOperators:     c.AllowedOperators,
+		NormalizeValues:      c.NormalizeValues,
+		TimestampLocation:    c.TimestampLocation,
+		TimestampPrecision:   c.TimestampPrecision,
+		MapValue:             c.MapValue,
+	}
+}
+
+// checkOperatorAllowed returns an error if allowedOperators is not empty and does not contain op.
+func checkOperatorAllowed(op string, allowedOperators []string) error {
+	if len(allowedOperators) == 0 {
+		return nil
+	}
+	for _, allowed := range allowedOperators {
+		if op == allowed {
+			return nil
+		}
+	}
+	return fmt.Errorf("operator %s not allowed", op)
+}
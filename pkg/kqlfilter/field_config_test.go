@@ -0,0 +1,65 @@
+package kqlfilter
+
+import (
+	"testing"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFieldConfig(t *testing.T) {
+	fieldConfigs := map[string]FieldConfig{
+		"userId": {
+			ColumnName:       "user_id",
+			Type:             FieldTypeInt,
+			AllowedOperators: []string{"="},
+		},
+		"email": {
+			AllowPrefixMatch: true,
+		},
+	}
+
+	t.Run("spanner", func(t *testing.T) {
+		f, err := Parse("userId:12345 email:john@*", false)
+		require.NoError(t, err)
+		condAnds, params, err := f.ToSpannerSQL(SpannerFieldConfigs(fieldConfigs))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"user_id=@KQL0", "email LIKE @KQL1"}, condAnds)
+		assert.Equal(t, map[string]any{"KQL0": int64(12345), "KQL1": "john@%"}, params)
+	})
+
+	t.Run("squirrel", func(t *testing.T) {
+		f, err := Parse("userId:12345 email:john@*", false)
+		require.NoError(t, err)
+		stmt, err := f.ToSquirrelSql(sq.Select("*").From("users"), SquirrelSqlFieldConfigs(fieldConfigs))
+		require.NoError(t, err)
+		sql, args, err := stmt.ToSql()
+		require.NoError(t, err)
+		assert.Equal(t, "SELECT * FROM users WHERE user_id = ? AND email LIKE ?", sql)
+		assert.Equal(t, []any{int64(12345), "john@%"}, args)
+	})
+
+	t.Run("operator not allowed", func(t *testing.T) {
+		f, err := Parse("userId>12345", true)
+		require.NoError(t, err)
+		_, _, err = f.ToSpannerSQL(SpannerFieldConfigs(fieldConfigs))
+		assert.EqualError(t, err, "field userId: operator > not allowed")
+		_, err = f.ToSqlizer(SquirrelSqlFieldConfigs(fieldConfigs))
+		assert.ErrorIs(t, err, operatorError)
+	})
+
+	t.Run("string ranges", func(t *testing.T) {
+		f, err := Parse("cursor>2Nz7QeQJ6dRRqeHzWNQ7eJXZr5k", true)
+		require.NoError(t, err)
+		configs := map[string]FieldConfig{"cursor": {ColumnName: "id", AllowStringRanges: true}}
+		cond, err := f.ToSqlizer(SquirrelSqlFieldConfigs(configs))
+		require.NoError(t, err)
+		sql, _, err := cond.ToSql()
+		require.NoError(t, err)
+		assert.Equal(t, "(id > ?)", sql)
+
+		_, err = f.ToSqlizer(SquirrelSqlFieldConfigs(map[string]FieldConfig{"cursor": {ColumnName: "id"}}))
+		assert.ErrorIs(t, err, operatorError)
+	})
+}
@@ -2,7 +2,10 @@ package kqlfilter
 
 import (
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 )
 
 type Filter struct {
@@ -11,10 +14,11 @@ type Filter struct {
 
 type Clause struct {
 	Field string
-	// One of the following: `=`, `<`, `<=`, `>`, `>=`, `IN`
+	// One of the following: `=`, `!=`, `<`, `<=`, `>`, `>=`, `IN`, `NOT IN`, `DWITHIN`
 	Operator string
 	// List of values for the clause.
-	// For `IN` operator, this is a list of values to match against.
+	// For `IN` and `NOT IN` operators, this is a list of values to match against.
+	// For the `DWITHIN` operator, this is the latitude, longitude and distance in meters.
 	// For other operators, this is a list of one string.
 	Values []string
 }
@@ -22,6 +26,9 @@ type Clause struct {
 // Parse parses a filter string into a Filter struct.
 // The filter string must not contain any boolean operators, parentheses or nested queries.
 // The filter string must contain only simple clauses of the form "field:value", where all clauses are AND'ed.
+// Such a clause can be negated with NOT, e.g. "not field:value" or "not field:(a or b)", resulting in a `!=` or
+// `NOT IN` clause. A geo-distance clause like "distance(field, 52.3, 4.9) < 10km" results in a `DWITHIN` clause,
+// which includes points at exactly the distance.
 // Optionally, range operators can be enabled, e.g. for expressions involving date ranges.
 // If you need to parse a more complex filter string, use ParseAST instead.
 func Parse(input string, enableRangeOperator bool) (Filter, error) {
@@ -88,6 +95,10 @@ func convertToFilter(ast Node, enableRangeOperator bool) (Filter, error) {
 		return convertAndNode(n, enableRangeOperator)
 	case *IsNode:
 		return convertIsNode(n)
+	case *NotNode:
+		return convertNotNode(n)
+	case *DistanceNode:
+		return convertDistanceNode(n), nil
 	case *RangeNode:
 		if enableRangeOperator {
 			return convertRangeNode(n)
@@ -107,6 +118,10 @@ func convertAndNode(ast *AndNode, enableRangeOperator bool) (Filter, error) {
 		switch n := node.(type) {
 		case *IsNode:
 			f, err = convertIsNode(n)
+		case *NotNode:
+			f, err = convertNotNode(n)
+		case *DistanceNode:
+			f = convertDistanceNode(n)
 		case *RangeNode:
 			if !enableRangeOperator {
 				return Filter{}, fmt.Errorf("unsupported node type %T", ast)
@@ -154,6 +169,24 @@ func convertIsNode(ast *IsNode) (Filter, error) {
 	}, nil
 }
 
+func convertNotNode(ast *NotNode) (Filter, error) {
+	isNode, ok := ast.Expr.(*IsNode)
+	if !ok {
+		return Filter{}, fmt.Errorf("unsupported node type %T", ast.Expr)
+	}
+	f, err := convertIsNode(isNode)
+	if err != nil {
+		return Filter{}, err
+	}
+	switch f.Clauses[0].Operator {
+	case "=":
+		f.Clauses[0].Operator = "!="
+	case "IN":
+		f.Clauses[0].Operator = "NOT IN"
+	}
+	return f, nil
+}
+
 func convertRangeNode(ast *RangeNode) (Filter, error) {
 	var value string
 	switch n := ast.Value.(type) {
@@ -176,3 +209,62 @@ func convertRangeNode(ast *RangeNode) (Filter, error) {
 		},
 	}, nil
 }
+
+func convertDistanceNode(ast *DistanceNode) Filter {
+	return Filter{
+		Clauses: []Clause{
+			{
+				Field:    ast.Identifier,
+				Operator: "DWITHIN",
+				Values: []string{
+					strconv.FormatFloat(ast.Lat, 'f', -1, 64),
+					strconv.FormatFloat(ast.Lon, 'f', -1, 64),
+					strconv.FormatFloat(ast.Distance, 'f', -1, 64),
+				},
+			},
+		},
+	}
+}
+
+// parseDistanceValues parses the latitude, longitude and distance of a `DWITHIN` clause.
+func parseDistanceValues(values []string) (lat, lon, distance float64, err error) {
+	if len(values) != 3 {
+		return 0, 0, 0, fmt.Errorf("expected latitude, longitude and distance, got %d values", len(values))
+	}
+	var parsed [3]float64
+	for i, v := range values {
+		parsed[i], err = strconv.ParseFloat(v, 64)
+		if err != nil {
+			return 0, 0, 0, fmt.Errorf("invalid distance value: %w", err)
+		}
+	}
+	return parsed[0], parsed[1], parsed[2], nil
+}
+
+// normalizeValues trims whitespace from values, removes duplicates and sorts them, so that equivalent clauses result
+// in identical statements.
+func normalizeValues(values []string) []string {
+	out := make([]string, 0, len(values))
+	seen := make(map[string]bool, len(values))
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if seen[v] {
+			continue
+		}
+		seen[v] = true
+		out = append(out, v)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// normalizeTime converts t to loc and truncates it to precision, skipping either step when not configured.
+func normalizeTime(t time.Time, loc *time.Location, precision time.Duration) time.Time {
+	if loc != nil {
+		t = t.In(loc)
+	}
+	if precision > 0 {
+		t = t.Truncate(precision)
+	}
+	return t
+}
@@ -1,6 +1,7 @@
 package kqlfilter
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 	"strconv"
@@ -16,6 +17,7 @@ const (
 	FilterToSpannerFieldColumnTypeFloat64
 	FilterToSpannerFieldColumnTypeBool
 	FilterToSpannerFieldColumnTypeTimestamp
+	FilterToSpannerFieldColumnTypeGeography
 )
 
 func (c FilterToSpannerFieldColumnType) String() string {
@@ -30,6 +32,8 @@ func (c FilterToSpannerFieldColumnType) String() string {
 		return "BOOL"
 	case FilterToSpannerFieldColumnTypeTimestamp:
 		return "TIMESTAMP"
+	case FilterToSpannerFieldColumnTypeGeography:
+		return "GEOGRAPHY"
 	default:
 		return "???"
 	}
@@ -45,6 +49,20 @@ type FilterToSpannerFieldConfig struct {
 	AllowPrefixMatch bool
 	// Allow multiple values for this field. Defaults to false.
 	AllowMultipleValues bool
+	// Allow range operators (`>`, `>=`, `<`, `<=`) with lexicographic comparison, e.g. for cursors on KSUID or ULID
+	// identifiers. Only applicable for FilterToSpannerFieldColumnTypeString. Defaults to false.
+	AllowStringRanges bool
+	// Operators (as in Clause.Operator) that may be used with this field. Defaults to all supported operators.
+	AllowedOperators []string
+	// Trim whitespace from values, remove duplicate values and sort them before building the statement, so that
+	// equivalent filters result in identical statements and params. Defaults to false.
+	NormalizeValues bool
+	// Location parsed timestamps are converted to, e.g. time.UTC.
+	// Only applicable for FilterToSpannerFieldColumnTypeTimestamp. Defaults to keeping the offset given by the user.
+	TimestampLocation *time.Location
+	// Precision parsed timestamps are truncated to, e.g. time.Microsecond to match Spanner's TIMESTAMP precision.
+	// Only applicable for FilterToSpannerFieldColumnTypeTimestamp. Defaults to no truncation.
+	TimestampPrecision time.Duration
 	// A function that takes a string value as provided by the user and converts it to `any` result that matches how it is
 	// stored in the database. This should return an error when the user is providing a value that is illegal for this
 	// particular field. Defaults to using the provided value as-is.
@@ -160,7 +178,7 @@ func (f FilterToSpannerFieldConfig) convertValue(value string) (any, error) {
 		if err != nil {
 			return nil, fmt.Errorf("invalid TIMESTAMP value: %w", err)
 		}
-		return t, nil
+		return normalizeTime(t, f.TimestampLocation, f.TimestampPrecision), nil
 	default:
 		return value, nil
 	}
@@ -221,7 +239,14 @@ func unwrapSlice(v any) any {
 //		"@KQL1": "T2"
 //	}
 //
-// Note: The Clause Operator is contextually used/ignored. It only works with INT64, FLOAT64 and TIMESTAMP types currently.
+// Geo-distance clauses (`DWITHIN`) are only supported for FilterToSpannerFieldColumnTypeGeography fields:
+//
+//	["ST_DWITHIN(location, ST_GEOGPOINT(@KQL0, @KQL1), @KQL2)"]
+//
+// with the longitude, latitude and distance in meters as float64 params.
+//
+// Note: The Clause Operator is contextually used/ignored. It only works with INT64, FLOAT64 and TIMESTAMP types currently,
+// and with STRING types when AllowStringRanges is set.
 func (f Filter) ToSpannerSQL(fieldConfigs map[string]FilterToSpannerFieldConfig) ([]string, map[string]any, error) {
 	var condAnds []string
 	params := make(map[string]any)
@@ -231,26 +256,57 @@ func (f Filter) ToSpannerSQL(fieldConfigs map[string]FilterToSpannerFieldConfig)
 	for _, clause := range f.Clauses {
 		fieldConfig, ok := fieldConfigs[clause.Field]
 		if !ok {
-			return nil, nil, fmt.Errorf("unknown field: %s", clause.Field)
+			return nil, nil, errors.New(unknownFieldMessage(clause.Field, fieldConfigs))
+		}
+
+		if err := checkOperatorAllowed(clause.Operator, fieldConfig.AllowedOperators); err != nil {
+			return nil, nil, fmt.Errorf("field %s: %w", clause.Field, err)
 		}
 
 		columnName := fieldConfig.ColumnName
 		if columnName == "" {
 			columnName = clause.Field
 		}
-		mappedValue, err := fieldConfig.mapValues(clause.Values)
+
+		if clause.Operator == "DWITHIN" || fieldConfig.ColumnType == FilterToSpannerFieldColumnTypeGeography {
+			if clause.Operator != "DWITHIN" || fieldConfig.ColumnType != FilterToSpannerFieldColumnTypeGeography {
+				return nil, nil, fmt.Errorf("operator %s not supported for field type %s", clause.Operator, fieldConfig.ColumnType)
+			}
+			lat, lon, distance, err := parseDistanceValues(clause.Values)
+			if err != nil {
+				return nil, nil, fmt.Errorf("field %s: %w", clause.Field, err)
+			}
+			lonParam := fmt.Sprintf("%s%d", "KQL", paramIndex)
+			latParam := fmt.Sprintf("%s%d", "KQL", paramIndex+1)
+			distanceParam := fmt.Sprintf("%s%d", "KQL", paramIndex+2)
+			condAnds = append(condAnds, fmt.Sprintf("ST_DWITHIN(%s, ST_GEOGPOINT(@%s, @%s), @%s)", columnName, lonParam, latParam, distanceParam))
+			params[lonParam] = lon
+			params[latParam] = lat
+			params[distanceParam] = distance
+			paramIndex += 3
+			continue
+		}
+
+		values := clause.Values
+		if fieldConfig.NormalizeValues {
+			values = normalizeValues(values)
+		}
+
+		mappedValue, err := fieldConfig.mapValues(values)
 		if err != nil {
 			return nil, nil, fmt.Errorf("field %s: %w", clause.Field, err)
 		}
 
 		operator := clause.Operator
 
-		if len(clause.Values) > 1 && operator != "IN" {
+		if len(values) > 1 && operator != "IN" {
 			return nil, nil, fmt.Errorf("operator %s doesn't support multiple values in field: %s", operator, clause.Field)
 		}
 
 		whereClauseFormat := "%s%s@%s"
 		switch operator {
+		case "!=", "NOT IN":
+			return nil, nil, fmt.Errorf("operator %s not supported in field: %s", operator, clause.Field)
 		case "IN":
 			switch fieldConfig.ColumnType {
 			case FilterToSpannerFieldColumnTypeString:
@@ -289,6 +345,10 @@ func (f Filter) ToSpannerSQL(fieldConfigs map[string]FilterToSpannerFieldConfig)
 			switch fieldConfig.ColumnType {
 			case FilterToSpannerFieldColumnTypeInt64, FilterToSpannerFieldColumnTypeFloat64, FilterToSpannerFieldColumnTypeTimestamp:
 				break
+			case FilterToSpannerFieldColumnTypeString:
+				if !fieldConfig.AllowStringRanges {
+					return nil, nil, fmt.Errorf("operator %s not supported for field type %s", operator, fieldConfig.ColumnType)
+				}
 			default:
 				return nil, nil, fmt.Errorf("operator %s not supported for field type %s", operator, fieldConfig.ColumnType)
 			}
@@ -313,6 +313,47 @@ func TestToSpannerSQL(t *testing.T) {
 				"KQL3": time.Date(2023, time.June, 1, 23, 0, 0, 200000000, time.UTC),
 			},
 		},
+		{
+			"timestamp normalization",
+			"date>=\"2023-06-01T23:00:00.123456789+02:00\"",
+			true,
+			map[string]FilterToSpannerFieldConfig{
+				"date": {
+					ColumnType:         FilterToSpannerFieldColumnTypeTimestamp,
+					TimestampLocation:  time.UTC,
+					TimestampPrecision: time.Microsecond,
+				},
+			},
+			false,
+			"(date>=@KQL0)",
+			map[string]any{
+				"KQL0": time.Date(2023, time.June, 1, 21, 0, 0, 123456000, time.UTC),
+			},
+		},
+		{
+			"range on string field",
+			"id>2Nz7QeQJ6dRRqeHzWNQ7eJXZr5k",
+			true,
+			map[string]FilterToSpannerFieldConfig{
+				"id": {},
+			},
+			true, // operator > not supported for field type STRING
+			"",
+			map[string]any{},
+		},
+		{
+			"range on string field with string ranges allowed",
+			"id>2Nz7QeQJ6dRRqeHzWNQ7eJXZr5k",
+			true,
+			map[string]FilterToSpannerFieldConfig{
+				"id": {AllowStringRanges: true},
+			},
+			false,
+			"(id>@KQL0)",
+			map[string]any{
+				"KQL0": "2Nz7QeQJ6dRRqeHzWNQ7eJXZr5k",
+			},
+		},
 		{
 			"repeat query on same field more than allowed",
 			"count>=1 and count<5 and count>3",
@@ -407,6 +448,83 @@ func TestToSpannerSQL(t *testing.T) {
 			"",
 			map[string]any{},
 		},
+		{
+			"negation",
+			"not userId:12345",
+			false,
+			map[string]FilterToSpannerFieldConfig{
+				"userId": {
+					ColumnName: "user_id",
+					ColumnType: FilterToSpannerFieldColumnTypeInt64,
+				},
+			},
+			true, // operator != not supported
+			"",
+			map[string]any{},
+		},
+		{
+			"normalized values",
+			`team:(psv or ajax or psv) name:" Beau "`,
+			false,
+			map[string]FilterToSpannerFieldConfig{
+				"team": {
+					AllowMultipleValues: true,
+					NormalizeValues:     true,
+				},
+				"name": {
+					NormalizeValues: true,
+				},
+			},
+			false,
+			"(team IN UNNEST(@KQL0) AND name=@KQL1)",
+			map[string]any{
+				"KQL0": []string{"ajax", "psv"},
+				"KQL1": "Beau",
+			},
+		},
+		{
+			"distance",
+			"distance(location, 52.3, 4.9) <= 10km type:stadium",
+			false,
+			map[string]FilterToSpannerFieldConfig{
+				"location": {
+					ColumnType: FilterToSpannerFieldColumnTypeGeography,
+				},
+				"type": {},
+			},
+			false,
+			"(ST_DWITHIN(location, ST_GEOGPOINT(@KQL0, @KQL1), @KQL2) AND type=@KQL3)",
+			map[string]any{
+				"KQL0": 4.9,
+				"KQL1": 52.3,
+				"KQL2": float64(10000),
+				"KQL3": "stadium",
+			},
+		},
+		{
+			"distance on non-geography field",
+			"distance(location, 52.3, 4.9) <= 10km",
+			false,
+			map[string]FilterToSpannerFieldConfig{
+				"location": {},
+			},
+			true,
+			"",
+			map[string]any{},
+		},
+		{
+			"equality on geography field",
+			"location:here",
+			false,
+			map[string]FilterToSpannerFieldConfig{
+				"location": {
+					ColumnType: FilterToSpannerFieldColumnTypeGeography,
+				},
+			},
+			true,
+			"",
+			map[string]any{},
+		},
 	}
 
 	for _, test := range testCases {
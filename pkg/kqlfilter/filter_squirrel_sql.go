@@ -19,18 +19,78 @@ const (
 	FilterToSquirrelSqlFieldColumnTypeFloat
 	FilterToSquirrelSqlFieldColumnTypeBool
 	FilterToSquirrelSqlFieldColumnTypeTimestamp
+	// PostGIS geography column, only supporting geo-distance clauses (`DWITHIN`).
+	FilterToSquirrelSqlFieldColumnTypeGeography
+)
+
+type FilterToSquirrelSqlJSONDialect int
+
+const (
+	FilterToSquirrelSqlJSONDialectPostgres = iota
+	FilterToSquirrelSqlJSONDialectMySQL
+)
+
+type FilterToSquirrelSqlTSQuery int
+
+const (
+	FilterToSquirrelSqlTSQueryNone = iota
+	// websearch_to_tsquery, accepting web search engine syntax such as quoted phrases, `or` and `-word`.
+	FilterToSquirrelSqlTSQueryWebSearchToTSQuery
+	// plainto_tsquery, requiring all words of the value.
+	FilterToSquirrelSqlTSQueryPlainToTSQuery
+	// to_tsquery, requiring all words of the value. Supports prefix matching via AllowPrefixMatch.
+	FilterToSquirrelSqlTSQueryToTSQuery
 )
 
 type FilterToSquirrelSqlFieldConfig struct {
 	// SQL table column name. Can be omitted if the column name is equal to the key in the fieldConfigs map.
 	ColumnName string
 	// SQL column type. Defaults to FilterToSquirrelSqlFieldColumnTypeString.
+	// When JSONPath is set, this is the type the extracted JSON value is cast to.
 	ColumnType FilterToSquirrelSqlFieldColumnType
+	// Path of the value inside a JSON column, with nested keys separated by dots (e.g. "address.city").
+	// If set, ColumnName must refer to a JSON column and the value at this path is compared instead of the column itself.
+	JSONPath string
+	// SQL dialect used to extract the value at JSONPath. Defaults to FilterToSquirrelSqlJSONDialectPostgres.
+	JSONDialect FilterToSquirrelSqlJSONDialect
 	// Allow prefix matching when a wildcard (`*`) is present at the end of a string.
 	// Only applicable for FilterToSpannerFieldColumnTypeString. Defaults to false.
 	AllowPrefixMatch bool
 	// Allow multiple values for this field. Defaults to false.
 	AllowMultipleValues bool
+	// Reject range operators (`>`, `>=`, `<`, `<=`), which compare strings lexicographically.
+	// Only applicable for FilterToSquirrelSqlFieldColumnTypeString. Defaults to false, unlike with ToSpannerSQL, where
+	// they have to be allowed with AllowStringRanges.
+	DisallowStringRanges bool
+	// Operators (as in Clause.Operator) that may be used with this field. Defaults to all supported operators.
+	// Also applies to CustomBuilder and CustomSqlizer.
+	AllowedOperators []string
+	// Trim whitespace from values, remove duplicate values and sort them before building the statement, so that
+	// equivalent filters result in identical statements and args. Does not apply to CustomBuilder and CustomSqlizer.
+	// Defaults to false.
+	NormalizeValues bool
+	// Location parsed timestamps are converted to, e.g. time.UTC.
+	// Only applicable for FilterToSquirrelSqlFieldColumnTypeTimestamp. Defaults to keeping the offset given by the user.
+	TimestampLocation *time.Location
+	// Precision parsed timestamps are truncated to, e.g. time.Microsecond for databases storing microseconds.
+	// Only applicable for FilterToSquirrelSqlFieldColumnTypeTimestamp. Defaults to no truncation.
+	TimestampPrecision time.Duration
+	// Match values against a MySQL full-text index on the column using `MATCH(col) AGAINST (? IN BOOLEAN MODE)`
+	// instead of comparing them. Every word of the value is required to be present, and with AllowPrefixMatch a
+	// trailing wildcard matches words starting with the last word. Boolean mode operators in the user input are removed.
+	// Only applicable for FilterToSquirrelSqlFieldColumnTypeString. Defaults to false.
+	FullTextSearch bool
+	// Match values against a Postgres text search vector using `col @@ websearch_to_tsquery(?)` (or another tsquery
+	// function) instead of comparing them. ColumnName must be a tsvector column or expression,
+	// e.g. "to_tsvector('english', bio)". Only applicable for FilterToSquirrelSqlFieldColumnTypeString.
+	// Defaults to FilterToSquirrelSqlTSQueryNone.
+	TSQuery FilterToSquirrelSqlTSQuery
+	// Text search configuration passed to the tsquery function, e.g. "english".
+	// Defaults to the database's default_text_search_config.
+	TSConfig string
+	// Let negated clauses (`!=` and `NOT IN`) also match rows where the column is NULL, e.g. `col <> ? OR col IS NULL`.
+	// Defaults to false, which follows SQL semantics where a NULL column never matches a negated comparison.
+	NegationMatchesNull bool
 	// A function that takes a string value as provided by the user and converts it to string result that matches how it
 	// should be as users' input. This should return an error when the user is providing a value that is illegal or unexpected
 	// for this particular field. Defaults to using the provided value as-is.
@@ -38,6 +98,10 @@ type FilterToSquirrelSqlFieldConfig struct {
 	// A function that handle parsing the sql statement by itself.
 	// If set, all other fields in the config will be ignored
 	CustomBuilder func(stmt sq.SelectBuilder, operator string, values []string) (sq.SelectBuilder, error)
+	// A function that builds the condition for this field by itself.
+	// Unlike CustomBuilder it is also used by ToSqlizer. If set, all other fields in the config except CustomBuilder
+	// will be ignored.
+	CustomSqlizer func(operator string, values []string) (sq.Sqlizer, error)
 }
 
 // ToSquirrelSql parses a Filter and attach the result the given squirrel sql select builder.
@@ -76,7 +140,7 @@ func (f Filter) ToSquirrelSql(stmt sq.SelectBuilder, fieldConfigs map[string]Fil
 	for i, clause := range f.Clauses {
 		fieldConfig, ok := fieldConfigs[clause.Field]
 		if !ok {
-			return stmt, errors.Wrapf(unknownFieldErr, "unknown field: %s", clause.Field)
+			return stmt, errors.Wrap(unknownFieldErr, unknownFieldMessage(clause.Field, fieldConfigs))
 		}
 
 		stmt, err = clause.ToSquirrelSql(stmt, fieldConfig)
@@ -91,6 +155,9 @@ func (c *Clause) ToSquirrelSql(stmt sq.SelectBuilder, config FilterToSquirrelSql
 	var err error
 	// use customer parser if provided
 	if config.CustomBuilder != nil {
+		if err = checkOperatorAllowed(c.Operator, config.AllowedOperators); err != nil {
+			return stmt, errors.Wrap(operatorError, err.Error())
+		}
 		stmt, err = config.CustomBuilder(stmt, c.Operator, c.Values)
 		if err != nil {
 			return stmt, err
@@ -98,131 +165,449 @@ func (c *Clause) ToSquirrelSql(stmt sq.SelectBuilder, config FilterToSquirrelSql
 		return stmt, nil
 	}
 
+	cond, err := c.ToSqlizer(config)
+	if err != nil {
+		return stmt, err
+	}
+	return stmt.Where(cond), nil
+}
+
+// ToSqlizer parses a Filter into a squirrel sql expression.
+//
+// It works like ToSquirrelSql, but instead of attaching where clauses to a select builder it returns a single
+// sq.Sqlizer combining all clauses with AND. The result can be nested in other expressions (e.g. sq.Or) or attached
+// to any squirrel builder:
+//
+//	cond, err := filter.ToSqlizer(fieldConfigs)
+//	if err != nil {
+//		return err
+//	}
+//	stmt = stmt.Where(sq.Or{cond, sq.Eq{"public": true}})
+//
+// Fields configured with a CustomBuilder can not be converted, use CustomSqlizer instead.
+func (f Filter) ToSqlizer(fieldConfigs map[string]FilterToSquirrelSqlFieldConfig) (sq.Sqlizer, error) {
+	conds := make(sq.And, 0, len(f.Clauses))
+
+	for i, clause := range f.Clauses {
+		fieldConfig, ok := fieldConfigs[clause.Field]
+		if !ok {
+			return nil, errors.Wrap(unknownFieldErr, unknownFieldMessage(clause.Field, fieldConfigs))
+		}
+
+		cond, err := clause.ToSqlizer(fieldConfig)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse clause %d to squirrel sql expression", i)
+		}
+		conds = append(conds, cond)
+	}
+	return conds, nil
+}
+
+var customBuilderErr = errors.Errorf("custom builder is not supported")
+
+func (c *Clause) ToSqlizer(config FilterToSquirrelSqlFieldConfig) (sq.Sqlizer, error) {
+	if err := checkOperatorAllowed(c.Operator, config.AllowedOperators); err != nil {
+		return nil, errors.Wrap(operatorError, err.Error())
+	}
+	if config.CustomSqlizer != nil {
+		return config.CustomSqlizer(c.Operator, c.Values)
+	}
+	if config.CustomBuilder != nil {
+		return nil, errors.Wrapf(customBuilderErr, "field %s has a CustomBuilder, use CustomSqlizer instead", c.Field)
+	}
+
 	// get field name
 	columnName := config.ColumnName
 	if columnName == "" {
 		columnName = c.Field
 	}
+	if config.JSONPath != "" {
+		var err error
+		columnName, err = jsonPathExpression(columnName, config)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if c.Operator == "DWITHIN" || config.ColumnType == FilterToSquirrelSqlFieldColumnTypeGeography {
+		if c.Operator != "DWITHIN" || config.ColumnType != FilterToSquirrelSqlFieldColumnTypeGeography {
+			return nil, errors.Wrapf(operatorError, "unsupported operator %s for field %s", c.Operator, c.Field)
+		}
+		lat, lon, distance, err := parseDistanceValues(c.Values)
+		if err != nil {
+			return nil, errors.Wrap(valueConvertErr, err.Error())
+		}
+		return sq.Expr(fmt.Sprintf("ST_DWithin(%s, ST_SetSRID(ST_MakePoint(?, ?), 4326)::geography, ?)", columnName), lon, lat, distance), nil
+	}
+
+	values := c.Values
+	if config.NormalizeValues {
+		values = normalizeValues(values)
+	}
 
 	// use MapValue function in config if provided
-	rawValues := make([]any, 0, len(c.Values))
+	rawValues := make([]any, 0, len(values))
 	if config.MapValue != nil {
 		mappedValues := make([]any, 0, len(rawValues))
-		for i := range c.Values {
-			mappedValue, err := config.MapValue(c.Values[i])
+		for i := range values {
+			mappedValue, err := config.MapValue(values[i])
 			if err != nil {
-				return stmt, err
+				return nil, err
 			}
 			mappedValues = append(mappedValues, mappedValue)
 		}
 		rawValues = mappedValues
 	} else {
-		for i := range c.Values {
-			rawValues = append(rawValues, c.Values[i])
+		for i := range values {
+			rawValues = append(rawValues, values[i])
 		}
 	}
 
+	var cond sq.Sqlizer
+	var err error
 	switch config.ColumnType {
 	case FilterToSquirrelSqlFieldColumnTypeInt:
 		nativeValues := make([]int64, 0, len(rawValues))
 		for i, v := range rawValues {
 			nativeValue, err := any2Int64(v)
 			if err != nil {
-				return stmt, errors.Wrapf(err, "failed to convert value %+v at index %d to int64", v, i)
+				return nil, errors.Wrapf(err, "failed to convert value %+v at index %d to int64", v, i)
 			}
 			nativeValues = append(nativeValues, nativeValue)
 		}
-		stmt, err = buildStmtByOperator[int64](stmt, columnName, c.Operator, nativeValues, config)
+		cond, err = buildSqlizerByOperator[int64](columnName, c.Operator, nativeValues, config)
 	case FilterToSquirrelSqlFieldColumnTypeFloat:
 		nativeValues := make([]float64, 0, len(rawValues))
 		for i, v := range rawValues {
 			nativeValue, err := any2Float64(v)
 			if err != nil {
-				return stmt, errors.Wrapf(valueConvertErr, "failed to convert value %s (index %d in filter c values) to float64", v, i)
+				return nil, errors.Wrapf(valueConvertErr, "failed to convert value %s (index %d in filter c values) to float64", v, i)
 			}
 			nativeValues = append(nativeValues, nativeValue)
 		}
-		stmt, err = buildStmtByOperator[float64](stmt, columnName, c.Operator, nativeValues, config)
+		cond, err = buildSqlizerByOperator[float64](columnName, c.Operator, nativeValues, config)
 	case FilterToSquirrelSqlFieldColumnTypeBool:
 		nativeValues := make([]bool, 0, len(rawValues))
 		for i, v := range rawValues {
 			nativeValue, err := any2Bool(v)
 			if err != nil {
-				return stmt, errors.Wrapf(valueConvertErr, "failed to convert value %s (index %d in filter c values) to bool", v, i)
+				return nil, errors.Wrapf(valueConvertErr, "failed to convert value %s (index %d in filter c values) to bool", v, i)
 			}
 			nativeValues = append(nativeValues, nativeValue)
 		}
-		stmt, err = buildStmtByOperator[bool](stmt, columnName, c.Operator, nativeValues, config)
+		cond, err = buildSqlizerByOperator[bool](columnName, c.Operator, nativeValues, config)
 	case FilterToSquirrelSqlFieldColumnTypeTimestamp:
 		nativeValues := make([]time.Time, 0, len(rawValues))
 		for i, v := range rawValues {
 			nativeValue, err := any2Time(v)
 			if err != nil {
-				return stmt, errors.Wrapf(valueConvertErr, "failed to convert value %s (index %d in filter c values) to time.Time", v, i)
+				return nil, errors.Wrapf(valueConvertErr, "failed to convert value %s (index %d in filter c values) to time.Time", v, i)
 			}
-			nativeValues = append(nativeValues, nativeValue)
+			nativeValues = append(nativeValues, normalizeTime(nativeValue, config.TimestampLocation, config.TimestampPrecision))
 		}
-		stmt, err = buildStmtByOperator[time.Time](stmt, columnName, c.Operator, nativeValues, config)
+		cond, err = buildSqlizerByOperator[time.Time](columnName, c.Operator, nativeValues, config)
 	default:
 		nativeValues := make([]string, 0, len(rawValues))
-		for i, v := range rawValues {
-			nativeValue := any2Str(v)
-			if err != nil {
-				return stmt, errors.Wrapf(valueConvertErr, "failed to convert value %s (index %d in filter c values) to time.Time", v, i)
+		for _, v := range rawValues {
+			nativeValues = append(nativeValues, any2Str(v))
+		}
+		if config.FullTextSearch {
+			cond, err = buildFullTextSqlizer(columnName, c.Operator, nativeValues, config, mysqlFullTextMatch(columnName, config))
+			break
+		}
+		if config.TSQuery != FilterToSquirrelSqlTSQueryNone {
+			cond, err = buildFullTextSqlizer(columnName, c.Operator, nativeValues, config, postgresTSQueryMatch(columnName, config))
+			break
+		}
+		switch c.Operator {
+		case ">", ">=", "<", "<=":
+			if config.DisallowStringRanges {
+				return nil, errors.Wrapf(operatorError, "operator %s not supported for string field %s", c.Operator, c.Field)
 			}
-			nativeValues = append(nativeValues, nativeValue)
 		}
-		stmt, err = buildStmtByOperator[string](stmt, columnName, c.Operator, nativeValues, config)
+		cond, err = buildSqlizerByOperator[string](columnName, c.Operator, nativeValues, config)
 	}
 
 	if err != nil {
-		return stmt, errors.Wrapf(err, "failed to build statement by operator")
+		return nil, errors.Wrapf(err, "failed to build statement by operator")
 	}
-	return stmt, nil
+	return cond, nil
 }
 
 var emptyValuesErr = errors.Errorf("no values provided")
 var valuesNumError = errors.Errorf("wrong values num")
 var operatorError = errors.Errorf("unsupported operator")
 
-func buildStmtByOperator[T string | int64 | float64 | bool | time.Time](stmt sq.SelectBuilder, columnName string, op string, values []T, config FilterToSquirrelSqlFieldConfig) (sq.SelectBuilder, error) {
+func buildSqlizerByOperator[T string | int64 | float64 | bool | time.Time](columnName string, op string, values []T, config FilterToSquirrelSqlFieldConfig) (sq.Sqlizer, error) {
 	switch op {
 	case "IN":
 		if len(values) == 0 {
-			return stmt, emptyValuesErr
+			return nil, emptyValuesErr
+		}
+		if len(values) > 1 && !config.AllowMultipleValues {
+			return nil, errors.Wrapf(valuesNumError, "values num %d doesn't match the operator %s", len(values), op)
+		}
+		return sq.Eq{columnName: values}, nil
+	case "NOT IN":
+		if len(values) == 0 {
+			return nil, emptyValuesErr
 		}
 		if len(values) > 1 && !config.AllowMultipleValues {
-			return stmt, errors.Wrapf(valuesNumError, "values num %d doesn't match the operator %s", len(values), op)
+			return nil, errors.Wrapf(valuesNumError, "values num %d doesn't match the operator %s", len(values), op)
+		}
+		return negate(sq.NotEq{columnName: values}, columnName, config), nil
+	case "!=":
+		if len(values) != 1 {
+			return nil, errors.Wrapf(valuesNumError, "values num %d doesn't match the operator %s", len(values), op)
 		}
-		stmt = stmt.Where(sq.Eq{columnName: values})
+		if vStr, ok := any(values[0]).(string); ok && config.AllowPrefixMatch && strings.HasSuffix(vStr, "*") && !strings.HasSuffix(vStr, `\*`) {
+			return negate(sq.NotLike{columnName: escapeLikePrefix(vStr)}, columnName, config), nil
+		}
+		return negate(sq.NotEq{columnName: values[0]}, columnName, config), nil
 	case "=", ">", ">=", "<", "<=":
 		if len(values) != 1 {
-			return stmt, errors.Wrapf(valuesNumError, "values num %d doesn't match the operator %s", len(values), op)
+			return nil, errors.Wrapf(valuesNumError, "values num %d doesn't match the operator %s", len(values), op)
 		}
 		switch op {
 		case "=":
 			if vStr, ok := any(values[0]).(string); ok && config.AllowPrefixMatch && strings.HasSuffix(vStr, "*") && !strings.HasSuffix(vStr, `\*`) {
-				vStr = vStr[:len(vStr)-1]                  // trim the suffix * ( don't use the TrimRightFunc because it'll also remove the first start from suffix "**"
-				vStr = strings.ReplaceAll(vStr, `\`, `\\`) // escape all `\`
-				vStr = strings.ReplaceAll(vStr, `%`, `\%`) // escape all `%`
-				vStr = strings.ReplaceAll(vStr, `_`, `\_`) // escape all `_`
-				stmt = stmt.Where(sq.Like{columnName: vStr + "%"})
-			} else {
-				stmt = stmt.Where(sq.Eq{columnName: values[0]})
+				return sq.Like{columnName: escapeLikePrefix(vStr)}, nil
 			}
+			return sq.Eq{columnName: values[0]}, nil
 		case ">":
-			stmt = stmt.Where(sq.Gt{columnName: values[0]})
+			return sq.Gt{columnName: values[0]}, nil
 		case ">=":
-			stmt = stmt.Where(sq.GtOrEq{columnName: values[0]})
+			return sq.GtOrEq{columnName: values[0]}, nil
 		case "<":
-			stmt = stmt.Where(sq.Lt{columnName: values[0]})
-		case "<=":
-			stmt = stmt.Where(sq.LtOrEq{columnName: values[0]})
+			return sq.Lt{columnName: values[0]}, nil
+		default:
+			return sq.LtOrEq{columnName: values[0]}, nil
 		}
 	default:
-		return stmt, errors.Wrapf(operatorError, "unsupported operator %s", op)
+		return nil, errors.Wrapf(operatorError, "unsupported operator %s", op)
 	}
-	return stmt, nil
+}
+
+// buildFullTextSqlizer builds a full-text search condition, using match to build the condition for a single value.
+// Multiple values are OR'ed and negated operators wrap the condition with NOT.
+func buildFullTextSqlizer(columnName string, op string, values []string, config FilterToSquirrelSqlFieldConfig, match func(value string) (sq.Sqlizer, error)) (sq.Sqlizer, error) {
+	switch op {
+	case "IN", "NOT IN":
+		if len(values) == 0 {
+			return nil, emptyValuesErr
+		}
+		if len(values) > 1 && !config.AllowMultipleValues {
+			return nil, errors.Wrapf(valuesNumError, "values num %d doesn't match the operator %s", len(values), op)
+		}
+	case "=", "!=":
+		if len(values) != 1 {
+			return nil, errors.Wrapf(valuesNumError, "values num %d doesn't match the operator %s", len(values), op)
+		}
+	default:
+		return nil, errors.Wrapf(operatorError, "unsupported operator %s for full-text search", op)
+	}
+
+	conds := make(sq.Or, 0, len(values))
+	for _, v := range values {
+		cond, err := match(v)
+		if err != nil {
+			return nil, err
+		}
+		conds = append(conds, cond)
+	}
+
+	var cond sq.Sqlizer = conds
+	if len(conds) == 1 {
+		cond = conds[0]
+	}
+	if op == "!=" || op == "NOT IN" {
+		sql, args, err := cond.ToSql()
+		if err != nil {
+			return nil, err
+		}
+		return negate(sq.Expr("NOT "+sql, args...), columnName, config), nil
+	}
+	return cond, nil
+}
+
+// mysqlFullTextMatch returns a function matching a value against a MySQL full-text index on the given column.
+func mysqlFullTextMatch(columnName string, config FilterToSquirrelSqlFieldConfig) func(string) (sq.Sqlizer, error) {
+	matchSql := fmt.Sprintf("MATCH(%s) AGAINST (? IN BOOLEAN MODE)", columnName)
+	return func(value string) (sq.Sqlizer, error) {
+		query, err := fullTextQuery(value, config.AllowPrefixMatch)
+		if err != nil {
+			return nil, err
+		}
+		return sq.Expr(matchSql, query), nil
+	}
+}
+
+// postgresTSQueryMatch returns a function matching a value against a Postgres text search vector.
+func postgresTSQueryMatch(columnName string, config FilterToSquirrelSqlFieldConfig) func(string) (sq.Sqlizer, error) {
+	var function string
+	switch config.TSQuery {
+	case FilterToSquirrelSqlTSQueryToTSQuery:
+		function = "to_tsquery"
+	case FilterToSquirrelSqlTSQueryPlainToTSQuery:
+		function = "plainto_tsquery"
+	default:
+		function = "websearch_to_tsquery"
+	}
+	return func(value string) (sq.Sqlizer, error) {
+		query := value
+		if config.TSQuery == FilterToSquirrelSqlTSQueryToTSQuery {
+			var err error
+			query, err = tsQuery(value, config.AllowPrefixMatch)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if config.TSConfig != "" {
+			return sq.Expr(fmt.Sprintf("%s @@ %s(?, ?)", columnName, function), config.TSConfig, query), nil
+		}
+		return sq.Expr(fmt.Sprintf("%s @@ %s(?)", columnName, function), query), nil
+	}
+}
+
+// tsQueryOperators are the characters with a special meaning in a Postgres to_tsquery input.
+const tsQueryOperators = `&|!()<>:*'\`
+
+// tsQuery turns a user value into a to_tsquery input requiring all of its words.
+func tsQuery(value string, allowPrefixMatch bool) (string, error) {
+	prefix := allowPrefixMatch && strings.HasSuffix(value, "*") && !strings.HasSuffix(value, `\*`)
+	words := strings.Fields(strings.Map(func(r rune) rune {
+		if strings.ContainsRune(tsQueryOperators, r) {
+			return ' '
+		}
+		return r
+	}, value))
+	if len(words) == 0 {
+		return "", errors.Wrapf(emptyValuesErr, "value %q contains no words to search for", value)
+	}
+
+	query := strings.Join(words, " & ")
+	if prefix {
+		query += ":*"
+	}
+	return query, nil
+}
+
+// fullTextOperators are the characters with a special meaning in a MySQL boolean mode full-text search.
+const fullTextOperators = `+-<>()~*"@`
+
+// fullTextQuery turns a user value into a boolean mode full-text query requiring all of its words.
+func fullTextQuery(value string, allowPrefixMatch bool) (string, error) {
+	prefix := allowPrefixMatch && strings.HasSuffix(value, "*") && !strings.HasSuffix(value, `\*`)
+	words := strings.Fields(strings.Map(func(r rune) rune {
+		if strings.ContainsRune(fullTextOperators, r) {
+			return ' '
+		}
+		return r
+	}, value))
+	if len(words) == 0 {
+		return "", errors.Wrapf(emptyValuesErr, "value %q contains no words to search for", value)
+	}
+
+	var sb strings.Builder
+	for i, word := range words {
+		if i > 0 {
+			sb.WriteString(" ")
+		}
+		sb.WriteString("+")
+		sb.WriteString(word)
+	}
+	if prefix {
+		sb.WriteString("*")
+	}
+	return sb.String(), nil
+}
+
+// escapeLikePrefix turns a string with a trailing wildcard into a LIKE pattern.
+func escapeLikePrefix(vStr string) string {
+	vStr = vStr[:len(vStr)-1]                  // trim the suffix * ( don't use the TrimRightFunc because it'll also remove the first start from suffix "**"
+	vStr = strings.ReplaceAll(vStr, `\`, `\\`) // escape all `\`
+	vStr = strings.ReplaceAll(vStr, `%`, `\%`) // escape all `%`
+	vStr = strings.ReplaceAll(vStr, `_`, `\_`) // escape all `_`
+	return vStr + "%"
+}
+
+// negate adds the `col IS NULL` alternative to a negated condition if the field config asks for it.
+func negate(cond sq.Sqlizer, columnName string, config FilterToSquirrelSqlFieldConfig) sq.Sqlizer {
+	if !config.NegationMatchesNull {
+		return cond
+	}
+	return sq.Or{cond, sq.Eq{columnName: nil}}
+}
+
+var jsonPathErr = errors.Errorf("invalid json path")
+
+// jsonPathExpression builds the sql expression extracting config.JSONPath from the given JSON column, cast to the
+// configured column type:
+//
+//	Postgres: data->>'locale', (data#>>'{stats,age}')::bigint
+//	MySQL:    JSON_UNQUOTE(JSON_EXTRACT(data, '$.locale')), CAST(JSON_EXTRACT(data, '$.stats.age') AS SIGNED)
+//
+// Keys may contain letters, digits, `_` and `-`.
+func jsonPathExpression(columnName string, config FilterToSquirrelSqlFieldConfig) (string, error) {
+	keys := strings.Split(config.JSONPath, ".")
+	for _, key := range keys {
+		if key == "" || strings.IndexFunc(key, func(r rune) bool {
+			return !(r == '_' || r == '-' || '0' <= r && r <= '9' || 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z')
+		}) >= 0 {
+			return "", errors.Wrapf(jsonPathErr, "invalid json path %q", config.JSONPath)
+		}
+	}
+
+	switch config.JSONDialect {
+	case FilterToSquirrelSqlJSONDialectPostgres:
+		expr := fmt.Sprintf("%s->>'%s'", columnName, keys[0])
+		if len(keys) > 1 {
+			expr = fmt.Sprintf("%s#>>'{%s}'", columnName, strings.Join(keys, ","))
+		}
+		switch config.ColumnType {
+		case FilterToSquirrelSqlFieldColumnTypeInt:
+			return "(" + expr + ")::bigint", nil
+		case FilterToSquirrelSqlFieldColumnTypeFloat:
+			return "(" + expr + ")::double precision", nil
+		case FilterToSquirrelSqlFieldColumnTypeBool:
+			return "(" + expr + ")::boolean", nil
+		case FilterToSquirrelSqlFieldColumnTypeTimestamp:
+			return "(" + expr + ")::timestamptz", nil
+		default:
+			return expr, nil
+		}
+	case FilterToSquirrelSqlJSONDialectMySQL:
+		members := make([]string, 0, len(keys))
+		for _, key := range keys {
+			members = append(members, mysqlJSONPathMember(key))
+		}
+		expr := fmt.Sprintf("JSON_EXTRACT(%s, '$.%s')", columnName, strings.Join(members, "."))
+		switch config.ColumnType {
+		case FilterToSquirrelSqlFieldColumnTypeInt:
+			return "CAST(" + expr + " AS SIGNED)", nil
+		case FilterToSquirrelSqlFieldColumnTypeFloat:
+			return "CAST(" + expr + " AS DOUBLE)", nil
+		case FilterToSquirrelSqlFieldColumnTypeBool:
+			return "CAST(" + expr + " AS UNSIGNED)", nil
+		case FilterToSquirrelSqlFieldColumnTypeTimestamp:
+			return "CAST(JSON_UNQUOTE(" + expr + ") AS DATETIME(6))", nil
+		default:
+			return "JSON_UNQUOTE(" + expr + ")", nil
+		}
+	default:
+		return "", errors.Errorf("unsupported json dialect %d", config.JSONDialect)
+	}
+}
+
+// mysqlJSONPathMember returns key as a member of a MySQL JSON path. Keys that are not identifiers, e.g. with `-` or a
+// leading digit, are quoted: $."my-key".
+func mysqlJSONPathMember(key string) string {
+	for i, r := range key {
+		if !(r == '_' || 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || i > 0 && '0' <= r && r <= '9') {
+			return `"` + key + `"`
+		}
+	}
+	return key
 }
 
 var valueConvertErr = errors.Errorf("value convert error") // used in test cases
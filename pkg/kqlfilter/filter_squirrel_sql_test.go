@@ -94,6 +94,22 @@ func TestToSquirrelSql(t *testing.T) {
 			"SELECT * FROM users WHERE birthdate > ?",
 			[]any{time.Date(1993, 11, 26, 7, 0, 0, 0, time.UTC)},
 		},
+		{
+			"timestamp normalization",
+			"birthdate>\"1993-11-26T09:00:00.123456789+02:00\"",
+			true,
+			map[string]FilterToSquirrelSqlFieldConfig{
+				"birthdate": {
+					ColumnName:         "birthdate",
+					ColumnType:         FilterToSquirrelSqlFieldColumnTypeTimestamp,
+					TimestampLocation:  time.UTC,
+					TimestampPrecision: time.Microsecond,
+				},
+			},
+			nil,
+			"SELECT * FROM users WHERE birthdate > ?",
+			[]any{time.Date(1993, 11, 26, 7, 0, 0, 123456000, time.UTC)},
+		},
 		{
 			"all type of values together",
 			"name:Beau age:30 weight:70.7 local:false favorite_day: (Monday OR Tuesday)",
@@ -203,6 +219,288 @@ func TestToSquirrelSql(t *testing.T) {
 			"SELECT * FROM users WHERE create_time < ?",
 			[]any{time.Date(2023, 01, 01, 00, 00, 00, 00, time.UTC)},
 		},
+		{
+			"negated string field",
+			"not name:Beau",
+			false,
+			map[string]FilterToSquirrelSqlFieldConfig{
+				"name": {
+					ColumnName: "name",
+					ColumnType: FilterToSpannerFieldColumnTypeString,
+				},
+			},
+			nil,
+			"SELECT * FROM users WHERE name <> ?",
+			[]any{"Beau"},
+		},
+		{
+			"negated string field matching null",
+			"not name:Beau",
+			false,
+			map[string]FilterToSquirrelSqlFieldConfig{
+				"name": {
+					ColumnName:          "name",
+					ColumnType:          FilterToSpannerFieldColumnTypeString,
+					NegationMatchesNull: true,
+				},
+			},
+			nil,
+			"SELECT * FROM users WHERE (name <> ? OR name IS NULL)",
+			[]any{"Beau"},
+		},
+		{
+			"negated string field with prefix matching",
+			"not name:Be*",
+			false,
+			map[string]FilterToSquirrelSqlFieldConfig{
+				"name": {
+					ColumnName:       "name",
+					ColumnType:       FilterToSpannerFieldColumnTypeString,
+					AllowPrefixMatch: true,
+				},
+			},
+			nil,
+			"SELECT * FROM users WHERE name NOT LIKE ?",
+			[]any{"Be%"},
+		},
+		{
+			"negated multiple values",
+			"not age:(30 OR 31)",
+			false,
+			map[string]FilterToSquirrelSqlFieldConfig{
+				"age": {
+					ColumnName:          "age",
+					ColumnType:          FilterToSpannerFieldColumnTypeInt64,
+					AllowMultipleValues: true,
+					NegationMatchesNull: true,
+				},
+			},
+			nil,
+			"SELECT * FROM users WHERE (age NOT IN (?,?) OR age IS NULL)",
+			[]any{int64(30), int64(31)},
+		},
+		{
+			"range on string field",
+			"id>2Nz7QeQJ6dRRqeHzWNQ7eJXZr5k",
+			true,
+			map[string]FilterToSquirrelSqlFieldConfig{
+				"id": {},
+			},
+			nil,
+			"SELECT * FROM users WHERE id > ?",
+			[]any{"2Nz7QeQJ6dRRqeHzWNQ7eJXZr5k"},
+		},
+		{
+			"range on string field with string ranges disallowed",
+			"id>2Nz7QeQJ6dRRqeHzWNQ7eJXZr5k",
+			true,
+			map[string]FilterToSquirrelSqlFieldConfig{
+				"id": {DisallowStringRanges: true},
+			},
+			operatorError,
+			"",
+			nil,
+		},
+		{
+			"json path postgres",
+			"locale:nl age>=18",
+			true,
+			map[string]FilterToSquirrelSqlFieldConfig{
+				"locale": {
+					ColumnName: "data",
+					JSONPath:   "locale",
+				},
+				"age": {
+					ColumnName: "data",
+					ColumnType: FilterToSquirrelSqlFieldColumnTypeInt,
+					JSONPath:   "stats.age",
+				},
+			},
+			nil,
+			"SELECT * FROM users WHERE data->>'locale' = ? AND (data#>>'{stats,age}')::bigint >= ?",
+			[]any{"nl", int64(18)},
+		},
+		{
+			"json path mysql",
+			"locale:nl age>=18",
+			true,
+			map[string]FilterToSquirrelSqlFieldConfig{
+				"locale": {
+					ColumnName:  "data",
+					JSONPath:    "locale",
+					JSONDialect: FilterToSquirrelSqlJSONDialectMySQL,
+				},
+				"age": {
+					ColumnName:  "data",
+					ColumnType:  FilterToSquirrelSqlFieldColumnTypeInt,
+					JSONPath:    "stats.age",
+					JSONDialect: FilterToSquirrelSqlJSONDialectMySQL,
+				},
+			},
+			nil,
+			"SELECT * FROM users WHERE JSON_UNQUOTE(JSON_EXTRACT(data, '$.locale')) = ? AND CAST(JSON_EXTRACT(data, '$.stats.age') AS SIGNED) >= ?",
+			[]any{"nl", int64(18)},
+		},
+		{
+			"json path with dash and leading digit postgres",
+			"theme:dark",
+			false,
+			map[string]FilterToSquirrelSqlFieldConfig{
+				"theme": {
+					ColumnName: "data",
+					JSONPath:   "ui-settings.2fa_theme",
+				},
+			},
+			nil,
+			"SELECT * FROM users WHERE data#>>'{ui-settings,2fa_theme}' = ?",
+			[]any{"dark"},
+		},
+		{
+			"json path with dash and leading digit mysql",
+			"theme:dark",
+			false,
+			map[string]FilterToSquirrelSqlFieldConfig{
+				"theme": {
+					ColumnName:  "data",
+					JSONPath:    "ui-settings.2fa_theme.mode",
+					JSONDialect: FilterToSquirrelSqlJSONDialectMySQL,
+				},
+			},
+			nil,
+			`SELECT * FROM users WHERE JSON_UNQUOTE(JSON_EXTRACT(data, '$."ui-settings"."2fa_theme".mode')) = ?`,
+			[]any{"dark"},
+		},
+		{
+			"invalid json path",
+			"locale:nl",
+			false,
+			map[string]FilterToSquirrelSqlFieldConfig{
+				"locale": {
+					ColumnName: "data",
+					JSONPath:   "locale'",
+				},
+			},
+			jsonPathErr,
+			"",
+			nil,
+		},
+		{
+			"full-text search",
+			`bio:"+go -java* developer"`,
+			false,
+			map[string]FilterToSquirrelSqlFieldConfig{
+				"bio": {
+					ColumnName:     "bio",
+					FullTextSearch: true,
+				},
+			},
+			nil,
+			"SELECT * FROM users WHERE MATCH(bio) AGAINST (? IN BOOLEAN MODE)",
+			[]any{"+go +java +developer"},
+		},
+		{
+			"full-text search with multiple values",
+			"bio:(gopher OR developer)",
+			false,
+			map[string]FilterToSquirrelSqlFieldConfig{
+				"bio": {
+					ColumnName:          "bio",
+					FullTextSearch:      true,
+					AllowPrefixMatch:    true,
+					AllowMultipleValues: true,
+				},
+			},
+			nil,
+			"SELECT * FROM users WHERE (MATCH(bio) AGAINST (? IN BOOLEAN MODE) OR MATCH(bio) AGAINST (? IN BOOLEAN MODE))",
+			[]any{"+gopher", "+developer"},
+		},
+		{
+			"full-text search with prefix",
+			"bio:develop*",
+			false,
+			map[string]FilterToSquirrelSqlFieldConfig{
+				"bio": {
+					ColumnName:       "bio",
+					FullTextSearch:   true,
+					AllowPrefixMatch: true,
+				},
+			},
+			nil,
+			"SELECT * FROM users WHERE MATCH(bio) AGAINST (? IN BOOLEAN MODE)",
+			[]any{"+develop*"},
+		},
+		{
+			"negated full-text search",
+			"not bio:java",
+			false,
+			map[string]FilterToSquirrelSqlFieldConfig{
+				"bio": {
+					ColumnName:     "bio",
+					FullTextSearch: true,
+				},
+			},
+			nil,
+			"SELECT * FROM users WHERE NOT MATCH(bio) AGAINST (? IN BOOLEAN MODE)",
+			[]any{"+java"},
+		},
+		{
+			"full-text search without words",
+			`bio:"+-"`,
+			false,
+			map[string]FilterToSquirrelSqlFieldConfig{
+				"bio": {
+					ColumnName:     "bio",
+					FullTextSearch: true,
+				},
+			},
+			emptyValuesErr,
+			"",
+			nil,
+		},
+		{
+			"postgres text search",
+			`bio:"go -java"`,
+			false,
+			map[string]FilterToSquirrelSqlFieldConfig{
+				"bio": {
+					ColumnName: "bio_tsv",
+					TSQuery:    FilterToSquirrelSqlTSQueryWebSearchToTSQuery,
+					TSConfig:   "english",
+				},
+			},
+			nil,
+			"SELECT * FROM users WHERE bio_tsv @@ websearch_to_tsquery(?, ?)",
+			[]any{"english", "go -java"},
+		},
+		{
+			"postgres to_tsquery with prefix",
+			`bio:"gopher & develop*"`,
+			false,
+			map[string]FilterToSquirrelSqlFieldConfig{
+				"bio": {
+					ColumnName:       "to_tsvector(bio)",
+					TSQuery:          FilterToSquirrelSqlTSQueryToTSQuery,
+					AllowPrefixMatch: true,
+				},
+			},
+			nil,
+			"SELECT * FROM users WHERE to_tsvector(bio) @@ to_tsquery(?)",
+			[]any{"gopher & develop:*"},
+		},
+		{
+			"negated postgres text search",
+			"not bio:java",
+			false,
+			map[string]FilterToSquirrelSqlFieldConfig{
+				"bio": {
+					ColumnName: "bio_tsv",
+					TSQuery:    FilterToSquirrelSqlTSQueryPlainToTSQuery,
+				},
+			},
+			nil,
+			"SELECT * FROM users WHERE NOT bio_tsv @@ plainto_tsquery(?)",
+			[]any{"java"},
+		},
 		{
 			"unknown field",
 			"name:Beau age:30",
@@ -268,6 +566,61 @@ func TestToSquirrelSql(t *testing.T) {
 			"SELECT * FROM users WHERE age > ? AND age > ?",
 			[]any{int64(1), int64(2)},
 		},
+		{
+			"normalized values",
+			`team:(psv or ajax or psv) name:" Beau "`,
+			false,
+			map[string]FilterToSquirrelSqlFieldConfig{
+				"team": {
+					AllowMultipleValues: true,
+					NormalizeValues:     true,
+				},
+				"name": {
+					NormalizeValues: true,
+				},
+			},
+			nil,
+			"SELECT * FROM users WHERE team IN (?,?) AND name = ?",
+			[]any{"ajax", "psv", "Beau"},
+		},
+		{
+			"distance",
+			"distance(location, 52.3, 4.9) <= 10km",
+			false,
+			map[string]FilterToSquirrelSqlFieldConfig{
+				"location": {
+					ColumnName: "home_location",
+					ColumnType: FilterToSquirrelSqlFieldColumnTypeGeography,
+				},
+			},
+			nil,
+			"SELECT * FROM users WHERE ST_DWithin(home_location, ST_SetSRID(ST_MakePoint(?, ?), 4326)::geography, ?)",
+			[]any{4.9, 52.3, float64(10000)},
+		},
+		{
+			"distance on non-geography field",
+			"distance(location, 52.3, 4.9) <= 10km",
+			false,
+			map[string]FilterToSquirrelSqlFieldConfig{
+				"location": {},
+			},
+			operatorError,
+			"",
+			nil,
+		},
+		{
+			"equality on geography field",
+			"location:here",
+			false,
+			map[string]FilterToSquirrelSqlFieldConfig{
+				"location": {
+					ColumnType: FilterToSquirrelSqlFieldColumnTypeGeography,
+				},
+			},
+			operatorError,
+			"",
+			nil,
+		},
 	}
 
 	for _, test := range testCases {
@@ -286,6 +639,81 @@ func TestToSquirrelSql(t *testing.T) {
 	}
 }
 
+func TestToSqlizer(t *testing.T) {
+	columnMap := map[string]FilterToSquirrelSqlFieldConfig{
+		"name": {
+			ColumnName: "name",
+			ColumnType: FilterToSpannerFieldColumnTypeString,
+		},
+		"age": {
+			ColumnName: "age",
+			ColumnType: FilterToSpannerFieldColumnTypeInt64,
+		},
+		"team": {
+			CustomSqlizer: func(operator string, values []string) (sq.Sqlizer, error) {
+				return sq.Expr("team_id = (SELECT id FROM teams WHERE name = ?)", values[0]), nil
+			},
+		},
+		"legacy": {
+			CustomBuilder: func(stmt sq.SelectBuilder, operator string, values []string) (sq.SelectBuilder, error) {
+				return stmt, nil
+			},
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		input         string
+		expectedError error
+		expectedSQL   string
+		expectedArgs  []any
+	}{
+		{
+			"nested in or",
+			"name:Beau age:30",
+			nil,
+			"SELECT * FROM users WHERE ((name = ? AND age = ?) OR public = ?)",
+			[]any{"Beau", int64(30), true},
+		},
+		{
+			"custom sqlizer",
+			"team:Ajax",
+			nil,
+			"SELECT * FROM users WHERE ((team_id = (SELECT id FROM teams WHERE name = ?)) OR public = ?)",
+			[]any{"Ajax", true},
+		},
+		{
+			"custom builder",
+			"legacy:value",
+			customBuilderErr,
+			"",
+			nil,
+		},
+		{
+			"unknown field",
+			"email:beau@example.com",
+			unknownFieldErr,
+			"",
+			nil,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			f, errParse := Parse(test.input, false)
+			require.NoError(t, errParse)
+			cond, err := f.ToSqlizer(columnMap)
+			require.ErrorIs(t, err, test.expectedError)
+			if test.expectedError == nil {
+				sql, args, err := sq.Select("*").From("users").Where(sq.Or{cond, sq.Eq{"public": true}}).ToSql()
+				require.NoError(t, err)
+				require.Equal(t, test.expectedSQL, sql)
+				require.Equal(t, test.expectedArgs, args)
+			}
+		})
+	}
+}
+
 func TestAny2Int(t *testing.T) {
 	successCases := []any{
 		"1",
@@ -100,6 +100,48 @@ func TestParse(t *testing.T) {
 				},
 			},
 		},
+		{
+			"negated field",
+			"not field:value",
+			false,
+			false,
+			Filter{
+				Clauses: []Clause{
+					{
+						Field:    "field",
+						Operator: "!=",
+						Values:   []string{"value"},
+					},
+				},
+			},
+		},
+		{
+			"negated or values",
+			"another:second and not field:(value OR second)",
+			false,
+			false,
+			Filter{
+				Clauses: []Clause{
+					{
+						Field:    "another",
+						Operator: "=",
+						Values:   []string{"second"},
+					},
+					{
+						Field:    "field",
+						Operator: "NOT IN",
+						Values:   []string{"value", "second"},
+					},
+				},
+			},
+		},
+		{
+			"negated range is not supported",
+			"not field>=value",
+			true,
+			true,
+			Filter{},
+		},
 		{
 			"one field with range operator",
 			"field>=value",
@@ -177,6 +219,26 @@ func TestParse(t *testing.T) {
 				},
 			},
 		},
+		{
+			"distance",
+			"distance(location, 52.3, 4.9) <= 10km type:stadium",
+			false,
+			false,
+			Filter{
+				Clauses: []Clause{
+					{
+						Field:    "location",
+						Operator: "DWITHIN",
+						Values:   []string{"52.3", "4.9", "10000"},
+					},
+					{
+						Field:    "type",
+						Operator: "=",
+						Values:   []string{"stadium"},
+					},
+				},
+			},
+		},
 	}
 
 	for _, test := range testCases {
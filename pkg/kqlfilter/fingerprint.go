@@ -0,0 +1,90 @@
+package kqlfilter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FingerprintOption is a function that configures Fingerprint.
+type FingerprintOption func(*fingerprinter)
+
+// FingerprintIncludeValues includes the values of the filter in the fingerprint.
+// By default, only the fields and operators are included.
+func FingerprintIncludeValues() FingerprintOption {
+	return func(f *fingerprinter) {
+		f.includeValues = true
+	}
+}
+
+// Fingerprint returns a stable hash of the structure of the filter, which can be used as cache key for converted
+// queries or to aggregate metrics per kind of filter.
+// Filters that only differ in the order of AND'ed or OR'ed clauses result in the same fingerprint, and by default
+// values are ignored, so `team:ajax and year>2020` and `year>1990 and team:psv` have the same fingerprint.
+func Fingerprint(node Node, options ...FingerprintOption) string {
+	f := &fingerprinter{}
+	for _, option := range options {
+		option(f)
+	}
+	sum := sha256.Sum256([]byte(f.canonical(node)))
+	return hex.EncodeToString(sum[:])
+}
+
+type fingerprinter struct {
+	includeValues bool
+}
+
+// canonical returns a normalized representation of node.
+func (f *fingerprinter) canonical(node Node) string {
+	switch n := node.(type) {
+	case *AndNode:
+		return "and(" + f.canonicalList(n.Nodes) + ")"
+	case *OrNode:
+		return "or(" + f.canonicalList(n.Nodes) + ")"
+	case *NotNode:
+		return "not(" + f.canonical(n.Expr) + ")"
+	case *IsNode:
+		return "is(" + strconv.Quote(n.Identifier) + "," + f.canonical(n.Value) + ")"
+	case *NestedNode:
+		return "nested(" + f.canonical(n.Expr) + ")"
+	case *RangeNode:
+		return "range(" + strconv.Quote(n.Identifier) + "," + n.Operator.String() + "," + f.canonical(n.Value) + ")"
+	case *DistanceNode:
+		var value string
+		if f.includeValues {
+			value = strconv.FormatFloat(n.Lat, 'f', -1, 64) + "," +
+				strconv.FormatFloat(n.Lon, 'f', -1, 64) + "," +
+				strconv.FormatFloat(n.Distance, 'f', -1, 64)
+		} else {
+			value = "?"
+		}
+		return "distance(" + strconv.Quote(n.Identifier) + "," + n.Operator.String() + "," + value + ")"
+	case *LiteralNode:
+		switch {
+		case n.Value == "*":
+			// Presence checks differ in shape from comparisons.
+			return "*"
+		case f.includeValues:
+			return strconv.Quote(n.Value)
+		case strings.HasSuffix(n.Value, "*") && !strings.HasSuffix(n.Value, `\*`):
+			// Prefix matches, e.g. LIKE instead of = in SQL.
+			return "?*"
+		default:
+			return "?"
+		}
+	default:
+		return ""
+	}
+}
+
+// canonicalList returns the sorted canonical representations of nodes, joined by commas.
+func (f *fingerprinter) canonicalList(nodes []Node) string {
+	parts := make([]string, 0, len(nodes))
+	for _, n := range nodes {
+		parts = append(parts, f.canonical(n))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
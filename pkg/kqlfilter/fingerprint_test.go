@@ -0,0 +1,57 @@
+package kqlfilter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFingerprint(t *testing.T) {
+	testCases := []struct {
+		name          string
+		a             string
+		b             string
+		includeValues bool
+		equal         bool
+	}{
+		{"same filter", "team:ajax", "team:ajax", false, true},
+		{"different values", "team:ajax and year>2020", "team:psv and year>1990", false, true},
+		{"different values included", "team:ajax", "team:psv", true, false},
+		{"different order", "team:ajax and year>2020", "year>2020 and team:ajax", true, true},
+		{"different order of values", "team:(ajax or psv)", "team:(psv or ajax)", true, true},
+		{"different number of values", "team:(ajax or psv)", "team:(ajax or psv or az)", false, false},
+		{"different fields", "team:ajax", "city:ajax", false, false},
+		{"different range operators", "year>2020", "year>=2020", false, false},
+		{"and versus or", "team:ajax and city:amsterdam", "team:ajax or city:amsterdam", false, false},
+		{"negation", "team:ajax", "not team:ajax", false, false},
+		{"presence", "team:*", "team:ajax", false, false},
+		{"prefix", "name:foo*", "name:foo", false, false},
+		{"prefixes", "name:foo*", "name:bar*", false, true},
+		{"nested", "team:{name:ajax}", "team.name:ajax", false, false},
+		{"distance", "distance(location, 52.3, 4.9) <= 10km", "distance(location, 51.9, 4.5) <= 5km", false, true},
+		{"distance values included", "distance(location, 52.3, 4.9) <= 10km", "distance(location, 51.9, 4.5) <= 5km", true, false},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			var options []FingerprintOption
+			if test.includeValues {
+				options = append(options, FingerprintIncludeValues())
+			}
+			a, err := ParseAST(test.a)
+			require.NoError(t, err)
+			b, err := ParseAST(test.b)
+			require.NoError(t, err)
+
+			fa := Fingerprint(a, options...)
+			fb := Fingerprint(b, options...)
+			assert.Len(t, fa, 64)
+			if test.equal {
+				assert.Equal(t, fa, fb)
+			} else {
+				assert.NotEqual(t, fa, fb)
+			}
+		})
+	}
+}
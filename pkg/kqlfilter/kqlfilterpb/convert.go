@@ -0,0 +1,274 @@
+// Package kqlfilterpb provides a protobuf representation of filters, so that validated filters can be passed between
+// services without parsing and validating the filter string again.
+package kqlfilterpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative kqlfilter.proto
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/mycujoo/go-stdlib/pkg/kqlfilter"
+)
+
+// ToProto converts a Filter to its protobuf representation, AND'ing its clauses.
+func ToProto(f kqlfilter.Filter) *Filter {
+	switch len(f.Clauses) {
+	case 0:
+		return &Filter{}
+	case 1:
+		return clauseToProto(f.Clauses[0])
+	}
+	and := &And{}
+	for _, c := range f.Clauses {
+		and.Filters = append(and.Filters, clauseToProto(c))
+	}
+	return &Filter{Expr: &Filter_And{And: and}}
+}
+
+// FromProto converts the protobuf representation of a filter back to a Filter.
+// It only supports single clauses and clauses combined with AND, as created by ToProto.
+func FromProto(p *Filter) (kqlfilter.Filter, error) {
+	var f kqlfilter.Filter
+	if err := appendClauses(&f, p); err != nil {
+		return kqlfilter.Filter{}, err
+	}
+	return f, nil
+}
+
+func appendClauses(f *kqlfilter.Filter, p *Filter) error {
+	switch expr := p.GetExpr().(type) {
+	case nil:
+		return nil
+	case *Filter_Clause:
+		f.Clauses = append(f.Clauses, kqlfilter.Clause{
+			Field:    expr.Clause.GetField(),
+			Operator: expr.Clause.GetOperator(),
+			Values:   expr.Clause.GetValues(),
+		})
+		return nil
+	case *Filter_And:
+		for _, child := range expr.And.GetFilters() {
+			if err := appendClauses(f, child); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported filter expression %T", expr)
+	}
+}
+
+func clauseToProto(c kqlfilter.Clause) *Filter {
+	return &Filter{
+		Expr: &Filter_Clause{
+			Clause: &Clause{
+				Field:    c.Field,
+				Operator: c.Operator,
+				Values:   c.Values,
+			},
+		},
+	}
+}
+
+// NodeToProto converts a parsed filter to its protobuf representation.
+// Each comparison becomes a Clause with an operator like in Filter. Nested queries are not supported.
+func NodeToProto(node kqlfilter.Node) (*Filter, error) {
+	switch n := node.(type) {
+	case nil:
+		return &Filter{}, nil
+	case *kqlfilter.AndNode:
+		filters, err := nodesToProto(n.Nodes)
+		if err != nil {
+			return nil, err
+		}
+		return &Filter{Expr: &Filter_And{And: &And{Filters: filters}}}, nil
+	case *kqlfilter.OrNode:
+		filters, err := nodesToProto(n.Nodes)
+		if err != nil {
+			return nil, err
+		}
+		return &Filter{Expr: &Filter_Or{Or: &Or{Filters: filters}}}, nil
+	case *kqlfilter.NotNode:
+		filter, err := NodeToProto(n.Expr)
+		if err != nil {
+			return nil, err
+		}
+		return &Filter{Expr: &Filter_Not{Not: filter}}, nil
+	case *kqlfilter.IsNode:
+		switch v := n.Value.(type) {
+		case *kqlfilter.LiteralNode:
+			return clauseToProto(kqlfilter.Clause{Field: n.Identifier, Operator: "=", Values: []string{v.Value}}), nil
+		case *kqlfilter.OrNode:
+			c := kqlfilter.Clause{Field: n.Identifier, Operator: "IN"}
+			for _, child := range v.Nodes {
+				lit, ok := child.(*kqlfilter.LiteralNode)
+				if !ok {
+					return nil, fmt.Errorf("%s: unsupported node type %T", n.Identifier, child)
+				}
+				c.Values = append(c.Values, lit.Value)
+			}
+			return clauseToProto(c), nil
+		default:
+			return nil, fmt.Errorf("%s: unsupported node type %T", n.Identifier, v)
+		}
+	case *kqlfilter.RangeNode:
+		lit, ok := n.Value.(*kqlfilter.LiteralNode)
+		if !ok {
+			return nil, fmt.Errorf("%s: unsupported node type %T", n.Identifier, n.Value)
+		}
+		return clauseToProto(kqlfilter.Clause{Field: n.Identifier, Operator: n.Operator.String(), Values: []string{lit.Value}}), nil
+	case *kqlfilter.DistanceNode:
+		return clauseToProto(kqlfilter.Clause{
+			Field:    n.Identifier,
+			Operator: "DWITHIN",
+			Values: []string{
+				strconv.FormatFloat(n.Lat, 'f', -1, 64),
+				strconv.FormatFloat(n.Lon, 'f', -1, 64),
+				strconv.FormatFloat(n.Distance, 'f', -1, 64),
+			},
+		}), nil
+	default:
+		return nil, fmt.Errorf("unsupported node type %T", n)
+	}
+}
+
+func nodesToProto(nodes []kqlfilter.Node) ([]*Filter, error) {
+	filters := make([]*Filter, 0, len(nodes))
+	for _, child := range nodes {
+		filter, err := NodeToProto(child)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, filter)
+	}
+	return filters, nil
+}
+
+// NodeFromProto converts the protobuf representation of a filter back to a parsed filter.
+// It returns nil for an empty filter.
+func NodeFromProto(p *Filter) (kqlfilter.Node, error) {
+	switch expr := p.GetExpr().(type) {
+	case nil:
+		return nil, nil
+	case *Filter_And:
+		nodes, err := nodesFromProto(expr.And.GetFilters())
+		if err != nil {
+			return nil, err
+		}
+		return &kqlfilter.AndNode{NodeType: kqlfilter.NodeAnd, Nodes: nodes}, nil
+	case *Filter_Or:
+		nodes, err := nodesFromProto(expr.Or.GetFilters())
+		if err != nil {
+			return nil, err
+		}
+		return &kqlfilter.OrNode{NodeType: kqlfilter.NodeOr, Nodes: nodes}, nil
+	case *Filter_Not:
+		node, err := NodeFromProto(expr.Not)
+		if err != nil {
+			return nil, err
+		}
+		return &kqlfilter.NotNode{NodeType: kqlfilter.NodeNot, Expr: node}, nil
+	case *Filter_Clause:
+		return clauseFromProto(expr.Clause)
+	default:
+		return nil, fmt.Errorf("unsupported filter expression %T", expr)
+	}
+}
+
+func nodesFromProto(filters []*Filter) ([]kqlfilter.Node, error) {
+	if len(filters) == 0 {
+		return nil, fmt.Errorf("empty filter list")
+	}
+	nodes := make([]kqlfilter.Node, 0, len(filters))
+	for _, child := range filters {
+		node, err := NodeFromProto(child)
+		if err != nil {
+			return nil, err
+		}
+		if node == nil {
+			return nil, fmt.Errorf("empty filter in filter list")
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+func clauseFromProto(c *Clause) (kqlfilter.Node, error) {
+	field := c.GetField()
+	values := c.GetValues()
+	if field == "" {
+		return nil, fmt.Errorf("clause without field")
+	}
+
+	switch op := c.GetOperator(); op {
+	case "=", "!=":
+		if len(values) != 1 {
+			return nil, fmt.Errorf("%s: operator %s expects 1 value, got %d", field, op, len(values))
+		}
+		var node kqlfilter.Node = isNode(field, literalNode(values[0]))
+		if op == "!=" {
+			node = &kqlfilter.NotNode{NodeType: kqlfilter.NodeNot, Expr: node}
+		}
+		return node, nil
+	case "IN", "NOT IN":
+		if len(values) == 0 {
+			return nil, fmt.Errorf("%s: operator %s expects values", field, op)
+		}
+		or := &kqlfilter.OrNode{NodeType: kqlfilter.NodeOr}
+		for _, v := range values {
+			or.Nodes = append(or.Nodes, literalNode(v))
+		}
+		var node kqlfilter.Node = isNode(field, or)
+		if op == "NOT IN" {
+			node = &kqlfilter.NotNode{NodeType: kqlfilter.NodeNot, Expr: node}
+		}
+		return node, nil
+	case ">", ">=", "<", "<=":
+		if len(values) != 1 {
+			return nil, fmt.Errorf("%s: operator %s expects 1 value, got %d", field, op, len(values))
+		}
+		var rop kqlfilter.RangeOperator
+		switch op {
+		case ">":
+			rop = kqlfilter.RangeOperatorGt
+		case ">=":
+			rop = kqlfilter.RangeOperatorGte
+		case "<":
+			rop = kqlfilter.RangeOperatorLt
+		default:
+			rop = kqlfilter.RangeOperatorLte
+		}
+		return &kqlfilter.RangeNode{NodeType: kqlfilter.NodeRange, Identifier: field, Operator: rop, Value: literalNode(values[0])}, nil
+	case "DWITHIN":
+		if len(values) != 3 {
+			return nil, fmt.Errorf("%s: operator %s expects 3 values, got %d", field, op, len(values))
+		}
+		var parsed [3]float64
+		for i, v := range values {
+			var err error
+			parsed[i], err = strconv.ParseFloat(v, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%s: invalid distance value: %w", field, err)
+			}
+		}
+		return &kqlfilter.DistanceNode{
+			NodeType:   kqlfilter.NodeDistance,
+			Identifier: field,
+			Lat:        parsed[0],
+			Lon:        parsed[1],
+			Operator:   kqlfilter.RangeOperatorLte,
+			Distance:   parsed[2],
+		}, nil
+	default:
+		return nil, fmt.Errorf("%s: unsupported operator %s", field, op)
+	}
+}
+
+func isNode(field string, value kqlfilter.Node) *kqlfilter.IsNode {
+	return &kqlfilter.IsNode{NodeType: kqlfilter.NodeIs, Identifier: field, Value: value}
+}
+
+func literalNode(value string) *kqlfilter.LiteralNode {
+	return &kqlfilter.LiteralNode{NodeType: kqlfilter.NodeLiteral, Value: value}
+}
@@ -0,0 +1,121 @@
+package kqlfilterpb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/mycujoo/go-stdlib/pkg/kqlfilter"
+)
+
+func TestFilterRoundTrip(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input string
+	}{
+		{"empty", ""},
+		{"one clause", "team:ajax"},
+		{"multiple clauses", "team:(ajax or psv) not city:amsterdam year>=2020"},
+		{"distance", "distance(location, 52.3, 4.9) <= 10km"},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			f, err := kqlfilter.Parse(test.input, true)
+			require.NoError(t, err)
+
+			data, err := proto.Marshal(ToProto(f))
+			require.NoError(t, err)
+			var p Filter
+			require.NoError(t, proto.Unmarshal(data, &p))
+
+			out, err := FromProto(&p)
+			require.NoError(t, err)
+			assert.Equal(t, f, out)
+		})
+	}
+}
+
+func TestFromProtoUnsupported(t *testing.T) {
+	_, err := FromProto(&Filter{
+		Expr: &Filter_Or{Or: &Or{Filters: []*Filter{
+			clauseToProto(kqlfilter.Clause{Field: "team", Operator: "=", Values: []string{"ajax"}}),
+		}}},
+	})
+	require.Error(t, err)
+}
+
+func TestNodeRoundTrip(t *testing.T) {
+	testCases := []struct {
+		name          string
+		input         string
+		expectedError bool
+		expected      string
+	}{
+		{"one clause", "team:ajax", false, "team=ajax"},
+		{"multiple values", "team:(ajax or psv)", false, "team=(ajax OR psv)"},
+		{"boolean tree", "team:ajax or (year>=2020 and not city:amsterdam)", false, "(team=ajax OR (year>=2020 AND NOT city=amsterdam))"},
+		{"distance", "distance(location, 52.3, 4.9) <= 10km", false, "distance(location, 52.3, 4.9)<=10000m"},
+		{"nested", "team:{name:ajax}", true, ""},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			n, err := kqlfilter.ParseAST(test.input)
+			require.NoError(t, err)
+
+			p, err := NodeToProto(n)
+			if test.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			data, err := proto.Marshal(p)
+			require.NoError(t, err)
+			var out Filter
+			require.NoError(t, proto.Unmarshal(data, &out))
+
+			node, err := NodeFromProto(&out)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, node.String())
+		})
+	}
+}
+
+func TestNodeFromProtoInvalid(t *testing.T) {
+	testCases := []struct {
+		name   string
+		filter *Filter
+	}{
+		{
+			"unknown operator",
+			clauseToProto(kqlfilter.Clause{Field: "team", Operator: "LIKE", Values: []string{"ajax"}}),
+		},
+		{
+			"missing value",
+			clauseToProto(kqlfilter.Clause{Field: "team", Operator: "="}),
+		},
+		{
+			"missing field",
+			clauseToProto(kqlfilter.Clause{Operator: "=", Values: []string{"ajax"}}),
+		},
+		{
+			"empty and",
+			&Filter{Expr: &Filter_And{And: &And{}}},
+		},
+		{
+			"invalid distance",
+			clauseToProto(kqlfilter.Clause{Field: "location", Operator: "DWITHIN", Values: []string{"52.3", "4.9", "far"}}),
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NodeFromProto(test.filter)
+			require.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,450 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.31.0
+// 	protoc        v4.24.4
+// source: kqlfilter.proto
+
+package kqlfilterpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Filter is a boolean tree of clauses.
+type Filter struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Expr:
+	//	*Filter_Clause
+	//	*Filter_And
+	//	*Filter_Or
+	//	*Filter_Not
+	Expr isFilter_Expr `protobuf_oneof:"expr"`
+}
+
+func (x *Filter) Reset() {
+	*x = Filter{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_kqlfilter_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Filter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Filter) ProtoMessage() {}
+
+func (x *Filter) ProtoReflect() protoreflect.Message {
+	mi := &file_kqlfilter_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Filter.ProtoReflect.Descriptor instead.
+func (*Filter) Descriptor() ([]byte, []int) {
+	return file_kqlfilter_proto_rawDescGZIP(), []int{0}
+}
+
+func (m *Filter) GetExpr() isFilter_Expr {
+	if m != nil {
+		return m.Expr
+	}
+	return nil
+}
+
+func (x *Filter) GetClause() *Clause {
+	if x, ok := x.GetExpr().(*Filter_Clause); ok {
+		return x.Clause
+	}
+	return nil
+}
+
+func (x *Filter) GetAnd() *And {
+	if x, ok := x.GetExpr().(*Filter_And); ok {
+		return x.And
+	}
+	return nil
+}
+
+func (x *Filter) GetOr() *Or {
+	if x, ok := x.GetExpr().(*Filter_Or); ok {
+		return x.Or
+	}
+	return nil
+}
+
+func (x *Filter) GetNot() *Filter {
+	if x, ok := x.GetExpr().(*Filter_Not); ok {
+		return x.Not
+	}
+	return nil
+}
+
+type isFilter_Expr interface {
+	isFilter_Expr()
+}
+
+type Filter_Clause struct {
+	// A single clause.
+	Clause *Clause `protobuf:"bytes,1,opt,name=clause,proto3,oneof"`
+}
+
+type Filter_And struct {
+	// All filters must match.
+	And *And `protobuf:"bytes,2,opt,name=and,proto3,oneof"`
+}
+
+type Filter_Or struct {
+	// Any of the filters must match.
+	Or *Or `protobuf:"bytes,3,opt,name=or,proto3,oneof"`
+}
+
+type Filter_Not struct {
+	// The filter must not match.
+	Not *Filter `protobuf:"bytes,4,opt,name=not,proto3,oneof"`
+}
+
+func (*Filter_Clause) isFilter_Expr() {}
+
+func (*Filter_And) isFilter_Expr() {}
+
+func (*Filter_Or) isFilter_Expr() {}
+
+func (*Filter_Not) isFilter_Expr() {}
+
+// And matches if all of its filters match.
+type And struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Filters []*Filter `protobuf:"bytes,1,rep,name=filters,proto3" json:"filters,omitempty"`
+}
+
+func (x *And) Reset() {
+	*x = And{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_kqlfilter_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *And) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*And) ProtoMessage() {}
+
+func (x *And) ProtoReflect() protoreflect.Message {
+	mi := &file_kqlfilter_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use And.ProtoReflect.Descriptor instead.
+func (*And) Descriptor() ([]byte, []int) {
+	return file_kqlfilter_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *And) GetFilters() []*Filter {
+	if x != nil {
+		return x.Filters
+	}
+	return nil
+}
+
+// Or matches if any of its filters match.
+type Or struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Filters []*Filter `protobuf:"bytes,1,rep,name=filters,proto3" json:"filters,omitempty"`
+}
+
+func (x *Or) Reset() {
+	*x = Or{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_kqlfilter_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Or) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Or) ProtoMessage() {}
+
+func (x *Or) ProtoReflect() protoreflect.Message {
+	mi := &file_kqlfilter_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Or.ProtoReflect.Descriptor instead.
+func (*Or) Descriptor() ([]byte, []int) {
+	return file_kqlfilter_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Or) GetFilters() []*Filter {
+	if x != nil {
+		return x.Filters
+	}
+	return nil
+}
+
+// Clause compares a field to values, like kqlfilter.Clause.
+type Clause struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Name of the field.
+	Field string `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
+	// One of `=`, `!=`, `<`, `<=`, `>`, `>=`, `IN`, `NOT IN`, `DWITHIN`.
+	Operator string `protobuf:"bytes,2,opt,name=operator,proto3" json:"operator,omitempty"`
+	// Values the field is compared to.
+	Values []string `protobuf:"bytes,3,rep,name=values,proto3" json:"values,omitempty"`
+}
+
+func (x *Clause) Reset() {
+	*x = Clause{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_kqlfilter_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Clause) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Clause) ProtoMessage() {}
+
+func (x *Clause) ProtoReflect() protoreflect.Message {
+	mi := &file_kqlfilter_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Clause.ProtoReflect.Descriptor instead.
+func (*Clause) Descriptor() ([]byte, []int) {
+	return file_kqlfilter_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Clause) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *Clause) GetOperator() string {
+	if x != nil {
+		return x.Operator
+	}
+	return ""
+}
+
+func (x *Clause) GetValues() []string {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+var File_kqlfilter_proto protoreflect.FileDescriptor
+
+var file_kqlfilter_proto_rawDesc = []byte{
+	0x0a, 0x0f, 0x6b, 0x71, 0x6c, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x12, 0x14, 0x6d, 0x79, 0x63, 0x75, 0x6a, 0x6f, 0x6f, 0x2e, 0x6b, 0x71, 0x6c, 0x66, 0x69,
+	0x6c, 0x74, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x22, 0xd5, 0x01, 0x0a, 0x06, 0x46, 0x69, 0x6c, 0x74,
+	0x65, 0x72, 0x12, 0x36, 0x0a, 0x06, 0x63, 0x6c, 0x61, 0x75, 0x73, 0x65, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x6d, 0x79, 0x63, 0x75, 0x6a, 0x6f, 0x6f, 0x2e, 0x6b, 0x71, 0x6c,
+	0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6c, 0x61, 0x75, 0x73, 0x65,
+	0x48, 0x00, 0x52, 0x06, 0x63, 0x6c, 0x61, 0x75, 0x73, 0x65, 0x12, 0x2d, 0x0a, 0x03, 0x61, 0x6e,
+	0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x6d, 0x79, 0x63, 0x75, 0x6a, 0x6f,
+	0x6f, 0x2e, 0x6b, 0x71, 0x6c, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x41,
+	0x6e, 0x64, 0x48, 0x00, 0x52, 0x03, 0x61, 0x6e, 0x64, 0x12, 0x2a, 0x0a, 0x02, 0x6f, 0x72, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x6d, 0x79, 0x63, 0x75, 0x6a, 0x6f, 0x6f, 0x2e,
+	0x6b, 0x71, 0x6c, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x4f, 0x72, 0x48,
+	0x00, 0x52, 0x02, 0x6f, 0x72, 0x12, 0x30, 0x0a, 0x03, 0x6e, 0x6f, 0x74, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x6d, 0x79, 0x63, 0x75, 0x6a, 0x6f, 0x6f, 0x2e, 0x6b, 0x71, 0x6c,
+	0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72,
+	0x48, 0x00, 0x52, 0x03, 0x6e, 0x6f, 0x74, 0x42, 0x06, 0x0a, 0x04, 0x65, 0x78, 0x70, 0x72, 0x22,
+	0x3d, 0x0a, 0x03, 0x41, 0x6e, 0x64, 0x12, 0x36, 0x0a, 0x07, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72,
+	0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x6d, 0x79, 0x63, 0x75, 0x6a, 0x6f,
+	0x6f, 0x2e, 0x6b, 0x71, 0x6c, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x46,
+	0x69, 0x6c, 0x74, 0x65, 0x72, 0x52, 0x07, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x73, 0x22, 0x3c,
+	0x0a, 0x02, 0x4f, 0x72, 0x12, 0x36, 0x0a, 0x07, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x73, 0x18,
+	0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x6d, 0x79, 0x63, 0x75, 0x6a, 0x6f, 0x6f, 0x2e,
+	0x6b, 0x71, 0x6c, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x69, 0x6c,
+	0x74, 0x65, 0x72, 0x52, 0x07, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x73, 0x22, 0x52, 0x0a, 0x06,
+	0x43, 0x6c, 0x61, 0x75, 0x73, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x12, 0x1a, 0x0a, 0x08,
+	0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08,
+	0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x6f, 0x72, 0x12, 0x16, 0x0a, 0x06, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x06, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73,
+	0x42, 0x38, 0x5a, 0x36, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6d,
+	0x79, 0x63, 0x75, 0x6a, 0x6f, 0x6f, 0x2f, 0x67, 0x6f, 0x2d, 0x73, 0x74, 0x64, 0x6c, 0x69, 0x62,
+	0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x6b, 0x71, 0x6c, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x2f, 0x6b,
+	0x71, 0x6c, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x33,
+}
+
+var (
+	file_kqlfilter_proto_rawDescOnce sync.Once
+	file_kqlfilter_proto_rawDescData = file_kqlfilter_proto_rawDesc
+)
+
+func file_kqlfilter_proto_rawDescGZIP() []byte {
+	file_kqlfilter_proto_rawDescOnce.Do(func() {
+		file_kqlfilter_proto_rawDescData = protoimpl.X.CompressGZIP(file_kqlfilter_proto_rawDescData)
+	})
+	return file_kqlfilter_proto_rawDescData
+}
+
+var file_kqlfilter_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_kqlfilter_proto_goTypes = []interface{}{
+	(*Filter)(nil), // 0: mycujoo.kqlfilter.v1.Filter
+	(*And)(nil),    // 1: mycujoo.kqlfilter.v1.And
+	(*Or)(nil),     // 2: mycujoo.kqlfilter.v1.Or
+	(*Clause)(nil), // 3: mycujoo.kqlfilter.v1.Clause
+}
+var file_kqlfilter_proto_depIdxs = []int32{
+	3, // 0: mycujoo.kqlfilter.v1.Filter.clause:type_name -> mycujoo.kqlfilter.v1.Clause
+	1, // 1: mycujoo.kqlfilter.v1.Filter.and:type_name -> mycujoo.kqlfilter.v1.And
+	2, // 2: mycujoo.kqlfilter.v1.Filter.or:type_name -> mycujoo.kqlfilter.v1.Or
+	0, // 3: mycujoo.kqlfilter.v1.Filter.not:type_name -> mycujoo.kqlfilter.v1.Filter
+	0, // 4: mycujoo.kqlfilter.v1.And.filters:type_name -> mycujoo.kqlfilter.v1.Filter
+	0, // 5: mycujoo.kqlfilter.v1.Or.filters:type_name -> mycujoo.kqlfilter.v1.Filter
+	6, // [6:6] is the sub-list for method output_type
+	6, // [6:6] is the sub-list for method input_type
+	6, // [6:6] is the sub-list for extension type_name
+	6, // [6:6] is the sub-list for extension extendee
+	0, // [0:6] is the sub-list for field type_name
+}
+
+func init() { file_kqlfilter_proto_init() }
+func file_kqlfilter_proto_init() {
+	if File_kqlfilter_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_kqlfilter_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Filter); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_kqlfilter_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*And); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_kqlfilter_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Or); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_kqlfilter_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Clause); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_kqlfilter_proto_msgTypes[0].OneofWrappers = []interface{}{
+		(*Filter_Clause)(nil),
+		(*Filter_And)(nil),
+		(*Filter_Or)(nil),
+		(*Filter_Not)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_kqlfilter_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   4,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_kqlfilter_proto_goTypes,
+		DependencyIndexes: file_kqlfilter_proto_depIdxs,
+		MessageInfos:      file_kqlfilter_proto_msgTypes,
+	}.Build()
+	File_kqlfilter_proto = out.File
+	file_kqlfilter_proto_rawDesc = nil
+	file_kqlfilter_proto_goTypes = nil
+	file_kqlfilter_proto_depIdxs = nil
+}
@@ -0,0 +1,21 @@
+module github.com/mycujoo/go-stdlib/pkg/kqlfilter/mongo
+
+go 1.21
+
+require (
+	github.com/mycujoo/go-stdlib/pkg/kqlfilter v0.3.3
+	github.com/stretchr/testify v1.8.4
+	go.mongodb.org/mongo-driver v1.12.1
+)
+
+require (
+	github.com/Masterminds/squirrel v1.5.4 // indirect
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/lann/builder v0.0.0-20180802200727-47ae307949d0 // indirect
+	github.com/lann/ps v0.0.0-20150810152359-62de8c46ede0 // indirect
+	github.com/pkg/errors v0.9.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/mycujoo/go-stdlib/pkg/kqlfilter v0.3.3 => ../
@@ -0,0 +1,231 @@
+package mongo
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mycujoo/go-stdlib/pkg/kqlfilter"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// FieldType is the BSON type values of a field are converted to.
+type FieldType int
+
+const (
+	FieldTypeString FieldType = iota
+	FieldTypeInt
+	FieldTypeFloat
+	FieldTypeBool
+	FieldTypeDate
+)
+
+type FilterGenerator struct {
+	validateFieldName func(name string) error
+	fieldTypes        map[string]FieldType
+}
+
+func NewFilterGenerator(options ...Option) *FilterGenerator {
+	g := &FilterGenerator{validateFieldName: defaultFieldNameValidator}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Option is a function that configures a filter generator.
+type Option func(*FilterGenerator)
+
+// WithFieldValidator allows checking incoming field names.
+// This can be used to prevent users from querying fields that they are not allowed to query.
+// Example usage:
+//
+//	WithFieldValidator(func(name string) error {
+//		if !allowedFields[name] {
+//			return fmt.Errorf("field %s is not allowed", name)
+//		}
+//		return nil
+//	})
+func WithFieldValidator(fieldValidator func(name string) error) Option {
+	return func(g *FilterGenerator) {
+		g.validateFieldName = fieldValidator
+	}
+}
+
+// WithFieldTypes sets the type values of each field are converted to, keyed by the full (dotted) field name.
+// Fields that are not present are treated as FieldTypeString.
+func WithFieldTypes(fieldTypes map[string]FieldType) Option {
+	return func(g *FilterGenerator) {
+		g.fieldTypes = fieldTypes
+	}
+}
+
+// ConvertAST converts a KQL AST to a MongoDB filter document.
+//
+// Multiple values for a field are converted to $in, values ending with a wildcard (`*`) to a prefix $regex,
+// and boolean expressions to $and, $or and $nor (as negation of an arbitrary expression).
+func (g *FilterGenerator) ConvertAST(root kqlfilter.Node) (bson.D, error) {
+	return g.convertNodeToFilter(root, "")
+}
+
+func (g *FilterGenerator) convertNodeToFilter(node kqlfilter.Node, prefix string) (bson.D, error) {
+	switch n := node.(type) {
+	case *kqlfilter.AndNode:
+		clauses, err := g.convertNodes(n.Nodes, prefix)
+		if err != nil {
+			return nil, err
+		}
+		return bson.D{{Key: "$and", Value: clauses}}, nil
+	case *kqlfilter.OrNode:
+		clauses, err := g.convertNodes(n.Nodes, prefix)
+		if err != nil {
+			return nil, err
+		}
+		return bson.D{{Key: "$or", Value: clauses}}, nil
+	case *kqlfilter.NotNode:
+		f, err := g.convertNodeToFilter(n.Expr, prefix)
+		if err != nil {
+			return nil, err
+		}
+		return bson.D{{Key: "$nor", Value: bson.A{f}}}, nil
+	case *kqlfilter.IsNode:
+		id := prefix + n.Identifier
+
+		nested, ok := n.Value.(*kqlfilter.NestedNode)
+		if ok {
+			// Transform x:{y:z} syntax.
+			// Prefix all identifiers with the identifier of the parent node,
+			// so it becomes x.y:z
+			return g.convertNodeToFilter(nested.Expr, id+".")
+		}
+
+		if err := g.validateFieldName(id); err != nil {
+			return nil, fmt.Errorf("%s: %w", id, err)
+		}
+
+		or, ok := n.Value.(*kqlfilter.OrNode)
+		if ok {
+			// Transform x:(y or z) syntax.
+			var vals bson.A
+			// Check that all children are literals
+			for _, child := range or.Nodes {
+				lit, ok := child.(*kqlfilter.LiteralNode)
+				if !ok {
+					return nil, fmt.Errorf("%s: invalid syntax", id)
+				}
+				val, err := g.convertValue(id, lit.Value)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", id, err)
+				}
+				vals = append(vals, val)
+			}
+			return bson.D{{Key: id, Value: bson.D{{Key: "$in", Value: vals}}}}, nil
+		}
+
+		lit, ok := n.Value.(*kqlfilter.LiteralNode)
+		if !ok {
+			return nil, fmt.Errorf("%s: expected literal node", id)
+		}
+		val, err := g.convertValue(id, lit.Value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", id, err)
+		}
+		if regex, ok := val.(primitive.Regex); ok {
+			return bson.D{{Key: id, Value: bson.D{{Key: "$regex", Value: regex}}}}, nil
+		}
+		return bson.D{{Key: id, Value: val}}, nil
+	case *kqlfilter.RangeNode:
+		id := prefix + n.Identifier
+
+		if err := g.validateFieldName(id); err != nil {
+			return nil, fmt.Errorf("%s: %w", id, err)
+		}
+
+		lit, ok := n.Value.(*kqlfilter.LiteralNode)
+		if !ok {
+			return nil, fmt.Errorf("%s: expected literal node", id)
+		}
+		val, err := g.convertValue(id, lit.Value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", id, err)
+		}
+		if _, ok := val.(primitive.Regex); ok {
+			return nil, fmt.Errorf("%s: wildcards are not supported in ranges", id)
+		}
+
+		var op string
+		switch n.Operator {
+		case kqlfilter.RangeOperatorLt:
+			op = "$lt"
+		case kqlfilter.RangeOperatorLte:
+			op = "$lte"
+		case kqlfilter.RangeOperatorGt:
+			op = "$gt"
+		case kqlfilter.RangeOperatorGte:
+			op = "$gte"
+		default:
+			return nil, fmt.Errorf("%s: unsupported range operator", id)
+		}
+		return bson.D{{Key: id, Value: bson.D{{Key: op, Value: val}}}}, nil
+	default:
+		return nil, fmt.Errorf("unexpected node type: %T", n)
+	}
+}
+
+func (g *FilterGenerator) convertNodes(nodes []kqlfilter.Node, prefix string) (bson.A, error) {
+	clauses := make(bson.A, 0, len(nodes))
+	for _, child := range nodes {
+		f, err := g.convertNodeToFilter(child, prefix)
+		if err != nil {
+			return nil, err
+		}
+		clauses = append(clauses, f)
+	}
+	return clauses, nil
+}
+
+// convertValue converts a literal to the configured type of the field.
+// String values ending with a wildcard are converted to a prefix regular expression.
+func (g *FilterGenerator) convertValue(id string, value string) (any, error) {
+	switch g.fieldTypes[id] {
+	case FieldTypeInt:
+		v, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, errors.New("expected int literal")
+		}
+		return v, nil
+	case FieldTypeFloat:
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, errors.New("expected number literal")
+		}
+		return v, nil
+	case FieldTypeBool:
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, errors.New("expected bool literal")
+		}
+		return v, nil
+	case FieldTypeDate:
+		v, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, errors.New("expected date literal")
+		}
+		return v, nil
+	default:
+		if strings.HasSuffix(value, "*") {
+			return primitive.Regex{Pattern: "^" + regexp.QuoteMeta(strings.TrimSuffix(value, "*"))}, nil
+		}
+		return value, nil
+	}
+}
+
+func defaultFieldNameValidator(_ string) error {
+	return nil
+}
@@ -0,0 +1,103 @@
+package mongo
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mycujoo/go-stdlib/pkg/kqlfilter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestConvertNodeToFilter(t *testing.T) {
+	testCases := []struct {
+		name               string
+		input              string
+		expectedError      error
+		expectedFilterJSON string
+	}{
+		{
+			name:               "simple equality",
+			input:              "type_id:team",
+			expectedFilterJSON: `{"type_id":"team"}`,
+		},
+		{
+			name:               "multiple values for same field",
+			input:              "type_id:(team OR player)",
+			expectedFilterJSON: `{"type_id":{"$in":["team","player"]}}`,
+		},
+		{
+			name:               "prefix match",
+			input:              "fields.name:Aj.x*",
+			expectedFilterJSON: `{"fields.name":{"$regex":{"$regularExpression":{"pattern":"^Aj\\.x","options":""}}}}`,
+		},
+		{
+			name:               "typed values",
+			input:              "fields.active:true fields.established_year>=1900",
+			expectedFilterJSON: `{"$and":[{"fields.active":true},{"fields.established_year":{"$gte":1900}}]}`,
+		},
+		{
+			name:               "date range",
+			input:              `fields.founded<"2000-01-01T00:00:00Z"`,
+			expectedFilterJSON: `{"fields.founded":{"$lt":{"$date":"2000-01-01T00:00:00Z"}}}`,
+		},
+		{
+			name:               "and/or/not",
+			input:              "type_id:team and (fields.active:true or not fields.name:Ajax)",
+			expectedFilterJSON: `{"$and":[{"type_id":"team"},{"$or":[{"fields.active":true},{"$nor":[{"fields.name":"Ajax"}]}]}]}`,
+		},
+		{
+			name:               "nested",
+			input:              "fields:{name:Ajax}",
+			expectedFilterJSON: `{"fields.name":"Ajax"}`,
+		},
+		{
+			name:          "invalid field",
+			input:         "other_field:team",
+			expectedError: errors.New("other_field: invalid field"),
+		},
+		{
+			name:          "invalid value",
+			input:         "fields.established_year>=long_ago",
+			expectedError: errors.New("fields.established_year: expected int literal"),
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			n, err := kqlfilter.ParseAST(test.input)
+			require.NoError(t, err)
+
+			g := NewFilterGenerator(
+				WithFieldValidator(func(field string) error {
+					if field == "type_id" {
+						return nil
+					}
+					if strings.HasPrefix(field, "fields.") && strings.Count(field, ".") == 1 {
+						return nil
+					}
+					return errors.New("invalid field")
+				}),
+				WithFieldTypes(map[string]FieldType{
+					"fields.active":           FieldTypeBool,
+					"fields.established_year": FieldTypeInt,
+					"fields.founded":          FieldTypeDate,
+				}),
+			)
+
+			f, err := g.ConvertAST(n)
+			if test.expectedError != nil {
+				require.EqualError(t, err, test.expectedError.Error())
+				return
+			}
+			require.NoError(t, err)
+
+			data, err := bson.MarshalExtJSON(f, false, false)
+			require.NoError(t, err)
+
+			assert.JSONEq(t, test.expectedFilterJSON, string(data))
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package kqlfilter
 
 import (
+	"strconv"
 	"strings"
 )
 
@@ -38,6 +39,7 @@ const (
 	NodeRange
 	NodeNested
 	NodeLiteral
+	NodeDistance
 )
 
 // Nodes.
@@ -255,3 +257,38 @@ func (q *LiteralNode) String() string {
 func (q *LiteralNode) writeTo(sb *strings.Builder) {
 	sb.WriteString(q.Value)
 }
+
+// DistanceNode holds a geo-distance check, e.g. distance(location, 52.3, 4.9) < 10km.
+type DistanceNode struct {
+	NodeType
+	Pos
+	p          *parser
+	Identifier string
+	Lat        float64
+	Lon        float64
+	Operator   RangeOperator // RangeOperatorLt or RangeOperatorLte, both converted like RangeOperatorLte.
+	Distance   float64       // Distance in meters.
+}
+
+func (p *parser) newDistanceNode(pos Pos, id string, lat, lon float64, op RangeOperator, distance float64) *DistanceNode {
+	return &DistanceNode{p: p, NodeType: NodeDistance, Pos: pos, Identifier: id, Lat: lat, Lon: lon, Operator: op, Distance: distance}
+}
+
+func (q *DistanceNode) String() string {
+	var sb strings.Builder
+	q.writeTo(&sb)
+	return sb.String()
+}
+
+func (q *DistanceNode) writeTo(sb *strings.Builder) {
+	sb.WriteString("distance(")
+	sb.WriteString(q.Identifier)
+	sb.WriteString(", ")
+	sb.WriteString(strconv.FormatFloat(q.Lat, 'f', -1, 64))
+	sb.WriteString(", ")
+	sb.WriteString(strconv.FormatFloat(q.Lon, 'f', -1, 64))
+	sb.WriteString(")")
+	sb.WriteString(q.Operator.String())
+	sb.WriteString(strconv.FormatFloat(q.Distance, 'f', -1, 64))
+	sb.WriteString("m")
+}
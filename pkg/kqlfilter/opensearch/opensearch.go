@@ -0,0 +1,183 @@
+// Package opensearch converts a KQL AST to an OpenSearch query DSL document.
+//
+// The generated Query is plain data that marshals to JSON, so it can be used as the "query" of a search request
+// body with any client, e.g. opensearch-go:
+//
+//	body, err := json.Marshal(map[string]any{"query": q})
+//	res, err := client.Search(client.Search.WithBody(bytes.NewReader(body)))
+package opensearch
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/mycujoo/go-stdlib/pkg/kqlfilter"
+)
+
+// Query is a node of the OpenSearch query DSL.
+type Query map[string]any
+
+type QueryGenerator struct {
+	validateFieldName func(name string) error
+}
+
+func NewQueryGenerator(options ...Option) *QueryGenerator {
+	g := &QueryGenerator{validateFieldName: defaultFieldNameValidator}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Option is a function that configures a query generator.
+type Option func(*QueryGenerator)
+
+// WithFieldValidator allows checking incoming field names.
+// This can be used to prevent users from querying fields that they are not allowed to query.
+// Example usage:
+//
+//	WithFieldValidator(func(name string) error {
+//		if !allowedFields[name] {
+//			return fmt.Errorf("field %s is not allowed", name)
+//		}
+//		return nil
+//	})
+func WithFieldValidator(fieldValidator func(name string) error) Option {
+	return func(g *QueryGenerator) {
+		g.validateFieldName = fieldValidator
+	}
+}
+
+// ConvertAST converts a KQL AST to an OpenSearch query.
+func (q *QueryGenerator) ConvertAST(root kqlfilter.Node) (Query, error) {
+	return q.convertNodeToQuery(root, "")
+}
+
+func (q *QueryGenerator) convertNodeToQuery(node kqlfilter.Node, prefix string) (Query, error) {
+	switch n := node.(type) {
+	case *kqlfilter.AndNode:
+		clauses, err := q.convertNodes(n.Nodes, prefix)
+		if err != nil {
+			return nil, err
+		}
+		return Query{"bool": Query{"must": clauses}}, nil
+	case *kqlfilter.OrNode:
+		clauses, err := q.convertNodes(n.Nodes, prefix)
+		if err != nil {
+			return nil, err
+		}
+		return Query{"bool": Query{"should": clauses}}, nil
+	case *kqlfilter.NotNode:
+		nq, err := q.convertNodeToQuery(n.Expr, prefix)
+		if err != nil {
+			return nil, err
+		}
+		return Query{"bool": Query{"must_not": []Query{nq}}}, nil
+	case *kqlfilter.IsNode:
+		id := prefix + n.Identifier
+
+		nested, ok := n.Value.(*kqlfilter.NestedNode)
+		if ok {
+			// Transform x:{y:z} syntax.
+			// Prefix all identifiers with the identifier of the parent node,
+			// so it becomes x.y:z
+			return q.convertNodeToQuery(nested.Expr, id+".")
+		}
+
+		if err := q.validateFieldName(id); err != nil {
+			return nil, fmt.Errorf("%s: %w", id, err)
+		}
+
+		or, ok := n.Value.(*kqlfilter.OrNode)
+		if ok {
+			// Transform x:(y or z) syntax.
+			var vals []string
+			// Check that all children are literals
+			for _, child := range or.Nodes {
+				lit, ok := child.(*kqlfilter.LiteralNode)
+				if !ok {
+					return nil, fmt.Errorf("%s: invalid syntax", id)
+				}
+				vals = append(vals, lit.Value)
+			}
+			return Query{"terms": Query{id: vals}}, nil
+		}
+
+		lit, ok := n.Value.(*kqlfilter.LiteralNode)
+		if !ok {
+			return nil, fmt.Errorf("%s: expected literal node", id)
+		}
+		if lit.Value == "*" {
+			// Transform x:* syntax into a check for presence of the field.
+			return Query{"exists": Query{"field": id}}, nil
+		}
+		return Query{"term": Query{id: Query{"value": lit.Value}}}, nil
+	case *kqlfilter.RangeNode:
+		id := prefix + n.Identifier
+
+		if err := q.validateFieldName(id); err != nil {
+			return nil, fmt.Errorf("%s: %w", id, err)
+		}
+
+		lit, ok := n.Value.(*kqlfilter.LiteralNode)
+		if !ok {
+			return nil, fmt.Errorf("%s: expected literal node", id)
+		}
+		rq, err := convertRangeNode(n.Operator, lit)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", id, err)
+		}
+		return Query{"range": Query{id: rq}}, nil
+	default:
+		return nil, fmt.Errorf("unexpected node type: %T", n)
+	}
+}
+
+func (q *QueryGenerator) convertNodes(nodes []kqlfilter.Node, prefix string) ([]Query, error) {
+	clauses := make([]Query, 0, len(nodes))
+	for _, child := range nodes {
+		cq, err := q.convertNodeToQuery(child, prefix)
+		if err != nil {
+			return nil, err
+		}
+		clauses = append(clauses, cq)
+	}
+	return clauses, nil
+}
+
+func convertRangeNode(op kqlfilter.RangeOperator, lit *kqlfilter.LiteralNode) (Query, error) {
+	var key string
+	switch op {
+	case kqlfilter.RangeOperatorLt:
+		key = "lt"
+	case kqlfilter.RangeOperatorLte:
+		key = "lte"
+	case kqlfilter.RangeOperatorGt:
+		key = "gt"
+	case kqlfilter.RangeOperatorGte:
+		key = "gte"
+	default:
+		return nil, errors.New("unsupported range operator")
+	}
+
+	// Here we check the type of the literal node, and then we can create the correct range query.
+	fVal, err := strconv.ParseFloat(lit.Value, 64)
+	if err == nil {
+		return Query{key: fVal}, nil
+	}
+
+	// It is not a number, so we check if it is a date.
+	_, err = time.Parse(time.RFC3339, lit.Value)
+	if err != nil {
+		return nil, errors.New("expected number or date literal")
+	}
+	return Query{key: lit.Value}, nil
+}
+
+func defaultFieldNameValidator(_ string) error {
+	return nil
+}
@@ -0,0 +1,118 @@
+package opensearch
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mycujoo/go-stdlib/pkg/kqlfilter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertNodeToQuery(t *testing.T) {
+	testCases := []struct {
+		name              string
+		input             string
+		expectedError     error
+		expectedQueryJSON string
+	}{
+		{
+			name:              "simple equality",
+			input:             "type_id:team",
+			expectedQueryJSON: `{"term":{"type_id":{"value":"team"}}}`,
+		},
+		{
+			name:              "multiple values for same field",
+			input:             "type_id:(team OR player)",
+			expectedQueryJSON: `{"terms":{"type_id":["team","player"]}}`,
+		},
+		{
+			name:  "and/or",
+			input: "type_id:team fields.active:true or fields.established_year < 2000",
+			expectedQueryJSON: `{
+  "bool": {
+    "must": [
+      {"term": {"type_id": {"value": "team"}}},
+      {
+        "bool": {
+          "should": [
+            {"term": {"fields.active": {"value": "true"}}},
+            {"range": {"fields.established_year": {"lt": 2000}}}
+          ]
+        }
+      }
+    ]
+  }
+}`,
+		},
+		{
+			name:              "exists",
+			input:             "fields.nickname:*",
+			expectedQueryJSON: `{"exists":{"field":"fields.nickname"}}`,
+		},
+		{
+			name:              "not",
+			input:             "not type_id:team",
+			expectedQueryJSON: `{"bool":{"must_not":[{"term":{"type_id":{"value":"team"}}}]}}`,
+		},
+		{
+			name:  "nested",
+			input: "type_id:player fields:{position:goalkeeper}",
+			expectedQueryJSON: `{
+  "bool": {
+    "must": [
+      {"term": {"type_id": {"value": "player"}}},
+      {"term": {"fields.position": {"value": "goalkeeper"}}}
+    ]
+  }
+}`,
+		},
+		{
+			name:              "range date",
+			input:             `fields.birthday >= "2000-01-01T00:00:00.000Z"`,
+			expectedQueryJSON: `{"range":{"fields.birthday":{"gte":"2000-01-01T00:00:00.000Z"}}}`,
+		},
+		{
+			name:          "range invalid",
+			input:         `fields.birthday>=true`,
+			expectedError: errors.New("fields.birthday: expected number or date literal"),
+		},
+		{
+			name:          "invalid field",
+			input:         `type:player`,
+			expectedError: errors.New("type: invalid field"),
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			n, err := kqlfilter.ParseAST(test.input)
+			require.NoError(t, err)
+
+			g := NewQueryGenerator(WithFieldValidator(
+				func(field string) error {
+					if field == "type_id" {
+						return nil
+					}
+					if strings.HasPrefix(field, "fields.") && strings.Count(field, ".") == 1 {
+						return nil
+					}
+					return errors.New("invalid field")
+				}))
+
+			q, err := g.ConvertAST(n)
+			if test.expectedError != nil {
+				require.EqualError(t, err, test.expectedError.Error())
+				return
+			}
+			require.NoError(t, err)
+
+			data, err := json.Marshal(q)
+			require.NoError(t, err)
+
+			assert.JSONEq(t, test.expectedQueryJSON, string(data))
+		})
+	}
+}
@@ -2,7 +2,9 @@ package kqlfilter
 
 import (
 	"fmt"
+	"math"
 	"runtime"
+	"strconv"
 	"strings"
 )
 
@@ -215,6 +217,9 @@ func (p *parser) parseExpression() Node {
 	switch p.peek().typ {
 	case itemString:
 		idItem := p.next()
+		if p.peek().typ == itemLeftParen {
+			return p.parseFunction(idItem)
+		}
 		p.eatSpace()
 
 		op := p.next()
@@ -254,6 +259,89 @@ func (p *parser) parseExpression() Node {
 	}
 }
 
+// parseFunction parses a function-style clause, the name of which has already been consumed.
+// The only supported function is distance(field, lat, lon) followed by `<` or `<=` and a distance with unit.
+// The converters treat both like `<=`, since the distance checks of the databases (ST_DWithin, geo_distance) include
+// points at exactly the distance, which makes no difference in practice.
+func (p *parser) parseFunction(name item) Node {
+	if name.val != "distance" {
+		p.errorf("unknown function %s", name.val)
+	}
+	p.expect(itemLeftParen, "function")
+
+	// The lexer does not separate arguments on commas, so join the arguments first and split them afterwards.
+	var sb strings.Builder
+Loop:
+	for {
+		token := p.next()
+		switch token.typ {
+		case itemRightParen:
+			break Loop
+		case itemString:
+			sb.WriteString(token.val)
+		case itemSpace:
+		default:
+			p.unexpected(token, "function arguments")
+		}
+	}
+	args := strings.Split(sb.String(), ",")
+	if len(args) != 3 || args[0] == "" {
+		p.errorf("distance expects a field, latitude and longitude")
+	}
+	lat, err := strconv.ParseFloat(args[1], 64)
+	// Negated, so that NaN is rejected.
+	if err != nil || !(math.Abs(lat) <= 90) {
+		p.errorf("invalid latitude %s", args[1])
+	}
+	lon, err := strconv.ParseFloat(args[2], 64)
+	if err != nil || !(math.Abs(lon) <= 180) {
+		p.errorf("invalid longitude %s", args[2])
+	}
+
+	p.eatSpace()
+	op := p.expect(itemRangeOperator, "distance")
+	var rop RangeOperator
+	switch op.val {
+	case "<":
+		rop = RangeOperatorLt
+	case "<=":
+		rop = RangeOperatorLte
+	default:
+		p.errorf("distance only supports < and <=")
+	}
+	p.eatSpace()
+	value := p.parseValue().(*LiteralNode)
+	distance, err := parseDistance(value.Value)
+	if err != nil {
+		p.errorf("%s", err)
+	}
+	return p.newDistanceNode(name.pos, args[0], lat, lon, rop, distance)
+}
+
+// parseDistance parses a distance with a unit of m, km or mi into meters.
+func parseDistance(s string) (float64, error) {
+	units := []struct {
+		suffix string
+		meters float64
+	}{
+		{"km", 1000},
+		{"mi", 1609.344},
+		{"m", 1},
+	}
+	for _, unit := range units {
+		number, ok := strings.CutSuffix(s, unit.suffix)
+		if !ok {
+			continue
+		}
+		v, err := strconv.ParseFloat(number, 64)
+		if err != nil || v < 0 || math.IsInf(v, 0) || math.IsNaN(v) {
+			return 0, fmt.Errorf("invalid distance %s", s)
+		}
+		return v * unit.meters, nil
+	}
+	return 0, fmt.Errorf("distance %s requires a unit of m, km or mi", s)
+}
+
 func (p *parser) parseListOfValues() Node {
 	peeked := p.peek()
 	if peeked.typ == itemLeftBrace {
@@ -230,6 +230,66 @@ func TestParseAST(t *testing.T) {
 			false,
 			"(a=1 OR b=2 OR c=3 OR d=4 OR e=5)",
 		},
+		{
+			"distance",
+			"distance(location, 52.3, 4.9) <= 10km",
+			false,
+			"distance(location, 52.3, 4.9)<=10000m",
+		},
+		{
+			"distance without spaces",
+			"distance(location,-33.9,151.2)<=1.5mi and type:stadium",
+			false,
+			"(distance(location, -33.9, 151.2)<=2414.016m AND type=stadium)",
+		},
+		{
+			"distance without unit",
+			"distance(location, 52.3, 4.9) <= 10",
+			true,
+			"",
+		},
+		{
+			"distance greater than",
+			"distance(location, 52.3, 4.9) > 10km",
+			true,
+			"",
+		},
+		{
+			"distance less than",
+			"distance(location, 52.3, 4.9) < 10km",
+			false,
+			"distance(location, 52.3, 4.9)<10000m",
+		},
+		{
+			"distance invalid latitude",
+			"distance(location, 92.3, 4.9) <= 10km",
+			true,
+			"",
+		},
+		{
+			"distance NaN latitude",
+			"distance(location, NaN, 4.9) <= 10km",
+			true,
+			"",
+		},
+		{
+			"distance NaN longitude",
+			"distance(location, 52.3, NaN) <= 10km",
+			true,
+			"",
+		},
+		{
+			"distance missing argument",
+			"distance(location, 52.3) <= 10km",
+			true,
+			"",
+		},
+		{
+			"unknown function",
+			"near(location, 52.3, 4.9) < 10km",
+			true,
+			"",
+		},
 	}
 
 	for _, test := range testCases {
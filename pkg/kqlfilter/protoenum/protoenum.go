@@ -0,0 +1,54 @@
+// Package protoenum provides kqlfilter MapValue functions for fields backed by protobuf enums.
+package protoenum
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// MapValue returns a MapValue function accepting the names and numbers of the values of a protobuf enum,
+// e.g. `PAYMENT_STATE_FAILED` or `3`, and mapping them to the enum number as int64.
+// The `*_UNSPECIFIED` value is not accepted. Example usage:
+//
+//	kqlfilter.FilterToSpannerFieldConfig{
+//		ColumnType: kqlfilter.FilterToSpannerFieldColumnTypeInt64,
+//		MapValue:   protoenum.MapValue(paymentv1.PaymentState(0).Descriptor()),
+//	}
+func MapValue(enum protoreflect.EnumDescriptor) func(string) (any, error) {
+	return func(value string) (any, error) {
+		v, err := lookup(enum, value)
+		if err != nil {
+			return nil, err
+		}
+		return int64(v.Number()), nil
+	}
+}
+
+// NameMapValue works like MapValue, but maps the values to the enum value name, for columns storing enums as strings.
+func NameMapValue(enum protoreflect.EnumDescriptor) func(string) (any, error) {
+	return func(value string) (any, error) {
+		v, err := lookup(enum, value)
+		if err != nil {
+			return nil, err
+		}
+		return string(v.Name()), nil
+	}
+}
+
+// lookup finds the enum value by name or number.
+func lookup(enum protoreflect.EnumDescriptor, value string) (protoreflect.EnumValueDescriptor, error) {
+	values := enum.Values()
+	v := values.ByName(protoreflect.Name(value))
+	if v == nil {
+		if n, err := strconv.ParseInt(value, 10, 32); err == nil {
+			v = values.ByNumber(protoreflect.EnumNumber(n))
+		}
+	}
+	if v == nil || strings.HasSuffix(string(v.Name()), "_UNSPECIFIED") {
+		return nil, fmt.Errorf("invalid %s value: %s", enum.Name(), value)
+	}
+	return v, nil
+}
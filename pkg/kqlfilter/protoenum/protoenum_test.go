@@ -0,0 +1,70 @@
+package protoenum
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func testEnum(t *testing.T) protoreflect.EnumDescriptor {
+	fd, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:    proto.String("payment.proto"),
+		Package: proto.String("payment.v1"),
+		Syntax:  proto.String("proto3"),
+		EnumType: []*descriptorpb.EnumDescriptorProto{
+			{
+				Name: proto.String("PaymentState"),
+				Value: []*descriptorpb.EnumValueDescriptorProto{
+					{Name: proto.String("PAYMENT_STATE_UNSPECIFIED"), Number: proto.Int32(0)},
+					{Name: proto.String("PAYMENT_STATE_PENDING"), Number: proto.Int32(1)},
+					{Name: proto.String("PAYMENT_STATE_FAILED"), Number: proto.Int32(2)},
+				},
+			},
+		},
+	}, nil)
+	require.NoError(t, err)
+	return fd.Enums().Get(0)
+}
+
+func TestMapValue(t *testing.T) {
+	testCases := []struct {
+		name          string
+		input         string
+		expectedError bool
+		expected      int64
+		expectedName  string
+	}{
+		{"name", "PAYMENT_STATE_FAILED", false, 2, "PAYMENT_STATE_FAILED"},
+		{"number", "1", false, 1, "PAYMENT_STATE_PENDING"},
+		{"unspecified name", "PAYMENT_STATE_UNSPECIFIED", true, 0, ""},
+		{"unspecified number", "0", true, 0, ""},
+		{"unknown name", "PAYMENT_STATE_REFUNDED", true, 0, ""},
+		{"unknown number", "3", true, 0, ""},
+		{"lower case name", "payment_state_failed", true, 0, ""},
+	}
+
+	enum := testEnum(t)
+	mapValue := MapValue(enum)
+	nameMapValue := NameMapValue(enum)
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			v, err := mapValue(test.input)
+			name, nameErr := nameMapValue(test.input)
+			if test.expectedError {
+				require.Error(t, err)
+				require.Error(t, nameErr)
+				return
+			}
+			require.NoError(t, err)
+			require.NoError(t, nameErr)
+			assert.Equal(t, test.expected, v)
+			assert.Equal(t, test.expectedName, name)
+		})
+	}
+}
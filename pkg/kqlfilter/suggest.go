@@ -0,0 +1,75 @@
+package kqlfilter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maxSuggestions limits the number of field names suggested for an unknown field.
+const maxSuggestions = 3
+
+// unknownFieldMessage returns the error message for a field that is not present in fieldConfigs,
+// including the closest allowed field names, if any.
+func unknownFieldMessage[T any](field string, fieldConfigs map[string]T) string {
+	names := make([]string, 0, len(fieldConfigs))
+	for name := range fieldConfigs {
+		names = append(names, name)
+	}
+	suggestions := suggestFields(field, names)
+	if len(suggestions) == 0 {
+		return fmt.Sprintf("unknown field: %s", field)
+	}
+	return fmt.Sprintf("unknown field: %s, did you mean %s?", field, strings.Join(suggestions, " or "))
+}
+
+// suggestFields returns the candidates with the smallest case-insensitive edit distance to field.
+// Candidates that differ in more than a third of the characters are not considered similar.
+func suggestFields(field string, candidates []string) []string {
+	maxDistance := len(field) / 3
+	if maxDistance < 1 {
+		maxDistance = 1
+	}
+
+	var suggestions []string
+	best := maxDistance + 1
+	lowerField := strings.ToLower(field)
+	for _, candidate := range candidates {
+		d := levenshtein(lowerField, strings.ToLower(candidate))
+		switch {
+		case d < best:
+			best = d
+			suggestions = []string{candidate}
+		case d == best:
+			suggestions = append(suggestions, candidate)
+		}
+	}
+
+	sort.Strings(suggestions)
+	if len(suggestions) > maxSuggestions {
+		suggestions = suggestions[:maxSuggestions]
+	}
+	return suggestions
+}
+
+// levenshtein returns the edit distance between a and b.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,37 @@
+package kqlfilter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSuggestFields(t *testing.T) {
+	candidates := []string{"userId", "teamId", "email", "createTime", "userIds"}
+
+	testCases := []struct {
+		name     string
+		field    string
+		expected []string
+	}{
+		{"different case", "userid", []string{"userId"}},
+		{"typo", "emial", []string{"email"}},
+		{"equally close", "userIdz", []string{"userId", "userIds"}},
+		{"nothing close", "status", nil},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, suggestFields(test.field, candidates))
+		})
+	}
+}
+
+func TestUnknownFieldMessage(t *testing.T) {
+	fieldConfigs := map[string]FilterToSpannerFieldConfig{
+		"userId": {},
+		"email":  {},
+	}
+	assert.Equal(t, "unknown field: userid, did you mean userId?", unknownFieldMessage("userid", fieldConfigs))
+	assert.Equal(t, "unknown field: status", unknownFieldMessage("status", fieldConfigs))
+}
@@ -0,0 +1,115 @@
+package kqlfilter
+
+import "fmt"
+
+// TokenKind identifies the kind of a token returned by a Tokenizer.
+type TokenKind int
+
+const (
+	TokenError         TokenKind = iota // error occurred; value is text of error
+	TokenEOF                            // end of input
+	TokenSpace                          // run of spaces
+	TokenBool                           // `true` or `false`
+	TokenString                         // identifier or value, including quotes of quoted strings
+	TokenOr                             // `or`
+	TokenAnd                            // `and`
+	TokenNot                            // `not`
+	TokenLeftParen                      // `(`
+	TokenRightParen                     // `)`
+	TokenLeftBrace                      // `{`
+	TokenRightBrace                     // `}`
+	TokenColon                          // `:`
+	TokenWildcard                       // `*`
+	TokenRangeOperator                  // `<=`, `<`, `>=` or `>`
+)
+
+var tokenKindName = map[TokenKind]string{
+	TokenError:         "error",
+	TokenEOF:           "EOF",
+	TokenSpace:         "space",
+	TokenBool:          "bool",
+	TokenString:        "string",
+	TokenOr:            "or",
+	TokenAnd:           "and",
+	TokenNot:           "not",
+	TokenLeftParen:     "(",
+	TokenRightParen:    ")",
+	TokenLeftBrace:     "{",
+	TokenRightBrace:    "}",
+	TokenColon:         ":",
+	TokenWildcard:      "*",
+	TokenRangeOperator: "range",
+}
+
+func (k TokenKind) String() string {
+	s := tokenKindName[k]
+	if s == "" {
+		return fmt.Sprintf("token%d", int(k))
+	}
+	return s
+}
+
+var itemTokenKind = map[itemType]TokenKind{
+	itemError:         TokenError,
+	itemEOF:           TokenEOF,
+	itemSpace:         TokenSpace,
+	itemBool:          TokenBool,
+	itemString:        TokenString,
+	itemOr:            TokenOr,
+	itemAnd:           TokenAnd,
+	itemNot:           TokenNot,
+	itemLeftParen:     TokenLeftParen,
+	itemRightParen:    TokenRightParen,
+	itemLeftBrace:     TokenLeftBrace,
+	itemRightBrace:    TokenRightBrace,
+	itemColon:         TokenColon,
+	itemWildcard:      TokenWildcard,
+	itemRangeOperator: TokenRangeOperator,
+}
+
+// Token is a lexical token of a filter string.
+type Token struct {
+	Kind TokenKind
+	// Byte position of the start of the token in the input.
+	Pos Pos
+	// Source text of the token, so that Pos+len(Value) is the end of the token.
+	// For TokenError this is the error message instead.
+	Value string
+}
+
+// Tokenizer splits a filter string into tokens, e.g. for syntax highlighting or auto-completion in editors.
+// Unlike ParseAST it does not check whether the tokens form a valid filter.
+type Tokenizer struct {
+	input string
+	lex   *lexer
+	last  *Token
+}
+
+// NewTokenizer creates a tokenizer for input.
+func NewTokenizer(input string) *Tokenizer {
+	return &Tokenizer{input: input, lex: lex(input)}
+}
+
+// Next returns the next token of the input.
+// Once it returned TokenEOF or TokenError, it keeps returning that token.
+func (t *Tokenizer) Next() Token {
+	if t.last != nil {
+		return *t.last
+	}
+	i := t.lex.nextItem()
+	token := Token{
+		Kind: itemTokenKind[i.typ],
+		Pos:  i.pos,
+	}
+	switch i.typ {
+	case itemError:
+		token.Value = i.val
+		t.last = &token
+	case itemEOF:
+		token.Pos = Pos(len(t.input))
+		t.last = &token
+	default:
+		token.Value = t.input[i.pos:t.lex.pos]
+	}
+	return token
+}
@@ -0,0 +1,86 @@
+package kqlfilter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenizer(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected []Token
+	}{
+		{
+			"empty",
+			"",
+			[]Token{
+				{TokenEOF, 0, ""},
+			},
+		},
+		{
+			"clauses",
+			`team:"ajax" and year>=2020`,
+			[]Token{
+				{TokenString, 0, "team"},
+				{TokenColon, 4, ":"},
+				{TokenString, 5, `"ajax"`},
+				{TokenSpace, 11, " "},
+				{TokenAnd, 12, "and"},
+				{TokenSpace, 15, " "},
+				{TokenString, 16, "year"},
+				{TokenRangeOperator, 20, ">="},
+				{TokenString, 22, "2020"},
+				{TokenEOF, 26, ""},
+			},
+		},
+		{
+			"escapes and wildcards",
+			`name:a\:b* not:{x:true}`,
+			[]Token{
+				{TokenString, 0, "name"},
+				{TokenColon, 4, ":"},
+				{TokenString, 5, `a\:b`},
+				{TokenWildcard, 9, "*"},
+				{TokenSpace, 10, " "},
+				{TokenNot, 11, "not"},
+				{TokenColon, 14, ":"},
+				{TokenLeftBrace, 15, "{"},
+				{TokenString, 16, "x"},
+				{TokenColon, 17, ":"},
+				{TokenBool, 18, "true"},
+				{TokenRightBrace, 22, "}"},
+				{TokenEOF, 23, ""},
+			},
+		},
+		{
+			"error",
+			`(team:"ajax`,
+			[]Token{
+				{TokenLeftParen, 0, "("},
+				{TokenString, 1, "team"},
+				{TokenColon, 5, ":"},
+				{TokenError, 6, "unterminated quoted string"},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			tokenizer := NewTokenizer(test.input)
+			var tokens []Token
+			for {
+				token := tokenizer.Next()
+				tokens = append(tokens, token)
+				if token.Kind == TokenEOF || token.Kind == TokenError {
+					break
+				}
+			}
+			assert.Equal(t, test.expected, tokens)
+
+			// The last token is repeated.
+			assert.Equal(t, test.expected[len(test.expected)-1], tokenizer.Next())
+		})
+	}
+}
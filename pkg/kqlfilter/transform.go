@@ -51,6 +51,8 @@ func (m NodeMapper) Map(ast Node) error {
 		if err != nil {
 			return err
 		}
+	case *DistanceNode:
+		x.Identifier = m.TransformIdentifierFunc(x.Identifier)
 	case *LiteralNode:
 		x.Value = m.TransformValueFunc(x.Value)
 	}